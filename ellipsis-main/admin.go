@@ -0,0 +1,228 @@
+package main
+
+import (
+	"brief/internal/config"
+	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/pkg/geoip"
+	pgdb "brief/pkg/repository/storage/postgres"
+	urlSrv "brief/service/url"
+	userSrv "brief/service/user"
+	"brief/utility"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// createAdmin creates an admin user from flags, or the one configured in env. The password is
+// read from stdin rather than a flag, so that it is kept out of the process list and shell history
+func createAdmin(logger *log.Logger, args []string) error {
+	fs, format := newFlagSet("create-admin")
+	user := &model.User{}
+	fs.StringVar(&user.Email, "email", "", "email of the admin, the password is then read from stdin")
+	fs.StringVar(&user.Firstname, "firstname", "Admin", "first name of the admin")
+	fs.StringVar(&user.Lastname, "lastname", "", "last name of the admin")
+	if err := parseFlags(fs, format, args, 0); err != nil {
+		return err
+	}
+
+	uService := userSrv.NewUserService(pgdb.GetDB(), events.GetBus())
+	if user.Email == "" {
+		if err := uService.CreateAdminUser(context.Background(), logger); err != nil {
+			return err
+		}
+		admin, err := uService.Get(context.Background(), config.GetConfig().AdminID)
+		if err != nil {
+			return err
+		}
+		return output(*format, admin, func(w io.Writer) { userRows(w, admin) })
+	}
+
+	password, err := readPassword(os.Stdin, os.Stderr)
+	if err != nil {
+		return err
+	}
+	user.Password = password
+
+	if err := validator.New().Struct(user); err != nil {
+		return err
+	}
+	if _, err := uService.Register(context.Background(), user, true); err != nil {
+		return err
+	}
+
+	return output(*format, user, func(w io.Writer) { userRows(w, user) })
+}
+
+// readPassword reads a password from the first line of 'r', prompting for it on 'prompt'
+func readPassword(r io.Reader, prompt io.Writer) (string, error) {
+	fmt.Fprint(prompt, "password: ")
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("could not read password from stdin, got error: %w", err)
+	}
+	fmt.Fprintln(prompt)
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func lockUser(logger *log.Logger, args []string) error {
+	return setLocked("lock-user", args, true)
+}
+
+func unlockUser(logger *log.Logger, args []string) error {
+	return setLocked("unlock-user", args, false)
+}
+
+// setLocked locks or unlocks the account of the user given in 'args'
+func setLocked(name string, args []string, isLocked bool) error {
+	fs, format := newFlagSet(name)
+	if err := parseFlags(fs, format, args, 1); err != nil {
+		return err
+	}
+
+	uService := userSrv.NewUserService(pgdb.GetDB(), events.GetBus())
+	lock := uService.UnlockUser
+	if isLocked {
+		lock = uService.LockUser
+	}
+
+	user, err := lock(context.Background(), operator(name), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return output(*format, user, func(w io.Writer) { userRows(w, user) })
+}
+
+// listUrls lists every url, or the urls created by a user
+func listUrls(logger *log.Logger, args []string) error {
+	fs, format := newFlagSet("list-urls")
+	idOrEmail := fs.String("user", "", "id or email of the user whose urls are listed")
+	if err := parseFlags(fs, format, args, 0); err != nil {
+		return err
+	}
+
+	uService := urlSrv.NewUrlService(pgdb.GetDB(), geoip.GetLocator(), events.GetBus())
+
+	var urls []model.URL
+	var err error
+	if *idOrEmail != "" {
+		user, err := userSrv.NewUserService(pgdb.GetDB(), events.GetBus()).Get(context.Background(), *idOrEmail)
+		if err != nil {
+			return err
+		}
+		urls, err = uService.GetURLs(context.Background(), operator("list-urls"), user.ID, "")
+		if err != nil {
+			return err
+		}
+	} else if urls, err = uService.GetAll(context.Background()); err != nil {
+		return err
+	}
+
+	return output(*format, urls, func(w io.Writer) { urlRows(w, urls...) })
+}
+
+// deleteUrl deletes a url by its id
+func deleteUrl(logger *log.Logger, args []string) error {
+	fs, format := newFlagSet("delete-url")
+	if err := parseFlags(fs, format, args, 1); err != nil {
+		return err
+	}
+
+	uService := urlSrv.NewUrlService(pgdb.GetDB(), geoip.GetLocator(), events.GetBus())
+	url, err := uService.Delete(context.Background(), operator("delete-url"), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return output(*format, url, func(w io.Writer) { urlRows(w, *url) })
+}
+
+// rotateSecret generates a new secret key used to sign tokens, and writes it into an env file
+// when one is given. The server must be restarted for the key to be used
+func rotateSecret(logger *log.Logger, args []string) error {
+	fs, format := newFlagSet("rotate-secret")
+	envFile := fs.String("env", "", "env file to write SECRET_KEY into, e.g. sample.env")
+	if err := parseFlags(fs, format, args, 0); err != nil {
+		return err
+	}
+
+	secret, err := utility.GenerateSecret(32)
+	if err != nil {
+		return fmt.Errorf("could not generate secret, got error: %w", err)
+	}
+
+	if *envFile != "" {
+		content, err := os.ReadFile(*envFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.WriteFile(*envFile, []byte(utility.SetEnv(string(content), "SECRET_KEY", secret)), 0600); err != nil {
+			return err
+		}
+	}
+
+	// The environment takes precedence over env files
+	if *envFile != "" && os.Getenv("SECRET_KEY") != "" {
+		logger.Warn("SECRET_KEY is set in the environment and overrides the env file")
+	}
+
+	result := map[string]string{"secret_key": secret, "env_file": *envFile}
+	return output(*format, result, func(w io.Writer) {
+		fmt.Fprintln(w, secret)
+		if *envFile != "" {
+			fmt.Fprintf(w, "written to %s, restart the server to sign tokens with it\n", *envFile)
+		}
+	})
+}
+
+// export writes the data of a user as a ZIP archive, to a file or stdout
+func export(logger *log.Logger, args []string) error {
+	fs, format := newFlagSet("export")
+	out := fs.String("out", "", "file to write the archive to, '-' for stdout, <id>-export.zip by default")
+	if err := parseFlags(fs, format, args, 1); err != nil {
+		return err
+	}
+
+	uService := userSrv.NewUserService(pgdb.GetDB(), events.GetBus())
+	user, err := uService.Get(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	archive, err := uService.Export(context.Background(), &model.ContextInfo{ID: user.ID, Role: user.Role, Email: user.Email})
+	if err != nil {
+		return err
+	}
+
+	if *out == "-" {
+		_, err := os.Stdout.Write(archive)
+		return err
+	}
+	if *out == "" {
+		*out = user.ID + "-export.zip"
+	}
+	if err := os.WriteFile(*out, archive, 0600); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{"user_id": user.ID, "file": *out, "bytes": len(archive)}
+	return output(*format, result, func(w io.Writer) {
+		fmt.Fprintf(w, "exported %s to %s (%d bytes)\n", user.Email, *out, len(archive))
+	})
+}
+
+// urlRows writes 'urls' as a table
+func urlRows(w io.Writer, urls ...model.URL) {
+	fmt.Fprintln(w, "ID\tHASH\tUSER\tLONG URL\tCREATED AT")
+	for _, u := range urls {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", u.ID, u.Hash, u.UserID, u.LongURL,
+			u.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
@@ -0,0 +1,13 @@
+// Command brief is the command line client of a brief server, install it with
+//
+//	go install brief/cmd/brief
+package main
+
+import (
+	"brief/pkg/cli"
+	"os"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
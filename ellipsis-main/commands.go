@@ -0,0 +1,144 @@
+package main
+
+import (
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
+	pgdb "brief/pkg/repository/storage/postgres"
+	webhookSrv "brief/service/webhook"
+	"brief/utility"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type command struct {
+	Name    string
+	Usage   string
+	Summary string
+	NeedsDB bool // the command connects to postgres before running
+	Run     func(logger *log.Logger, args []string) error
+}
+
+// commands lists the subcommands of the binary, they reuse the service layer directly so
+// that maintenance can be scripted without a token
+func commands() []command {
+	return []command{
+		{"serve", "serve", "run the HTTP server, the default", true, serve},
+		{"migrate", "migrate <up|down [steps]|status [-o json]|to <version>>", "manage the database schema", true, runMigrate},
+		{"create-admin", "create-admin [-o json] [-email e -firstname f -lastname l]", "create an admin user whose password is read from stdin, from ADMIN_ID and ADMIN_PASSWORD without flags", true, createAdmin},
+		{"lock-user", "lock-user [-o json] <idOrEmail>", "lock a user's account", true, lockUser},
+		{"unlock-user", "unlock-user [-o json] <idOrEmail>", "unlock a user's account", true, unlockUser},
+		{"list-urls", "list-urls [-o json] [-user idOrEmail]", "list every url, or the urls of a user", true, listUrls},
+		{"delete-url", "delete-url [-o json] <id>", "delete a url, it can be restored until its retention period ends", true, deleteUrl},
+		{"rotate-secret", "rotate-secret [-o json] [-env file]", "generate a new SECRET_KEY, signed tokens stop being valid", false, rotateSecret},
+		{"export", "export [-o json] [-out file] <idOrEmail>", "export the data of a user as a ZIP archive", true, export},
+	}
+}
+
+// runCommand runs the subcommand called 'name' with its 'args', an unknown name is an error
+func runCommand(logger *log.Logger, name string, args []string) error {
+	for _, cmd := range commands() {
+		if cmd.Name != name {
+			continue
+		}
+
+		if cmd.NeedsDB {
+			pgdb.ConnectToDB(logger)
+			subscribe(logger)
+			defer drain(logger)
+		}
+		return cmd.Run(logger, args)
+	}
+
+	printUsage(os.Stderr)
+	if name != "help" && name != "-h" && name != "--help" {
+		return fmt.Errorf("unknown command '%s'", name)
+	}
+	return nil
+}
+
+// subscribe sets up the event bus and the subscribers reacting to domain events
+func subscribe(logger *log.Logger) {
+	events.Setup(logger, pgdb.GetDB())
+	webhookSrv.Subscribe(events.GetBus(), pgdb.GetDB())
+}
+
+// drain waits for the async subscribers still handling events before the process exits
+func drain(logger *log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := events.GetBus().Drain(ctx); err != nil {
+		logger.Errorf("could not drain the event bus, got error: %s", err)
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: brief <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %s\n      %s\n", cmd.Usage, cmd.Summary)
+	}
+}
+
+// newFlagSet returns the flags of a subcommand, with the '-o' output format flag
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	format := fs.String("o", "text", "output format, text or json")
+	return fs, format
+}
+
+// parseFlags parses 'args' and ensures 'n' positional arguments are left
+func parseFlags(fs *flag.FlagSet, format *string, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("output format must be text or json, got '%s'", *format)
+	}
+	if fs.NArg() != n {
+		return fmt.Errorf("%s expects %d argument(s), got %d", fs.Name(), n, fs.NArg())
+	}
+	return nil
+}
+
+// output writes 'v' as JSON when 'format' is json, or calls 'text' with a tab aligned writer
+func output(format string, v interface{}, text func(w io.Writer)) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	text(w)
+	return w.Flush()
+}
+
+// operator returns the context info of actions taken from the command line, they are
+// audited as made by the admin user
+func operator(name string) *model.ContextInfo {
+	return &model.ContextInfo{
+		ID:        config.GetConfig().AdminID,
+		Role:      constant.Roles[constant.Admin],
+		UserAgent: "brief-cli/" + name,
+	}
+}
+
+// userRows writes 'users' as a table
+func userRows(w io.Writer, users ...*model.User) {
+	fmt.Fprintln(w, "ID\tEMAIL\tROLE\tLOCKED\tCREATED AT")
+	for _, u := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n", u.ID, u.Email, utility.RoleName(u.Role), u.IsLocked,
+			u.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
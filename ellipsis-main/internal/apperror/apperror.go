@@ -0,0 +1,129 @@
+// Package apperror defines the errors services return, each of a kind deciding its HTTP status and
+// with a stable code clients can rely on
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind is the category of an error
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindInternal     Kind = "internal"
+)
+
+// Error is an error of a known kind. Its message is safe to show to clients, the error it wraps
+// is only logged
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{} // more about the error for clients, such as the message of each invalid field
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s, got error: %s", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status of the error's kind
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Validation is returned when a request is malformed or breaks a rule
+func Validation(code, format string, args ...interface{}) *Error {
+	return newError(KindValidation, code, format, args...)
+}
+
+// Binding is returned when a request body cannot be decoded
+func Binding(err error) *Error {
+	return &Error{Kind: KindValidation, Code: CodeInvalidBody, Message: err.Error(), Err: err}
+}
+
+// Invalid is returned when a request fails struct validation, 'fields' holds the message of
+// each invalid field
+func Invalid(fields interface{}) *Error {
+	return &Error{Kind: KindValidation, Code: CodeValidationFailed, Message: "validation failed", Details: fields}
+}
+
+// Unauthorized is returned when a request is not authenticated, or its credentials are wrong
+func Unauthorized(code, format string, args ...interface{}) *Error {
+	return newError(KindUnauthorized, code, format, args...)
+}
+
+// Forbidden is returned when the requesting user is not allowed to perform an action
+func Forbidden(code, format string, args ...interface{}) *Error {
+	return newError(KindForbidden, code, format, args...)
+}
+
+// NotFound is returned when a resource does not exist, or is hidden from the requesting user
+func NotFound(code, format string, args ...interface{}) *Error {
+	return newError(KindNotFound, code, format, args...)
+}
+
+// Conflict is returned when an action conflicts with the current state of a resource
+func Conflict(code, format string, args ...interface{}) *Error {
+	return newError(KindConflict, code, format, args...)
+}
+
+// Internal is returned when an action fails for reasons clients cannot fix, 'err' is the cause
+func Internal(err error, format string, args ...interface{}) *Error {
+	e := newError(KindInternal, CodeInternal, format, args...)
+	e.Err = err
+	return e
+}
+
+// Wrap attaches the cause 'err' to 'e', for errors of other kinds caused by another error
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// From returns 'err' as an Error. Errors of unknown kinds are internal errors, their messages
+// are not shown to clients
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal(err, "something went wrong")
+}
+
+// Is reports whether 'err' is an Error of kind 'kind'
+func Is(err error, kind Kind) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == kind
+}
+
+func newError(kind Kind, code, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,42 @@
+package apperror
+
+// Codes identify an error independently of its message, they are part of the API and must not change
+const (
+	CodeInvalidBody              = "invalid_body"
+	CodeValidationFailed         = "validation_failed"
+	CodeInvalidURL               = "invalid_url"
+	CodeInvalidRule              = "invalid_rule"
+	CodeInvalidUTM               = "invalid_utm"
+	CodeInvalidRole              = "invalid_role"
+	CodeInvalidOption            = "invalid_option"
+	CodeDomainVerificationFailed = "domain_verification_failed"
+
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeSignInRequired     = "sign_in_required"
+
+	CodeForbidden     = "forbidden"
+	CodeAccountLocked = "account_locked"
+
+	CodeURLNotFound        = "url_not_found"
+	CodeUserNotFound       = "user_not_found"
+	CodeCampaignNotFound   = "campaign_not_found"
+	CodeDomainNotFound     = "domain_not_found"
+	CodeWorkspaceNotFound  = "workspace_not_found"
+	CodeMemberNotFound     = "member_not_found"
+	CodeInvitationNotFound = "invitation_not_found"
+	CodeWebhookNotFound    = "webhook_not_found"
+	CodeDeliveryNotFound   = "delivery_not_found"
+	CodeNotFound           = "not_found"
+
+	CodeHashTaken          = "hash_taken"
+	CodeEmailTaken         = "email_taken"
+	CodeDomainTaken        = "domain_taken"
+	CodeDomainNotVerified  = "domain_not_verified"
+	CodeRestoreExpired     = "restore_expired"
+	CodeInvitationAccepted = "invitation_accepted"
+	CodeInvitationExpired  = "invitation_expired"
+	CodeWorkspaceOwner     = "workspace_owner"
+
+	CodeInternal = "internal_error"
+)
@@ -20,6 +20,42 @@ type Configuration struct {
 	PGSSLMode     string `mapstructure:"PG_SSL_MODE"`
 	AdminID       string `mapstructure:"ADMIN_ID"`
 	AdminPassword string `mapstructure:"ADMIN_PASSWORD"`
+
+	GeoIPDatabase  string `mapstructure:"GEOIP_DATABASE"`  // path to a MaxMind format (.mmdb) country or city database
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"` // comma separated IPs or CIDRs allowed to set X-Forwarded-For
+	RetentionDays  int    `mapstructure:"RETENTION_DAYS"`  // days deleted urls and users can be restored before they are purged
+	MetricsToken   string `mapstructure:"METRICS_TOKEN"`   // bearer token required to read /metrics, open when empty
+	MetricsAddr    string `mapstructure:"METRICS_ADDR"`    // serve /metrics on this address instead of the API's, e.g. 127.0.0.1:9090
+
+	TracingExporter string `mapstructure:"TRACING_EXPORTER"` // stdout or otlp, tracing is disabled when empty
+	OTLPEndpoint    string `mapstructure:"OTLP_ENDPOINT"`    // host:port of the OTLP HTTP collector, e.g. localhost:4318
+	OTLPInsecure    bool   `mapstructure:"OTLP_INSECURE"`    // send spans over plain HTTP
+
+	LogFormat     string `mapstructure:"LOG_FORMAT"`      // json or text, json when empty
+	LogLevel      string `mapstructure:"LOG_LEVEL"`       // debug, info, warn or error, info when empty
+	LogFile       string `mapstructure:"LOG_FILE"`        // write logs to this rotated file instead of stdout
+	LogMaxSize    int    `mapstructure:"LOG_MAX_SIZE"`    // megabytes the log file grows to before it is rotated
+	LogMaxBackups int    `mapstructure:"LOG_MAX_BACKUPS"` // rotated log files to keep
+	LogMaxAge     int    `mapstructure:"LOG_MAX_AGE"`     // days to keep rotated log files
+
+	HealthTimeout   int `mapstructure:"HEALTH_TIMEOUT"`     // seconds each readiness check may take, 2 when unset
+	HealthMinDiskMB int `mapstructure:"HEALTH_MIN_DISK_MB"` // free megabytes required on the log file's disk
+	ShutdownDelay   int `mapstructure:"SHUTDOWN_DELAY"`     // seconds to report unready before shutting down
+
+	WebhookTimeout      int  `mapstructure:"WEBHOOK_TIMEOUT"`       // seconds a webhook may take to answer, 10 when unset
+	WebhookMaxAttempts  int  `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`  // attempts at a delivery before it is failed, 8 when unset
+	WebhookAllowPrivate bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE"` // let webhooks target loopback, private and link-local addresses, for development
+
+	EventOutbox            bool `mapstructure:"EVENT_OUTBOX"`              // store domain events in postgres and relay them at least once
+	EventOutboxMaxAttempts int  `mapstructure:"EVENT_OUTBOX_MAX_ATTEMPTS"` // attempts at a stored event before it is failed, 10 when unset
+
+	JobConcurrency int `mapstructure:"JOB_CONCURRENCY"` // background jobs run at once by each replica, 4 when unset
+	JobTimeout     int `mapstructure:"JOB_TIMEOUT"`     // seconds a background job may run, 300 when unset
+
+	LinkCheckInterval     int  `mapstructure:"LINK_CHECK_INTERVAL"`      // hours between two checks of a url's destination, 24 when unset
+	LinkCheckConcurrency  int  `mapstructure:"LINK_CHECK_CONCURRENCY"`   // destinations requested at once by the link checker, 8 when unset
+	LinkCheckHostDelay    int  `mapstructure:"LINK_CHECK_HOST_DELAY"`    // milliseconds between two requests to the same host, 1000 when unset
+	LinkCheckAllowPrivate bool `mapstructure:"LINK_CHECK_ALLOW_PRIVATE"` // let the link checker request loopback, private and link-local addresses, for development
 }
 
 // Setup initialize configuration
@@ -29,7 +65,6 @@ var (
 
 func Setup() {
 	var configuration *Configuration
-	logger := log.New()
 
 	viper.SetConfigName("sample")
 	viper.SetConfigType("env")
@@ -39,12 +74,12 @@ func Setup() {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
-		logger.Fatalf("Error reading config file, %s", err)
+		log.Fatalf("Error reading config file, %s", err)
 	}
 
 	err := viper.Unmarshal(&configuration)
 	if err != nil {
-		logger.Fatalf("Unable to decode into struct, %v", err)
+		log.Fatalf("Unable to decode into struct, %v", err)
 	}
 
 	if port := os.Getenv("PORT"); port != "" {
@@ -52,7 +87,7 @@ func Setup() {
 	}
 
 	Config = configuration
-	logger.Info("configurations loading successfully")
+	log.Info("configurations loading successfully")
 }
 
 // GetConfig helps you to get configuration data
@@ -5,6 +5,8 @@ const AppName = "UnifyLogic"
 const (
 	Admin      string = "admin"
 	User       string = "user"
+	Moderator  string = "moderator"
+	Analyst    string = "analyst"
 	CounterKey string = "counter"
 )
 
@@ -13,7 +15,161 @@ const (
 	StatusFailed  = "failed"
 )
 
+// Health statuses of an instance and of its checks
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// Query modes decide how query parameters on a short link are combined
+// with the query of its destination
+const (
+	QueryMerge    = "merge"    // destination wins on conflicting keys
+	QueryOverride = "override" // incoming request wins on conflicting keys
+	QueryAppend   = "append"   // values from both are kept
+)
+
 var Roles = map[string]int{
-	Admin: 1,
-	User:  2,
+	Admin:     1,
+	User:      2,
+	Moderator: 3,
+	Analyst:   4,
 }
+
+// RoleRanks orders roles by the authority they carry, staff can only act on users of a lower rank
+var RoleRanks = map[int]int{
+	Roles[Admin]:     4,
+	Roles[Moderator]: 3,
+	Roles[Analyst]:   2,
+	Roles[User]:      1,
+}
+
+// Permissions grant access to resources owned by other users, or to administrative actions.
+// They are named 'resource:action[:scope]'
+const (
+	PermURLReadAny    = "url:read:any"
+	PermURLUpdateAny  = "url:update:any"
+	PermURLDeleteAny  = "url:delete:any"
+	PermUserReadAny   = "user:read:any"
+	PermUserLock      = "user:lock"
+	PermUserDeleteAny = "user:delete:any"
+	PermRoleAssign    = "role:assign"
+	PermAuditRead     = "audit:read"
+	PermStatsRead     = "stats:read"
+
+	PermWorkspaceManageAny = "workspace:manage:any"
+	PermDomainManageAny    = "domain:manage:any"
+	PermCampaignManageAny  = "campaign:manage:any"
+	PermWebhookManageAny   = "webhook:manage:any"
+)
+
+// RolePermissions defines every role as the set of permissions it is granted
+var RolePermissions = map[int][]string{
+	Roles[Admin]: {
+		PermURLReadAny, PermURLUpdateAny, PermURLDeleteAny, PermUserReadAny,
+		PermUserLock, PermUserDeleteAny, PermRoleAssign, PermAuditRead, PermStatsRead,
+		PermWorkspaceManageAny, PermDomainManageAny, PermCampaignManageAny, PermWebhookManageAny,
+	},
+	Roles[Moderator]: {
+		PermURLReadAny, PermURLDeleteAny, PermUserReadAny, PermUserLock, PermStatsRead,
+	},
+	Roles[Analyst]: {
+		PermURLReadAny, PermStatsRead,
+	},
+	Roles[User]: {},
+}
+
+// What happens to the links of a user who deletes their account
+const (
+	LinksDelete   = "delete"   // links are permanently deleted with the account
+	LinksTransfer = "transfer" // links are given to the admin user
+	LinksKeep     = "keep"     // links stay live under an anonymized account
+)
+
+// Workspace member roles, a higher rank includes the permissions of the lower ones
+const (
+	WorkspaceOwner  = "owner"
+	WorkspaceEditor = "editor"
+	WorkspaceViewer = "viewer"
+)
+
+var WorkspaceRoles = map[string]int{
+	WorkspaceOwner:  3,
+	WorkspaceEditor: 2,
+	WorkspaceViewer: 1,
+}
+
+// Audited actions, named 'target.action'
+const (
+	AuditLogin         = "user.login"
+	AuditLoginFailed   = "user.login_failed"
+	AuditPasswordReset = "user.password_reset"
+	AuditUserLock      = "user.lock"
+	AuditUserUnlock    = "user.unlock"
+	AuditRoleChange    = "user.role_change"
+	AuditURLCreate     = "url.create"
+	AuditURLUpdate     = "url.update"
+	AuditURLDelete     = "url.delete"
+	AuditURLRestore    = "url.restore"
+	AuditUserDelete    = "user.delete"
+	AuditUserRestore   = "user.restore"
+)
+
+// Types of the targets of audited actions
+const (
+	AuditTargetUser = "user"
+	AuditTargetURL  = "url"
+)
+
+// Events webhooks can subscribe to, named 'target.event'
+const (
+	EventURLCreated = "url.created"
+	EventURLDeleted = "url.deleted"
+	EventURLClicked = "url.clicked"
+	EventURLBroken  = "url.broken"
+	EventUserLocked = "user.locked"
+)
+
+// Statuses of webhook deliveries
+const (
+	DeliveryPending   = "pending"   // waiting for its first or next attempt
+	DeliverySucceeded = "succeeded" // the webhook answered with a 2xx status
+	DeliveryFailed    = "failed"    // every attempt failed
+)
+
+// Domain events published on the event bus, named 'target.event'
+const (
+	DomainURLCreated     = "url.created"
+	DomainURLDeleted     = "url.deleted"
+	DomainRedirected     = "url.redirected"
+	DomainURLBroken      = "url.broken"
+	DomainUserRegistered = "user.registered"
+	DomainUserLocked     = "user.locked"
+)
+
+// Statuses of the events stored in the event outbox
+const (
+	OutboxPending   = "pending"   // some subscribers have not handled the event yet
+	OutboxProcessed = "processed" // every subscriber handled the event
+	OutboxFailed    = "failed"    // a subscriber failed on every attempt
+)
+
+// Statuses of background jobs
+const (
+	JobPending   = "pending"   // waiting for its first or next attempt, or running
+	JobSucceeded = "succeeded" // the last attempt succeeded
+	JobFailed    = "failed"    // every attempt failed
+)
+
+// Background jobs, named 'target.action'
+const (
+	JobRetentionPurge = "retention.purge"
+	JobLinkCheck      = "linkcheck.run"
+)
+
+// Health of the destination of a url, as found by the link checker
+const (
+	LinkOK      = "ok"      // the latest check succeeded
+	LinkFailing = "failing" // the latest checks failed, but too few of them to call the link broken
+	LinkBroken  = "broken"  // enough checks failed in a row
+)
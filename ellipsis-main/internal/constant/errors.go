@@ -6,4 +6,5 @@ const (
 	ErrUnauthorized = "unauthorized"
 	ErrBinding      = "binding error"
 	ErrRequest      = "could not execute request"
+	ErrUnavailable  = "service unavailable"
 )
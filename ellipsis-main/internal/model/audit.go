@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog is an append-only record of a security-relevant or administrative action
+type AuditLog struct {
+	ID         string          `json:"id,omitempty" gorm:"column:id;primaryKey;type:varchar(50)"`
+	ActorID    string          `json:"actor_id,omitempty" gorm:"column:actor_id;index;type:varchar(50)"`
+	Action     string          `json:"action,omitempty" gorm:"column:action;index;not null;type:varchar(50)"`
+	TargetType string          `json:"target_type,omitempty" gorm:"column:target_type;index:idx_audit_logs_target;not null;type:varchar(20)"`
+	TargetID   string          `json:"target_id,omitempty" gorm:"column:target_id;index:idx_audit_logs_target;type:varchar(100)"`
+	IP         string          `json:"ip,omitempty" gorm:"column:ip;type:varchar(45)"`
+	UserAgent  string          `json:"user_agent,omitempty" gorm:"column:user_agent;type:text"`
+	Before     json.RawMessage `json:"before,omitempty" gorm:"column:before;type:jsonb" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" gorm:"column:after;type:jsonb" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// AuditFilter narrows down the audit logs that are fetched, empty fields match everything
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type UTM struct {
+	Source   string `json:"source,omitempty" gorm:"column:source;type:varchar(100)" validate:"required,max=100"`
+	Medium   string `json:"medium,omitempty" gorm:"column:medium;type:varchar(100)" validate:"required,max=100"`
+	Campaign string `json:"campaign,omitempty" gorm:"column:campaign;type:varchar(100)" validate:"required,max=100"`
+	Term     string `json:"term,omitempty" gorm:"column:term;type:varchar(100)" validate:"max=100"`
+	Content  string `json:"content,omitempty" gorm:"column:content;type:varchar(100)" validate:"max=100"`
+}
+
+type Campaign struct {
+	ID        string    `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	Name      string    `json:"name,omitempty" gorm:"column:name;not null;type:varchar(100)" validate:"required,max=100"`
+	UTM       UTM       `json:"utm" gorm:"embedded;embeddedPrefix:utm_"`
+	UserID    string    `json:"user_id,omitempty" gorm:"column:user_id;index;not null;type:varchar(50)"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+	Urls      []URL     `json:"-" gorm:"foreignKey:campaign_id" swaggerignore:"true"`
+}
+
+type CampaignSummary struct {
+	Campaign  *Campaign `json:"campaign"`
+	LinkCount int       `json:"link_count"`
+	Urls      []URL     `json:"urls"`
+}
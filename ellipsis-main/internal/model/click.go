@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type Click struct {
+	ID        string    `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	URLID     string    `json:"url_id,omitempty" gorm:"column:url_id;index;not null;type:varchar(50)"`
+	VariantID string    `json:"variant_id,omitempty" gorm:"column:variant_id;index;type:varchar(50);default:null"`
+	Country   string    `json:"country,omitempty" gorm:"column:country;type:varchar(2)"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// LinkStats summarises the clicks of a url
+type LinkStats struct {
+	URLID      string           `json:"url_id"`
+	Hash       string           `json:"hash"`
+	Clicks     int64            `json:"clicks"`
+	Countries  map[string]int64 `json:"countries"`
+	FirstClick *time.Time       `json:"first_click,omitempty"`
+	LastClick  *time.Time       `json:"last_click,omitempty"`
+}
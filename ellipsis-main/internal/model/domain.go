@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Domain is a custom host links can be issued under once its owner proved control over it
+type Domain struct {
+	ID         string     `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	Host       string     `json:"host,omitempty" gorm:"column:host;index;unique;not null;type:varchar(255)" validate:"required,hostname_rfc1123,max=255"`
+	UserID     string     `json:"user_id,omitempty" gorm:"column:user_id;index;not null;type:varchar(50)"`
+	Method     string     `json:"method,omitempty" gorm:"column:method;not null;type:varchar(10)" validate:"required,oneof=dns http"`
+	Token      string     `json:"token,omitempty" gorm:"column:token;not null;type:varchar(100)"`
+	Verified   bool       `json:"verified" gorm:"column:verified;not null;default:false"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty" gorm:"column:verified_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// DomainChallenge describes what the owner of a domain has to publish to verify it
+type DomainChallenge struct {
+	Domain *Domain `json:"domain"`
+	Record string  `json:"record,omitempty"` // DNS TXT record name, for the 'dns' method
+	URL    string  `json:"url,omitempty"`    // location of the file, for the 'http' method
+	Value  string  `json:"value"`            // expected TXT record value or file content
+}
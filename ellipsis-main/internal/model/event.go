@@ -0,0 +1,20 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event stored until every subscriber of the event bus has handled it
+type OutboxEvent struct {
+	ID            string          `json:"id" gorm:"column:id;primaryKey;type:varchar(50)"`
+	Name          string          `json:"name" gorm:"column:name;not null;type:varchar(50)"`
+	Payload       json.RawMessage `json:"payload" gorm:"column:payload;not null;type:jsonb"`
+	Status        string          `json:"status" gorm:"column:status;index:idx_outbox_events_due;not null;type:varchar(20)"`
+	Attempts      int             `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"column:next_attempt_at;index:idx_outbox_events_due"`
+	Completed     []string        `json:"completed" gorm:"column:completed;not null;type:jsonb;serializer:json"` // subscribers that handled the event
+	Error         string          `json:"error,omitempty" gorm:"column:error;type:text"`                         // why the last attempt failed
+	CreatedAt     time.Time       `json:"created_at" gorm:"column:created_at;index"`
+	ProcessedAt   *time.Time      `json:"processed_at,omitempty" gorm:"column:processed_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// Health is the status of an instance and of each of its dependencies
+type Health struct {
+	Status string        `json:"status"` // ok, or unavailable when a check fails or the instance is shutting down
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck is the result of checking one dependency
+type HealthCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work, queued until a runner has run it. Scheduled jobs are named
+// after their schedule and the time they are due, so that replicas queue each of them once
+type Job struct {
+	ID         string          `json:"id" gorm:"column:id;primaryKey;type:varchar(100)"`
+	Name       string          `json:"name" gorm:"column:name;not null;type:varchar(50)"`
+	Payload    json.RawMessage `json:"payload" gorm:"column:payload;not null;type:jsonb"`
+	Status     string          `json:"status" gorm:"column:status;index:idx_jobs_due;not null;type:varchar(20)"`
+	Attempts   int             `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	RunAt      time.Time       `json:"run_at" gorm:"column:run_at;index:idx_jobs_due"`
+	Error      string          `json:"error,omitempty" gorm:"column:error;type:text"` // why the last attempt failed
+	CreatedAt  time.Time       `json:"created_at" gorm:"column:created_at;index"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty" gorm:"column:finished_at"`
+}
+
+// JobLease is held by the replica running a singleton job until it is done or the lease expires
+type JobLease struct {
+	Name      string    `json:"name" gorm:"column:name;primaryKey;type:varchar(50)"`
+	Holder    string    `json:"holder" gorm:"column:holder;not null;type:varchar(50)"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;not null"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// LinkCheck is the outcome of a request to the destination of a url by the link checker
+type LinkCheck struct {
+	ID         string    `json:"id" gorm:"column:id;primaryKey;type:varchar(50)"`
+	URLID      string    `json:"url_id" gorm:"column:url_id;index;not null;type:varchar(50)"`
+	OK         bool      `json:"ok" gorm:"column:ok;not null"`
+	StatusCode int       `json:"status_code,omitempty" gorm:"column:status_code"` // zero when no response was received
+	Error      string    `json:"error,omitempty" gorm:"column:error;type:text"`
+	LatencyMs  int64     `json:"latency_ms" gorm:"column:latency_ms"`
+	CheckedAt  time.Time `json:"checked_at" gorm:"column:checked_at;index"`
+}
+
+// BrokenLink is a url whose destination is broken, with the latest check of it
+type BrokenLink struct {
+	URL       URL        `json:"url"`
+	LastCheck *LinkCheck `json:"last_check,omitempty"`
+}
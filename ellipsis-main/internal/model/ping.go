@@ -1,5 +0,0 @@
-package model
-
-type Ping struct {
-	Message string `json:"email"`
-}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// RedirectRule sends visitors matching all of its non-empty conditions to 'Destination'.
+// Rules of a url are evaluated in order of 'Position', the first match wins
+type RedirectRule struct {
+	ID          string     `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	URLID       string     `json:"url_id,omitempty" gorm:"column:url_id;index;not null;type:varchar(50)"`
+	Position    int        `json:"position" gorm:"column:position;not null"`
+	OS          string     `json:"os,omitempty" gorm:"column:os;type:varchar(20)" validate:"omitempty,oneof=ios android windows macos linux"`
+	Device      string     `json:"device,omitempty" gorm:"column:device;type:varchar(20)" validate:"omitempty,oneof=mobile tablet desktop bot"`
+	Browser     string     `json:"browser,omitempty" gorm:"column:browser;type:varchar(20)" validate:"omitempty,oneof=chrome firefox safari edge opera"`
+	Language    string     `json:"language,omitempty" gorm:"column:language;type:varchar(20)" validate:"omitempty,max=20"`
+	Country     string     `json:"country,omitempty" gorm:"column:country;type:varchar(2)" validate:"omitempty,iso3166_1_alpha2"`
+	StartsAt    *time.Time `json:"starts_at,omitempty" gorm:"column:starts_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty" gorm:"column:ends_at"`
+	Destination string     `json:"destination,omitempty" gorm:"column:destination;not null" validate:"required,url"`
+}
+
+type RuleSet struct {
+	Rules []RedirectRule `json:"rules" validate:"dive"`
+}
+
+// Visitor holds the request details redirect rules are matched against
+type Visitor struct {
+	OS        string
+	Device    string
+	Browser   string
+	Languages []string
+	Country   string
+	Time      time.Time
+}
@@ -1,11 +1,28 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type URL struct {
-	ID        string    `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
-	LongURL   string    `json:"long_url,omitempty" gorm:"column:long_url;not null" validate:"required"`
-	Hash      string    `json:"hash,omitempty" gorm:"column:hash;not null;unique;index"`
-	UserID    string    `json:"user_id,omitempty" gorm:"column:user_id;index"`
-	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+	ID           string         `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	LongURL      string         `json:"long_url,omitempty" gorm:"column:long_url;not null" validate:"required"`
+	Hash         string         `json:"hash,omitempty" gorm:"column:hash;not null;index;uniqueIndex:idx_urls_domain_hash"`
+	DomainID     string         `json:"domain_id,omitempty" gorm:"column:domain_id;not null;default:'';type:varchar(50);uniqueIndex:idx_urls_domain_hash"`
+	UserID       string         `json:"user_id,omitempty" gorm:"column:user_id;index"`
+	WorkspaceID  string         `json:"workspace_id,omitempty" gorm:"column:workspace_id;index;type:varchar(50);default:null"`
+	ForwardQuery bool           `json:"forward_query,omitempty" gorm:"column:forward_query;not null;default:false"`
+	QueryMode    string         `json:"query_mode,omitempty" gorm:"column:query_mode;type:varchar(20)" validate:"omitempty,oneof=merge override append"`
+	ForwardPath  bool           `json:"forward_path,omitempty" gorm:"column:forward_path;not null;default:false"`
+	CampaignID   string         `json:"campaign_id,omitempty" gorm:"column:campaign_id;index;type:varchar(50);default:null"`
+	LinkStatus   string         `json:"link_status,omitempty" gorm:"column:link_status;index;type:varchar(20)"` // health of the destination, empty until it is checked
+	LinkFailures int            `json:"link_failures,omitempty" gorm:"column:link_failures;not null;default:0"` // checks failed in a row
+	CheckedAt    *time.Time     `json:"checked_at,omitempty" gorm:"column:checked_at;index"`
+	UTM          *UTM           `json:"utm,omitempty" gorm:"-" validate:"-"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"column:created_at;index"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"column:deleted_at;index" swaggertype:"string" format:"date-time"`
+	Rules        []RedirectRule `json:"rules,omitempty" gorm:"foreignKey:url_id;constraint:OnDelete:CASCADE" validate:"omitempty,dive"`
+	Variants     []Variant      `json:"variants,omitempty" gorm:"foreignKey:url_id;constraint:OnDelete:CASCADE" validate:"omitempty,dive"`
 }
@@ -1,19 +1,24 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
-	ID        string    `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
-	Firstname string    `json:"firstname,omitempty" gorm:"column:firstname;not null;type:varchar(100)" validate:"required"`
-	Lastname  string    `json:"lastname,omitempty" gorm:"column:lastname;type:varchar(100)"`
-	Email     string    `json:"email,omitempty" gorm:"column:email;index;unique;not null;type:varchar(100)" validate:"email,required"`
-	Password  string    `json:"password,omitempty" gorm:"column:password;type:varchar(100);not null" validate:"required,min=8"`
-	Role      int       `json:"role,omitempty" gorm:"column:role;not null;type:smallint"`
-	IsLocked  bool      `json:"is_locked,omitempty" gorm:"column:is_locked"`
-	Salt      string    `json:"salt,omitempty" gorm:"column:salt;not null;type:varchar(50)"`
-	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
-	Urls      []URL     `json:"-" gorm:"foreignKey:user_id" swaggerignore:"true"`
+	ID        string         `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	Firstname string         `json:"firstname,omitempty" gorm:"column:firstname;not null;type:varchar(100)" validate:"required"`
+	Lastname  string         `json:"lastname,omitempty" gorm:"column:lastname;type:varchar(100)"`
+	Email     string         `json:"email,omitempty" gorm:"column:email;index;unique;not null;type:varchar(100)" validate:"email,required"`
+	Password  string         `json:"password,omitempty" gorm:"column:password;type:varchar(100);not null" validate:"required,min=8"`
+	Role      int            `json:"role,omitempty" gorm:"column:role;not null;type:smallint"`
+	IsLocked  bool           `json:"is_locked,omitempty" gorm:"column:is_locked"`
+	Salt      string         `json:"salt,omitempty" gorm:"column:salt;not null;type:varchar(50)"`
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at;index"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"column:deleted_at;index" swaggertype:"string" format:"date-time"`
+	Urls      []URL          `json:"-" gorm:"foreignKey:user_id" swaggerignore:"true"`
 }
 
 type UserLogin struct {
@@ -35,8 +40,30 @@ type ForgotPassword struct {
 	Email string `json:"email,omitempty"`
 }
 
+// DeleteAccount confirms the deletion of an account and decides what happens to its links
+type DeleteAccount struct {
+	Password string `json:"password,omitempty" validate:"required"`
+	Links    string `json:"links,omitempty" validate:"required,oneof=delete transfer keep"`
+}
+
 type ContextInfo struct {
-	ID    string
-	Role  int
-	Email string
+	ID        string
+	Role      int
+	Email     string
+	IP        string
+	UserAgent string
+}
+
+type RoleAssignment struct {
+	Role string `json:"role,omitempty" validate:"required,oneof=admin user moderator analyst"`
+}
+
+// RoleChange records a change of a user's role, for auditing
+type RoleChange struct {
+	ID        string    `json:"id,omitempty" gorm:"column:id;primaryKey;type:varchar(50)"`
+	UserID    string    `json:"user_id,omitempty" gorm:"column:user_id;index;not null;type:varchar(50)"`
+	ChangedBy string    `json:"changed_by,omitempty" gorm:"column:changed_by;not null;type:varchar(50)"`
+	OldRole   int       `json:"old_role" gorm:"column:old_role;not null;type:smallint"`
+	NewRole   int       `json:"new_role" gorm:"column:new_role;not null;type:smallint"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
 }
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Variant is one of several weighted destinations of a url used for A/B testing
+type Variant struct {
+	ID          string    `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	URLID       string    `json:"url_id,omitempty" gorm:"column:url_id;index;not null;type:varchar(50)"`
+	Position    int       `json:"position" gorm:"column:position;not null"`
+	Name        string    `json:"name,omitempty" gorm:"column:name;not null;type:varchar(50)" validate:"required,max=50"`
+	Destination string    `json:"destination,omitempty" gorm:"column:destination;not null" validate:"required,url"`
+	Weight      int       `json:"weight,omitempty" gorm:"column:weight;not null" validate:"required,min=1,max=10000"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+type VariantSet struct {
+	Variants []Variant `json:"variants" validate:"dive"`
+}
+
+type VariantStats struct {
+	Variant
+	Clicks int64 `json:"clicks" gorm:"column:clicks"`
+}
+
+// Redirection is the outcome of resolving a short url for a visitor
+type Redirection struct {
+	URL         *URL
+	Destination string
+	Variant     *Variant // variant served to the visitor, if the url is split tested
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is an endpoint of a user that is sent the events it subscribes to
+type Webhook struct {
+	ID        string    `json:"id,omitempty" gorm:"column:id;primaryKey;type:varchar(50)"`
+	UserID    string    `json:"user_id,omitempty" gorm:"column:user_id;index;not null;type:varchar(50)"`
+	URL       string    `json:"url" gorm:"column:url;not null;type:text" validate:"required,http_url,max=2048"`
+	Events    []string  `json:"events" gorm:"column:events;not null;type:jsonb;serializer:json" validate:"required,min=1,dive,oneof=url.created url.deleted url.clicked url.broken user.locked"`
+	Secret    string    `json:"secret,omitempty" gorm:"column:secret;not null;type:varchar(100)"` // signs payloads, only returned when the webhook is created
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// WebhookDelivery is an event sent, or still to be sent, to a webhook. Pending deliveries are the
+// outbox, the others are the delivery log
+type WebhookDelivery struct {
+	ID             string          `json:"id,omitempty" gorm:"column:id;primaryKey;type:varchar(50)"`
+	WebhookID      string          `json:"webhook_id,omitempty" gorm:"column:webhook_id;index;not null;type:varchar(50)"`
+	Event          string          `json:"event,omitempty" gorm:"column:event;not null;type:varchar(50)"`
+	Payload        json.RawMessage `json:"payload,omitempty" gorm:"column:payload;not null;type:jsonb" swaggertype:"object"`
+	Status         string          `json:"status,omitempty" gorm:"column:status;index:idx_webhook_deliveries_due;not null;type:varchar(20)"`
+	Attempts       int             `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"column:next_attempt_at;index:idx_webhook_deliveries_due"`
+	ResponseStatus int             `json:"response_status,omitempty" gorm:"column:response_status"` // status code of the last attempt
+	Error          string          `json:"error,omitempty" gorm:"column:error;type:text"`           // why the last attempt failed
+	ReplayOf       string          `json:"replay_of,omitempty" gorm:"column:replay_of;type:varchar(50)"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"column:created_at;index"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+}
+
+// WebhookEvent is the body posted to webhooks. A replayed delivery posts the same event, so
+// receivers can tell duplicates apart by its 'id'
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
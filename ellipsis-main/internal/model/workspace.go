@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Workspace groups links shared by a team so they outlive the membership of any one user
+type Workspace struct {
+	ID        string       `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	Name      string       `json:"name,omitempty" gorm:"column:name;not null;type:varchar(100)" validate:"required,max=100"`
+	OwnerID   string       `json:"owner_id,omitempty" gorm:"column:owner_id;index;not null;type:varchar(50)"`
+	CreatedAt time.Time    `json:"created_at" gorm:"column:created_at;index"`
+	Members   []Membership `json:"-" gorm:"foreignKey:workspace_id;constraint:OnDelete:CASCADE" swaggerignore:"true"`
+	Urls      []URL        `json:"-" gorm:"foreignKey:workspace_id" swaggerignore:"true"`
+}
+
+type Membership struct {
+	WorkspaceID string    `json:"workspace_id,omitempty" gorm:"column:workspace_id;primaryKey;type:varchar(50)"`
+	UserID      string    `json:"user_id,omitempty" gorm:"column:user_id;primaryKey;index;type:varchar(50)"`
+	Role        string    `json:"role,omitempty" gorm:"column:role;not null;type:varchar(10)" validate:"required,oneof=owner editor viewer"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+type Invitation struct {
+	ID          string     `json:"id,omitempty" gorm:"column:id;index;unique;not null;type:varchar(50)"`
+	WorkspaceID string     `json:"workspace_id,omitempty" gorm:"column:workspace_id;index;not null;type:varchar(50)"`
+	Email       string     `json:"email,omitempty" gorm:"column:email;index;not null;type:varchar(100)" validate:"required,email"`
+	Role        string     `json:"role,omitempty" gorm:"column:role;not null;type:varchar(10)" validate:"required,oneof=editor viewer"`
+	Token       string     `json:"token,omitempty" gorm:"column:token;unique;not null;type:varchar(50)"`
+	InvitedBy   string     `json:"invited_by,omitempty" gorm:"column:invited_by;not null;type:varchar(50)"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty" gorm:"column:accepted_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+type MemberRole struct {
+	Role string `json:"role,omitempty" validate:"required,oneof=editor viewer"`
+}
+
+type WorkspaceTransfer struct {
+	UserID string `json:"user_id,omitempty" validate:"required"`
+}
+
+type URLWorkspace struct {
+	WorkspaceID string `json:"workspace_id"` // empty to move a link back to its creator
+}
@@ -1,7 +1,20 @@
 package main
 
 import (
+	"brief/pkg/events"
+	"brief/pkg/geoip"
+	"brief/pkg/jobs"
+	"brief/pkg/logging"
+	"brief/pkg/metrics"
+	mdw "brief/pkg/middleware"
 	pgdb "brief/pkg/repository/storage/postgres"
+	rdb "brief/pkg/repository/storage/redis"
+	"brief/pkg/tracing"
+	healthSrv "brief/service/health"
+	"brief/service/linkcheck"
+	"brief/service/retention"
+	userSrv "brief/service/user"
+	webhookSrv "brief/service/webhook"
 	"context"
 	"fmt"
 	"net/http"
@@ -16,18 +29,16 @@ import (
 	"brief/pkg/router"
 
 	"github.com/go-playground/validator/v10"
-	// rdb "brief/pkg/repository/storage/redis"
 )
 
 func init() {
 	config.Setup()
-	pgdb.ConnectToDB()
-	// redis.SetupRedis() uncomment when you need redis
+	// redis.SetupRedis(log.StandardLogger()) uncomment when you need redis
 }
 
 //	@title			Brief
 //	@version		1.0
-//	@description	URL Shortener.
+//	@description	URL Shortener. Failed requests answer with a Response whose 'name' is a stable error code, or with an RFC 7807 Problem when the 'Accept' header includes 'application/problem+json'.
 //	@termsOfService	http://swagger.io/terms/
 
 //	@contact.name	API Support
@@ -49,11 +60,51 @@ func init() {
 //	@externalDocs.url			https://swagger.io/resources/open-api/
 
 func main() {
+	// One logger, configured from 'LOG_*', is shared by every command
+	logger, err := logging.Setup()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The server runs when no subcommand is given
+	name, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		name, args = os.Args[1], os.Args[2:]
+	}
+
+	if err := runCommand(logger, name, args); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// serve runs the HTTP server until it receives an interrupt signal
+func serve(logger *log.Logger, args []string) error {
+	geoip.Setup(logger)
+
+	// Export traces, when an exporter is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), logger)
+	if err != nil {
+		return fmt.Errorf("could not set up tracing, got error: %w", err)
+	}
+
+	// Bring the database schema up to date before serving
+	if err := runMigrate(logger, []string{"up"}); err != nil {
+		return fmt.Errorf("could not run db migrations, got error: %w", err)
+	}
+
+	// Create admin user
+	if err := userSrv.NewUserService(pgdb.GetDB(), events.GetBus()).CreateAdminUser(context.Background(), logger); err != nil {
+		logger.Error(err)
+	}
+
 	//Load config
-	logger := log.New()
 	getConfig := config.GetConfig()
 	validatorRef := validator.New()
-	e := router.Setup(validatorRef, logger)
+	hService, err := readiness(logger)
+	if err != nil {
+		return err
+	}
+	e := router.Setup(validatorRef, logger, hService)
 
 	// The HTTP Server
 	server := &http.Server{
@@ -61,9 +112,48 @@ func main() {
 		Handler: e,
 	}
 
+	// The metrics server, when metrics are kept off the API's address
+	var metricsServer *http.Server
+	if getConfig.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", mdw.RequireToken(getConfig.MetricsToken)(metrics.Handler()))
+		metricsServer = &http.Server{Addr: getConfig.MetricsAddr, Handler: mux}
+
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Fatalf("could not serve metrics, got error: %s", err)
+			}
+		}()
+	}
+
 	// Server run context
 	serverCtx, serverCancel := context.WithCancel(context.Background())
 
+	// Run background jobs, urls and users past their retention period are permanently deleted
+	// every hour by one replica, which also checks the destinations of urls for broken links
+	runner := jobs.NewRunner(pgdb.GetDB(), getConfig.JobConcurrency, time.Duration(getConfig.JobTimeout)*time.Second)
+	checker := linkcheck.NewLinkCheckService(pgdb.GetDB(), events.GetBus(),
+		time.Duration(getConfig.LinkCheckInterval)*time.Hour, getConfig.LinkCheckConcurrency,
+		time.Duration(getConfig.LinkCheckHostDelay)*time.Millisecond)
+	err = retention.Schedule(runner, pgdb.GetDB())
+	if err == nil {
+		err = linkcheck.Schedule(runner, checker)
+	}
+	if err != nil {
+		serverCancel()
+		return fmt.Errorf("could not schedule jobs, got error: %w", err)
+	}
+	go runner.Run(serverCtx, logger, time.Second)
+
+	// Send the events queued in the webhook outbox
+	webhookTimeout := time.Duration(getConfig.WebhookTimeout) * time.Second
+	go webhookSrv.NewDispatcher(pgdb.GetDB(), webhookTimeout, getConfig.WebhookMaxAttempts).Run(serverCtx, logger, 5*time.Second)
+
+	// Relay the domain events stored in the event outbox, when it is enabled
+	if outbox, ok := events.GetBus().(*events.Outbox); ok {
+		go outbox.Run(serverCtx, logger, time.Second)
+	}
+
 	// Listen for syscall signals for process to interrupt/quit
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
@@ -76,29 +166,77 @@ func main() {
 		go func() {
 			<-shutdownCtx.Done()
 			if shutdownCtx.Err() == context.DeadlineExceeded {
-				log.Fatal("graceful shutdown timed out.. forcing exit.")
+				logger.Fatal("graceful shutdown timed out.. forcing exit.")
 			}
 		}()
 
+		// Stop receiving traffic before the server stops accepting it
+		hService.Drain()
+		if delay := time.Duration(getConfig.ShutdownDelay) * time.Second; delay > 0 {
+			logger.Infof("reporting unready for %s before shutting down", delay)
+			time.Sleep(delay)
+		}
+
 		// Store counter variable in redis
-		// redis.StoreCounter()
+		// redis.StoreCounter(logger)
 
 		// Trigger graceful shutdown
 		err := server.Shutdown(shutdownCtx)
 		if err != nil {
-			log.Fatal(err)
+			logger.Fatal(err)
+		}
+		if metricsServer != nil {
+			metricsServer.Shutdown(shutdownCtx)
+		}
+		// Let async subscribers finish handling the events of the last requests
+		if err := events.GetBus().Drain(shutdownCtx); err != nil {
+			logger.Error(err)
+		}
+		// Let running jobs finish, those cut short by the grace period are retried later
+		if err := runner.Drain(shutdownCtx); err != nil {
+			logger.Error(err)
+		}
+		// Flush the spans still buffered for export
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error(err)
 		}
 		shutdownCancel()
 		serverCancel()
 	}()
 
 	// Run the server
-	fmt.Printf("Server is now listening on port: %s\n", getConfig.ServerPort)
-	err := server.ListenAndServe()
+	logger.Infof("Server is now listening on port: %s", getConfig.ServerPort)
+	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
-		log.Fatal(err)
+		return err
 	}
 
 	// Wait for server context to be stopped
 	<-serverCtx.Done()
+	return nil
+}
+
+// readiness returns the health service with the checks of every dependency the server needs
+func readiness(logger *log.Logger) (healthSrv.HealthService, error) {
+	getConfig := config.GetConfig()
+	timeout := time.Duration(getConfig.HealthTimeout) * time.Second
+
+	hService := healthSrv.NewHealthService()
+	hService.Register("postgres", healthSrv.Ping(pgdb.GetDB()), timeout)
+
+	migrator, err := pgdb.Migrator(logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not load db migrations, got error: %w", err)
+	}
+	hService.Register("migrations", healthSrv.Migrations(migrator), timeout)
+
+	if rdb.Rds != nil {
+		hService.Register("redis", healthSrv.Ping(rdb.GetRedisDb()), timeout)
+	}
+
+	if getConfig.LogFile != "" {
+		hService.Register("log_disk", healthSrv.Disk(getConfig.LogFile, uint64(getConfig.HealthMinDiskMB)<<20), timeout)
+	}
+
+	return hService, nil
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	pgdb "brief/pkg/repository/storage/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const migrateUsage = `usage: brief migrate <command>
+
+commands:
+  up                apply every pending migration
+  down [steps]      roll back the latest 'steps' migrations, 1 by default
+  status [-o json]  list migrations and whether they are applied
+  to <version>      apply or roll back migrations up to 'version', 0 rolls back everything`
+
+// runMigrate runs the 'migrate' subcommand with its 'args'
+func runMigrate(logger *log.Logger, args []string) error {
+	migrator, err := pgdb.Migrator(logger)
+	if err != nil {
+		return fmt.Errorf("could not load migrations, got error: %w", err)
+	}
+
+	ctx := context.Background()
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+		}
+		return migrator.Down(ctx, steps)
+
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("version must be a number, 0 or above")
+		}
+		return migrator.To(ctx, version)
+
+	case "status":
+		fs, format := newFlagSet("migrate status")
+		if err := parseFlags(fs, format, args[1:], 0); err != nil {
+			return err
+		}
+
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		return output(*format, statuses, func(w io.Writer) {
+			fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+			for _, s := range statuses {
+				appliedAt := "pending"
+				if s.Applied {
+					appliedAt = s.AppliedAt.Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+			}
+		})
+	}
+
+	return errors.New(migrateUsage)
+}
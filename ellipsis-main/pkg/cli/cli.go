@@ -0,0 +1,306 @@
+// Package cli is the command line client of a brief server, built on package client
+package cli
+
+import (
+	"brief/internal/model"
+	"brief/pkg/client"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const defaultServer = "http://localhost:8080"
+
+const usage = `usage: brief [-server url] [-config file] <command> [arguments]
+
+commands:
+  login [-password p] <email>              log in and cache the token
+  logout                                   forget the cached token
+  shorten [-hash h] [-qr] [-o json] <url>  shorten a url, with a custom hash
+  list [-o json]                           list my urls
+  delete [-o json] <id>                    move my url to the trash
+  stats [-o json] <id>                     show the clicks of my url
+  qr <link>                                print a QR code of a link
+
+The server is read from '-server', BRIEF_SERVER or the cached config, ` + defaultServer + ` by default
+`
+
+type app struct {
+	ctx        context.Context
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+	server     string
+	configPath string
+	config     *Config
+	client     *client.Client
+}
+
+// Run runs the client with the command line 'args' and returns its exit code
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("brief", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	server := fs.String("server", "", "url of the brief server")
+	configFile := fs.String("config", "", "file caching the server and token")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	a := &app{ctx: context.Background(), stdin: stdin, stdout: stdout, stderr: stderr}
+	if err := a.setup(*server, *configFile); err != nil {
+		fmt.Fprintf(stderr, "brief: %s\n", err)
+		return 1
+	}
+
+	commands := map[string]func(args []string) error{
+		"login":   a.login,
+		"logout":  a.logout,
+		"shorten": a.shorten,
+		"list":    a.list,
+		"delete":  a.delete,
+		"stats":   a.stats,
+		"qr":      a.qr,
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command '%s'\n\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	if err := cmd(fs.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "brief: %s\n", err)
+
+		if errors.Is(err, client.ErrUnauthorized) {
+			fmt.Fprintln(stderr, "run 'brief login <email>' to log in")
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// setup loads the cached config and builds the client of the server
+func (a *app) setup(server, configFile string) error {
+	path, err := configPath(configFile)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("could not read config '%s', got error: %w", path, err)
+	}
+
+	a.configPath, a.config = path, config
+	for _, s := range []string{server, os.Getenv("BRIEF_SERVER"), config.Server, defaultServer} {
+		if s != "" {
+			a.server = s
+			break
+		}
+	}
+
+	a.client = client.New(a.server, client.WithToken(config.Token))
+	return nil
+}
+
+func (a *app) login(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	password := fs.String("password", os.Getenv("BRIEF_PASSWORD"), "password, prompted for when empty")
+	if err := parse(fs, nil, args, 1); err != nil {
+		return err
+	}
+
+	if *password == "" {
+		fmt.Fprint(a.stderr, "Password: ")
+		line, err := bufio.NewReader(a.stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	res, err := a.client.Login(a.ctx, fs.Arg(0), *password)
+	if err != nil {
+		return err
+	}
+
+	a.config.Server, a.config.Email, a.config.Token = a.server, fs.Arg(0), res.Token
+	if err := a.config.save(a.configPath); err != nil {
+		return fmt.Errorf("could not cache token, got error: %w", err)
+	}
+
+	fmt.Fprintf(a.stdout, "logged in to %s as %s\n", a.server, fs.Arg(0))
+	return nil
+}
+
+func (a *app) logout(args []string) error {
+	a.config.Token = ""
+	if err := a.config.save(a.configPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout, "logged out")
+	return nil
+}
+
+func (a *app) shorten(args []string) error {
+	fs, format := newFlagSet("shorten", a.stderr)
+	hash := fs.String("hash", "", "custom hash of the short link")
+	showQR := fs.Bool("qr", false, "print a QR code of the short link")
+	if err := parse(fs, format, args, 1); err != nil {
+		return err
+	}
+
+	url, err := a.client.Shorten(a.ctx, &model.URL{LongURL: fs.Arg(0), Hash: *hash})
+	if err != nil {
+		return err
+	}
+
+	return a.output(*format, url, func(w io.Writer) {
+		fmt.Fprintln(w, url.Hash)
+		if *showQR {
+			a.printQR(w, url.Hash)
+		}
+	})
+}
+
+func (a *app) list(args []string) error {
+	fs, format := newFlagSet("list", a.stderr)
+	if err := parse(fs, format, args, 0); err != nil {
+		return err
+	}
+
+	urls, err := a.client.GetUrls(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.output(*format, urls, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tHASH\tLONG URL\tCREATED AT")
+		for _, u := range urls {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.ID, u.Hash, u.LongURL, u.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	})
+}
+
+func (a *app) delete(args []string) error {
+	fs, format := newFlagSet("delete", a.stderr)
+	if err := parse(fs, format, args, 1); err != nil {
+		return err
+	}
+
+	url, err := a.client.DeleteUrl(a.ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return a.output(*format, url, func(w io.Writer) {
+		fmt.Fprintf(w, "moved %s to the trash, it can be restored until its retention period ends\n", fs.Arg(0))
+	})
+}
+
+func (a *app) stats(args []string) error {
+	fs, format := newFlagSet("stats", a.stderr)
+	if err := parse(fs, format, args, 1); err != nil {
+		return err
+	}
+
+	stats, err := a.client.GetStats(a.ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return a.output(*format, stats, func(w io.Writer) {
+		fmt.Fprintf(w, "hash\t%s\n", stats.Hash)
+		fmt.Fprintf(w, "clicks\t%d\n", stats.Clicks)
+		if stats.FirstClick != nil {
+			fmt.Fprintf(w, "first click\t%s\n", stats.FirstClick.Format("2006-01-02 15:04"))
+			fmt.Fprintf(w, "last click\t%s\n", stats.LastClick.Format("2006-01-02 15:04"))
+		}
+
+		countries := make([]string, 0, len(stats.Countries))
+		for country := range stats.Countries {
+			countries = append(countries, country)
+		}
+		sort.Slice(countries, func(i, j int) bool {
+			return stats.Countries[countries[i]] > stats.Countries[countries[j]]
+		})
+		for _, country := range countries {
+			fmt.Fprintf(w, "  %s\t%d\n", country, stats.Countries[country])
+		}
+	})
+}
+
+func (a *app) qr(args []string) error {
+	fs := flag.NewFlagSet("qr", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	if err := parse(fs, nil, args, 1); err != nil {
+		return err
+	}
+
+	return a.printQR(a.stdout, fs.Arg(0))
+}
+
+// printQR writes a QR code of 'content' with half block characters, two modules per line
+func (a *app) printQR(w io.Writer, content string) error {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("could not create QR code, got error: %w", err)
+	}
+
+	_, err = fmt.Fprint(w, code.ToSmallString(false))
+	return err
+}
+
+// newFlagSet returns the flags of a command, with the '-o' output format flag
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("o", "text", "output format, text or json")
+	return fs, format
+}
+
+// parse parses 'args' and ensures 'n' positional arguments are left
+func parse(fs *flag.FlagSet, format *string, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if format != nil && *format != "text" && *format != "json" {
+		return fmt.Errorf("output format must be text or json, got '%s'", *format)
+	}
+	if fs.NArg() != n {
+		return fmt.Errorf("%s expects %d argument(s), got %d", fs.Name(), n, fs.NArg())
+	}
+	return nil
+}
+
+// output writes 'v' as JSON when 'format' is json, or calls 'text' with a tab aligned writer
+func (a *app) output(format string, v interface{}, text func(w io.Writer)) error {
+	if format == "json" {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	text(w)
+	return w.Flush()
+}
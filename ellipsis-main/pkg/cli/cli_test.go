@@ -0,0 +1,169 @@
+// build+ unit
+package cli_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/cli"
+	"brief/utility"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeServer serves the endpoints used by the client, every endpoint but login requires
+// the token it hands out
+func fakeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	write := func(w http.ResponseWriter, rd utility.Response) {
+		res, _ := json.Marshal(rd)
+		w.WriteHeader(rd.Code)
+		w.Write(res)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/users/login", func(w http.ResponseWriter, r *http.Request) {
+		var login model.UserLogin
+		json.NewDecoder(r.Body).Decode(&login)
+		if login.Password != "password" {
+			write(w, utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
+				constant.ErrRequest, "invalid credentials", nil))
+			return
+		}
+		write(w, utility.BuildSuccessResponse(http.StatusOK, "", model.LoginResponse{Token: "token"}))
+	})
+
+	authed := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				write(w, utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
+					constant.ErrUnauthorized, "no token specified", nil))
+				return
+			}
+			next(w, r)
+		}
+	}
+
+	mux.HandleFunc("/api/v1/url/shorten", authed(func(w http.ResponseWriter, r *http.Request) {
+		var url model.URL
+		json.NewDecoder(r.Body).Decode(&url)
+		url.ID, url.Hash = "url-id", "https://brf.io/"+url.Hash
+		write(w, utility.BuildSuccessResponse(http.StatusCreated, "", url))
+	}))
+	mux.HandleFunc("/api/v1/url", authed(func(w http.ResponseWriter, r *http.Request) {
+		write(w, utility.BuildSuccessResponse(http.StatusOK, "",
+			[]model.URL{{ID: "url-id", Hash: "custom", LongURL: "https://example.com"}}))
+	}))
+	mux.HandleFunc("/api/v1/url/url-id", authed(func(w http.ResponseWriter, r *http.Request) {
+		write(w, utility.BuildSuccessResponse(http.StatusOK, "", model.URL{ID: "url-id"}))
+	}))
+	mux.HandleFunc("/api/v1/url/url-id/stats", authed(func(w http.ResponseWriter, r *http.Request) {
+		write(w, utility.BuildSuccessResponse(http.StatusOK, "",
+			model.LinkStats{URLID: "url-id", Hash: "custom", Clicks: 3, Countries: map[string]int64{"NG": 2, "GH": 1}}))
+	}))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// run runs the client with 'args' and returns its exit code and output
+func run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	srv := fakeServer(t)
+	config := filepath.Join(t.TempDir(), "brief", "config.json")
+	global := []string{"-server", srv.URL, "-config", config}
+
+	t.Run("Not_Logged_In", func(t *testing.T) {
+		code, _, stderr := run("", append(global, "list")...)
+		if code != 1 || !strings.Contains(stderr, "brief login") {
+			t.Errorf("Expected exit code '1' with a login hint, got '%d' and '%s'", code, stderr)
+		}
+	})
+
+	t.Run("Login_Wrong_Password", func(t *testing.T) {
+		code, _, stderr := run("wrong\n", append(global, "login", "john@doe.com")...)
+		if code != 1 || !strings.Contains(stderr, "invalid credentials") {
+			t.Errorf("Expected exit code '1' with 'invalid credentials', got '%d' and '%s'", code, stderr)
+		}
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		code, stdout, stderr := run("password\n", append(global, "login", "john@doe.com")...)
+		if code != 0 || !strings.Contains(stdout, "logged in") {
+			t.Errorf("Expected exit code '0', got '%d' and '%s'", code, stderr)
+		}
+	})
+
+	// The server and token are cached from now on
+	cached := []string{"-config", config}
+
+	t.Run("Shorten", func(t *testing.T) {
+		code, stdout, stderr := run("", append(cached, "shorten", "-hash", "custom", "https://example.com")...)
+		if code != 0 || strings.TrimSpace(stdout) != "https://brf.io/custom" {
+			t.Errorf("Expected 'https://brf.io/custom', got '%s' and '%s'", stdout, stderr)
+		}
+	})
+
+	t.Run("Shorten_QR", func(t *testing.T) {
+		code, stdout, _ := run("", append(cached, "shorten", "-qr", "-hash", "custom", "https://example.com")...)
+		if code != 0 || !strings.Contains(stdout, "█") {
+			t.Errorf("Expected a QR code, got '%s'", stdout)
+		}
+	})
+
+	t.Run("List_JSON", func(t *testing.T) {
+		code, stdout, _ := run("", append(cached, "list", "-o", "json")...)
+
+		var urls []model.URL
+		if err := json.Unmarshal([]byte(stdout), &urls); code != 0 || err != nil {
+			t.Fatalf("Expected a JSON list of urls, got '%s'", stdout)
+		}
+		if len(urls) != 1 || urls[0].Hash != "custom" {
+			t.Errorf("Expected url 'custom', got '%v'", urls)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		code, stdout, _ := run("", append(cached, "stats", "url-id")...)
+		if code != 0 || !strings.Contains(stdout, "clicks") || strings.Index(stdout, "NG") > strings.Index(stdout, "GH") {
+			t.Errorf("Expected clicks with countries by count, got '%s'", stdout)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		code, stdout, stderr := run("", append(cached, "delete", "url-id")...)
+		if code != 0 || !strings.Contains(stdout, "trash") {
+			t.Errorf("Expected exit code '0', got '%d' and '%s'", code, stderr)
+		}
+	})
+
+	t.Run("Missing_Argument", func(t *testing.T) {
+		if code, _, _ := run("", append(cached, "delete")...); code != 1 {
+			t.Errorf("Expected exit code '1', got '%d'", code)
+		}
+	})
+
+	t.Run("Unknown_Command", func(t *testing.T) {
+		if code, _, _ := run("", append(cached, "unknown")...); code != 2 {
+			t.Errorf("Expected exit code '2', got '%d'", code)
+		}
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		run("", append(cached, "logout")...)
+		if code, _, _ := run("", append(cached, "list")...); code != 1 {
+			t.Errorf("Expected exit code '1' after logging out, got '%d'", code)
+		}
+	})
+}
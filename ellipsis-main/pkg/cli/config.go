@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Config is cached between runs, in the file given by '-config', BRIEF_CONFIG or the user's
+// config directory
+type Config struct {
+	Server string `json:"server,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// configPath returns the path of the config file when no path is given
+func configPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path := os.Getenv("BRIEF_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "brief", "config.json"), nil
+}
+
+// loadConfig reads the config at 'path', a missing file is an empty config
+func loadConfig(path string) (*Config, error) {
+	config := new(Config)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+
+	return config, json.Unmarshal(b, config)
+}
+
+// save writes the config to 'path', readable by the user only since it holds a token
+func (c *Config) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
@@ -0,0 +1,192 @@
+// Package client calls the /api/v1 endpoints of a brief server.
+//
+// Every method takes a context, failed responses are returned as *Error which wraps one of the
+// sentinel errors of this package so they can be checked with errors.Is. Requests failing with
+// a 5xx response or a connection error are retried with exponential backoff.
+package client
+
+import (
+	"brief/utility"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	baseURL    string
+	auth       Auth
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+// Option configures a Client
+type Option func(c *Client)
+
+// WithAuth authenticates requests with 'auth'
+func WithAuth(auth Auth) Option {
+	return func(c *Client) { c.auth = auth }
+}
+
+// WithToken authenticates requests with a JWT token returned by Login or Register
+func WithToken(token string) Option {
+	return WithAuth(BearerToken(token))
+}
+
+// WithHTTPClient sends requests with 'httpClient' instead of a client with a 30 seconds timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithRetries retries failed requests up to 'retries' times, waiting 'backoff' before the first
+// retry and twice as long before each of the next ones. 0 retries disables retrying
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) { c.retries, c.backoff = retries, backoff }
+}
+
+// New returns a client of the server at 'baseURL', e.g. https://brief.up.railway.app. Requests
+// are retried twice by default, after 250ms then 500ms
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/") + "/api/v1",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retries:    2,
+		backoff:    250 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Auth authenticates the requests of a client
+type Auth interface {
+	Authenticate(req *http.Request)
+}
+
+// BearerToken authenticates requests with a JWT token in the Authorization header
+type BearerToken string
+
+func (t BearerToken) Authenticate(req *http.Request) {
+	if t != "" {
+		req.Header.Set("Authorization", "Bearer "+string(t))
+	}
+}
+
+// APIKey authenticates requests with a key in the X-API-Key header, for servers behind a
+// gateway checking API keys
+type APIKey string
+
+func (k APIKey) Authenticate(req *http.Request) {
+	if k != "" {
+		req.Header.Set("X-API-Key", string(k))
+	}
+}
+
+// do sends a request with 'body' encoded as JSON, and decodes the data of the response into 'data'
+func (c *Client) do(ctx context.Context, method, path string, body, data interface{}) error {
+	res, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	response := utility.Response{Data: data}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil && res.StatusCode < 400 {
+		return fmt.Errorf("could not decode response, got error: %w", err)
+	}
+	if res.StatusCode >= 400 {
+		return &Error{StatusCode: res.StatusCode, Response: response}
+	}
+
+	return nil
+}
+
+// raw sends a request and returns the body of a successful response as is
+func (c *Client) raw(ctx context.Context, method, path string) ([]byte, error) {
+	res, err := c.send(ctx, method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		var response utility.Response
+		json.NewDecoder(res.Body).Decode(&response)
+		return nil, &Error{StatusCode: res.StatusCode, Response: response}
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// send sends a request, retrying it on connection errors and 5xx responses. POST requests
+// are not idempotent and are only retried when the server was unavailable
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = b
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.auth != nil {
+			c.auth.Authenticate(req)
+		}
+
+		res, err := c.httpClient.Do(req)
+
+		retry := err != nil || res.StatusCode >= 500
+		if method == http.MethodPost {
+			retry = err == nil && res.StatusCode == http.StatusServiceUnavailable
+		}
+		if !retry || attempt >= c.retries {
+			return res, err
+		}
+
+		wait := c.wait(attempt, res)
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// wait returns how long to wait before retrying, the server's Retry-After header is honoured
+func (c *Client) wait(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	wait := c.backoff << attempt
+	if wait <= 0 {
+		return 0
+	}
+	// Jitter spreads retries of clients failing at the same time
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
@@ -0,0 +1,261 @@
+// build+ unit
+package client_test
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/client"
+	"brief/utility"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// server answers requests with 'rd' after checking them with 'check'
+func server(t *testing.T, method, path string, rd utility.Response, check func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method || r.URL.Path != path {
+			t.Errorf("Expected '%s %s', got '%s %s'", method, path, r.Method, r.URL.Path)
+		}
+		if check != nil {
+			check(r)
+		}
+
+		res, _ := json.Marshal(rd)
+		w.WriteHeader(rd.Code)
+		w.Write(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLogin(t *testing.T) {
+	rd := utility.BuildSuccessResponse(http.StatusOK, "logged in successfully",
+		model.LoginResponse{Token: "token", User: &model.User{Email: "john@doe.com"}})
+	srv := server(t, http.MethodPost, "/api/v1/users/login", rd, func(r *http.Request) {
+		var login model.UserLogin
+		json.NewDecoder(r.Body).Decode(&login)
+		if login.Email != "john@doe.com" || login.Password != "password" {
+			t.Errorf("Expected 'john@doe.com' and 'password', got '%s' and '%s'", login.Email, login.Password)
+		}
+	})
+
+	res, err := client.New(srv.URL).Login(context.Background(), "john@doe.com", "password")
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+	if res.Token != "token" || res.User.Email != "john@doe.com" {
+		t.Errorf("Expected token 'token' for 'john@doe.com', got '%s' for '%s'", res.Token, res.User.Email)
+	}
+}
+
+func TestShorten(t *testing.T) {
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "successfully created url",
+		model.URL{ID: "url-id", Hash: "https://brf.io/custom", LongURL: "https://example.com"})
+	srv := server(t, http.MethodPost, "/api/v1/url/shorten", rd, func(r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("Expected 'Bearer token', got '%s'", auth)
+		}
+
+		var url model.URL
+		json.NewDecoder(r.Body).Decode(&url)
+		if url.Hash != "custom" {
+			t.Errorf("Expected hash 'custom', got '%s'", url.Hash)
+		}
+	})
+
+	url, err := client.New(srv.URL+"/", client.WithToken("token")).
+		Shorten(context.Background(), &model.URL{LongURL: "https://example.com", Hash: "custom"})
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+	if url.ID != "url-id" || url.Hash != "https://brf.io/custom" {
+		t.Errorf("Expected 'url-id' shortened to 'https://brf.io/custom', got '%s' to '%s'", url.ID, url.Hash)
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	rd := utility.BuildSuccessResponse(http.StatusOK, "",
+		model.LinkStats{URLID: "url-id", Clicks: 2, Countries: map[string]int64{"NG": 2}})
+	srv := server(t, http.MethodGet, "/api/v1/url/url-id/stats", rd, nil)
+
+	stats, err := client.New(srv.URL).GetStats(context.Background(), "url-id")
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+	if stats.Clicks != 2 || stats.Countries["NG"] != 2 {
+		t.Errorf("Expected '2' clicks from 'NG', got '%d' and '%v'", stats.Clicks, stats.Countries)
+	}
+}
+
+func TestExportMe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write([]byte("PK"))
+	}))
+	defer srv.Close()
+
+	archive, err := client.New(srv.URL).ExportMe(context.Background())
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+	if string(archive) != "PK" {
+		t.Errorf("Expected 'PK', got '%s'", archive)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Auth   client.Auth
+		Header string
+		Value  string
+	}{
+		{"Bearer_Token", client.BearerToken("token"), "Authorization", "Bearer token"},
+		{"API_Key", client.APIKey("key"), "X-API-Key", "key"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			rd := utility.BuildSuccessResponse(http.StatusOK, "", model.User{})
+			srv := server(t, http.MethodGet, "/api/v1/users", rd, func(r *http.Request) {
+				if got := r.Header.Get(test.Header); got != test.Value {
+					t.Errorf("Expected '%s', got '%s'", test.Value, got)
+				}
+			})
+
+			if _, err := client.New(srv.URL, client.WithAuth(test.Auth)).GetMe(context.Background()); err != nil {
+				t.Errorf("Expected 'error' to be nil, got '%v'", err)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Response utility.Response
+		Expected error
+	}{
+		{"Request", utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
+			constant.ErrRequest, "oops, 'custom' already exists", nil), client.ErrRequest},
+		{"Validation", utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
+			constant.ErrValidation, map[string]string{"URL.LongURL": "LongURL is a required field"}, nil), client.ErrValidation},
+		{"Unauthorized", utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
+			constant.ErrUnauthorized, "expired token", nil), client.ErrUnauthorized},
+		{"Forbidden", utility.BuildErrorResponse(http.StatusForbidden, constant.StatusFailed,
+			constant.ErrUnauthorized, "missing permission 'url:read:any'", nil), client.ErrForbidden},
+		{"Not_Found", utility.ResponseMessage(http.StatusNotFound, "", "Not Found", "Page not found.",
+			nil, nil, nil, nil), client.ErrNotFound},
+		{"Conflict", utility.ResponseMessage(http.StatusConflict, constant.StatusFailed, apperror.CodeHashTaken,
+			constant.ErrRequest, "oops, 'custom' already exists", nil, nil, nil), client.ErrConflict},
+		{"Server", utility.BuildErrorResponse(http.StatusInternalServerError, constant.StatusFailed,
+			"", nil, nil), client.ErrServer},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			srv := server(t, http.MethodDelete, "/api/v1/url/url-id", test.Response, nil)
+
+			_, err := client.New(srv.URL, client.WithRetries(0, 0)).DeleteUrl(context.Background(), "url-id")
+			if !errors.Is(err, test.Expected) {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, err)
+			}
+
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != test.Response.Code {
+				t.Errorf("Expected status '%d', got '%v'", test.Response.Code, err)
+			}
+			if apiErr != nil && apiErr.Code() != test.Response.Name {
+				t.Errorf("Expected '%s', got '%s'", test.Response.Name, apiErr.Code())
+			}
+		})
+	}
+
+	t.Run("Validation_Fields", func(t *testing.T) {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
+			constant.ErrValidation, map[string]string{"URL.LongURL": "LongURL is a required field"}, nil)
+		srv := server(t, http.MethodPost, "/api/v1/url/shorten", rd, nil)
+
+		_, err := client.New(srv.URL).Shorten(context.Background(), &model.URL{})
+
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.ValidationErrors()["URL.LongURL"] != "LongURL is a required field" {
+			t.Errorf("Expected a message for 'URL.LongURL', got '%v'", err)
+		}
+		if expected := "validation error: LongURL is a required field"; err.Error() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+		}
+	})
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Method   string
+		Status   int
+		Failures int
+		Attempts int
+		ErrIsNil bool
+	}{
+		{"Recovers", http.MethodGet, http.StatusInternalServerError, 2, 3, true},
+		{"Gives_Up", http.MethodGet, http.StatusBadGateway, 5, 3, false},
+		{"Client_Error", http.MethodGet, http.StatusBadRequest, 5, 1, false},
+		{"Post_Server_Error", http.MethodPost, http.StatusInternalServerError, 5, 1, false},
+		{"Post_Unavailable", http.MethodPost, http.StatusServiceUnavailable, 1, 2, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			attempts := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				rd := utility.BuildSuccessResponse(http.StatusOK, "", nil)
+				if attempts <= test.Failures {
+					rd = utility.BuildErrorResponse(test.Status, constant.StatusFailed, constant.ErrServer, nil, nil)
+				}
+				res, _ := json.Marshal(rd)
+				w.WriteHeader(rd.Code)
+				w.Write(res)
+			}))
+			defer srv.Close()
+
+			c := client.New(srv.URL, client.WithRetries(2, time.Millisecond))
+
+			var err error
+			if test.Method == http.MethodPost {
+				_, err = c.Shorten(context.Background(), &model.URL{})
+			} else {
+				_, err = c.GetUrls(context.Background())
+			}
+
+			if (err == nil) != test.ErrIsNil {
+				t.Errorf("Expected 'error' to be nil: '%v', got '%v'", test.ErrIsNil, err)
+			}
+			if attempts != test.Attempts {
+				t.Errorf("Expected '%d' attempts, got '%d'", test.Attempts, attempts)
+			}
+		})
+	}
+
+	t.Run("Cancelled", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.New(srv.URL, client.WithRetries(5, time.Hour)).GetUrls(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected '%v', got '%v'", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"brief/internal/constant"
+	"brief/utility"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Failed responses wrap one of these errors, check them with errors.Is
+var (
+	ErrValidation   = errors.New(constant.ErrValidation)
+	ErrBinding      = errors.New(constant.ErrBinding)
+	ErrUnauthorized = errors.New(constant.ErrUnauthorized)
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRequest      = errors.New(constant.ErrRequest)
+	ErrServer       = errors.New(constant.ErrServer)
+)
+
+var errorsByName = map[string]error{
+	constant.ErrValidation:   ErrValidation,
+	constant.ErrBinding:      ErrBinding,
+	constant.ErrUnauthorized: ErrUnauthorized,
+	constant.ErrRequest:      ErrRequest,
+	constant.ErrServer:       ErrServer,
+	"Not Found":              ErrNotFound,
+}
+
+// Error is returned when the server answers with a failed response
+type Error struct {
+	StatusCode int
+	Response   utility.Response
+}
+
+func (e *Error) Error() string {
+	if e.Response.Error != nil {
+		if fields := e.ValidationErrors(); fields != nil {
+			messages := make([]string, 0, len(fields))
+			for _, message := range fields {
+				messages = append(messages, message)
+			}
+			sort.Strings(messages)
+			return fmt.Sprintf("%s: %s", e.Response.Message, strings.Join(messages, ", "))
+		}
+		return fmt.Sprintf("%s: %v", e.Response.Message, e.Response.Error)
+	}
+	if e.Response.Message != "" {
+		return e.Response.Message
+	}
+	return http.StatusText(e.StatusCode)
+}
+
+// Unwrap maps the response to a sentinel error, from its status then its name or message
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	}
+
+	if err, ok := errorsByName[e.Response.Name]; ok {
+		return err
+	}
+	if err, ok := errorsByName[e.Response.Message]; ok {
+		return err
+	}
+	if e.StatusCode >= 500 {
+		return ErrServer
+	}
+	return nil
+}
+
+// Code returns the stable code of the error, such as 'hash_taken'
+func (e *Error) Code() string {
+	return e.Response.Name
+}
+
+// ValidationErrors returns the message of each invalid field of a validation error, keyed
+// by field, or nil for other errors
+func (e *Error) ValidationErrors() map[string]string {
+	fields, ok := e.Response.Error.(map[string]interface{})
+	if !ok || e.Response.Message != constant.ErrValidation {
+		return nil
+	}
+
+	messages := make(map[string]string, len(fields))
+	for field, message := range fields {
+		messages[field] = fmt.Sprint(message)
+	}
+	return messages
+}
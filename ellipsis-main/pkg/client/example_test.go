@@ -0,0 +1,71 @@
+// build+ unit
+package client_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/client"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+func Example() {
+	ctx := context.Background()
+
+	// Log in once, then authenticate every request with the token
+	res, err := client.New("https://brief.up.railway.app").Login(ctx, "john@doe.com", "password")
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := client.New("https://brief.up.railway.app", client.WithToken(res.Token))
+
+	url, err := c.Shorten(ctx, &model.URL{LongURL: "https://example.com", Hash: "example"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(url.Hash)
+}
+
+func ExampleWithAuth() {
+	// Servers behind a gateway checking API keys
+	c := client.New("https://brief.up.railway.app",
+		client.WithAuth(client.APIKey("my-api-key")),
+		client.WithRetries(4, 500*time.Millisecond),
+	)
+
+	urls, err := c.GetUrls(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(len(urls))
+}
+
+func ExampleError() {
+	c := client.New("https://brief.up.railway.app", client.WithToken("token"))
+
+	_, err := c.Shorten(context.Background(), &model.URL{LongURL: "https://example.com", Hash: "taken"})
+
+	var apiErr *client.Error
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		fmt.Println("log in again")
+	case errors.Is(err, client.ErrValidation) && errors.As(err, &apiErr):
+		for field, message := range apiErr.ValidationErrors() {
+			fmt.Println(field, message)
+		}
+	case errors.Is(err, client.ErrRequest):
+		fmt.Println("could not shorten:", err)
+	}
+}
+
+func ExampleClient_DeleteMe() {
+	c := client.New("https://brief.up.railway.app", client.WithToken("token"))
+
+	// Keep the links live under an anonymized account
+	if err := c.DeleteMe(context.Background(), "password", constant.LinksKeep); err != nil {
+		log.Fatal(err)
+	}
+}
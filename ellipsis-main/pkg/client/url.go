@@ -0,0 +1,107 @@
+package client
+
+import (
+	"brief/internal/model"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Shorten creates a short url, a custom hash can be set on 'u'. The returned url's Hash is the
+// full short link
+func (c *Client) Shorten(ctx context.Context, u *model.URL) (*model.URL, error) {
+	res := new(model.URL)
+	err := c.do(ctx, http.MethodPost, "/url/shorten", u, res)
+	return res, err
+}
+
+// GetUrls fetches the urls of the authenticated user
+func (c *Client) GetUrls(ctx context.Context) ([]model.URL, error) {
+	var res []model.URL
+	err := c.do(ctx, http.MethodGet, "/url", nil, &res)
+	return res, err
+}
+
+// GetWorkspaceUrls fetches the urls of a workspace the authenticated user is a member of
+func (c *Client) GetWorkspaceUrls(ctx context.Context, workspaceID string) ([]model.URL, error) {
+	var res []model.URL
+	err := c.do(ctx, http.MethodGet, "/url?workspace_id="+url.QueryEscape(workspaceID), nil, &res)
+	return res, err
+}
+
+// DeleteUrl moves a url to the trash
+func (c *Client) DeleteUrl(ctx context.Context, id string) (*model.URL, error) {
+	res := new(model.URL)
+	err := c.do(ctx, http.MethodDelete, "/url/"+url.PathEscape(id), nil, res)
+	return res, err
+}
+
+// GetUrlTrash fetches the deleted urls of the authenticated user that can still be restored
+func (c *Client) GetUrlTrash(ctx context.Context) ([]model.URL, error) {
+	var res []model.URL
+	err := c.do(ctx, http.MethodGet, "/url/trash", nil, &res)
+	return res, err
+}
+
+// RestoreUrl restores a deleted url
+func (c *Client) RestoreUrl(ctx context.Context, id string) (*model.URL, error) {
+	res := new(model.URL)
+	err := c.do(ctx, http.MethodPatch, "/url/"+url.PathEscape(id)+"/restore", nil, res)
+	return res, err
+}
+
+// MoveToWorkspace moves a url into a workspace, an empty 'workspaceID' gives it back to its creator
+func (c *Client) MoveToWorkspace(ctx context.Context, id, workspaceID string) (*model.URL, error) {
+	res := new(model.URL)
+	err := c.do(ctx, http.MethodPatch, "/url/"+url.PathEscape(id)+"/workspace", &model.URLWorkspace{WorkspaceID: workspaceID}, res)
+	return res, err
+}
+
+// GetRules fetches the redirect rules of a url
+func (c *Client) GetRules(ctx context.Context, id string) ([]model.RedirectRule, error) {
+	var res []model.RedirectRule
+	err := c.do(ctx, http.MethodGet, "/url/"+url.PathEscape(id)+"/rules", nil, &res)
+	return res, err
+}
+
+// SetRules replaces the redirect rules of a url, they are evaluated in order
+func (c *Client) SetRules(ctx context.Context, id string, rules []model.RedirectRule) ([]model.RedirectRule, error) {
+	var res []model.RedirectRule
+	err := c.do(ctx, http.MethodPut, "/url/"+url.PathEscape(id)+"/rules", &model.RuleSet{Rules: rules}, &res)
+	return res, err
+}
+
+// GetVariants fetches the split test variants of a url with their click counts
+func (c *Client) GetVariants(ctx context.Context, id string) ([]model.VariantStats, error) {
+	var res []model.VariantStats
+	err := c.do(ctx, http.MethodGet, "/url/"+url.PathEscape(id)+"/variants", nil, &res)
+	return res, err
+}
+
+// SetVariants replaces the split test variants of a url
+func (c *Client) SetVariants(ctx context.Context, id string, variants []model.Variant) ([]model.Variant, error) {
+	var res []model.Variant
+	err := c.do(ctx, http.MethodPut, "/url/"+url.PathEscape(id)+"/variants", &model.VariantSet{Variants: variants}, &res)
+	return res, err
+}
+
+// GetStats fetches the click stats of a url
+func (c *Client) GetStats(ctx context.Context, id string) (*model.LinkStats, error) {
+	res := new(model.LinkStats)
+	err := c.do(ctx, http.MethodGet, "/url/"+url.PathEscape(id)+"/stats", nil, res)
+	return res, err
+}
+
+// GetAllUrls fetches every url
+func (c *Client) GetAllUrls(ctx context.Context) ([]model.URL, error) {
+	var res []model.URL
+	err := c.do(ctx, http.MethodGet, "/url/get-all", nil, &res)
+	return res, err
+}
+
+// GetUserUrls fetches the urls created by a user with 'userID'
+func (c *Client) GetUserUrls(ctx context.Context, userID string) ([]model.URL, error) {
+	var res []model.URL
+	err := c.do(ctx, http.MethodGet, "/url/"+url.PathEscape(userID), nil, &res)
+	return res, err
+}
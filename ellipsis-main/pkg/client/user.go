@@ -0,0 +1,117 @@
+package client
+
+import (
+	"brief/internal/model"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Register creates an account and returns it along with its token
+func (c *Client) Register(ctx context.Context, user *model.User) (*model.LoginResponse, error) {
+	res := new(model.LoginResponse)
+	err := c.do(ctx, http.MethodPost, "/users", user, res)
+	return res, err
+}
+
+// Login exchanges an email and password for a token, use it with WithToken
+func (c *Client) Login(ctx context.Context, email, password string) (*model.LoginResponse, error) {
+	res := new(model.LoginResponse)
+	err := c.do(ctx, http.MethodPost, "/users/login", &model.UserLogin{Email: email, Password: password}, res)
+	return res, err
+}
+
+// GetMe fetches the authenticated user
+func (c *Client) GetMe(ctx context.Context) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodGet, "/users", nil, res)
+	return res, err
+}
+
+// UpdateMe updates the profile of the authenticated user
+func (c *Client) UpdateMe(ctx context.Context, user *model.User) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodPatch, "/users", user, res)
+	return res, err
+}
+
+// ResetPassword changes the password of the authenticated user
+func (c *Client) ResetPassword(ctx context.Context, password string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodPatch, "/users/reset-password", &model.ResetPassword{Password: password}, res)
+	return res, err
+}
+
+// DeleteMe deletes the account of the authenticated user, 'links' is one of
+// constant.LinksDelete, constant.LinksTransfer or constant.LinksKeep
+func (c *Client) DeleteMe(ctx context.Context, password, links string) error {
+	return c.do(ctx, http.MethodDelete, "/users", &model.DeleteAccount{Password: password, Links: links}, nil)
+}
+
+// ExportMe downloads the data of the authenticated user as a ZIP archive
+func (c *Client) ExportMe(ctx context.Context) ([]byte, error) {
+	return c.raw(ctx, http.MethodGet, "/users/export")
+}
+
+// GetUsers fetches every user
+func (c *Client) GetUsers(ctx context.Context) ([]model.User, error) {
+	var res []model.User
+	err := c.do(ctx, http.MethodGet, "/users/get-all", nil, &res)
+	return res, err
+}
+
+// GetUser fetches a user by id or email
+func (c *Client) GetUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(idOrEmail), nil, res)
+	return res, err
+}
+
+// LockUser locks the account of a user
+func (c *Client) LockUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodPatch, "/users/lock/"+url.PathEscape(idOrEmail), nil, res)
+	return res, err
+}
+
+// UnlockUser unlocks the account of a user
+func (c *Client) UnlockUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodPatch, "/users/unlock/"+url.PathEscape(idOrEmail), nil, res)
+	return res, err
+}
+
+// AssignRole changes the role of a user, it takes effect on the user's next request
+func (c *Client) AssignRole(ctx context.Context, idOrEmail, role string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodPatch, "/users/"+url.PathEscape(idOrEmail)+"/role", &model.RoleAssignment{Role: role}, res)
+	return res, err
+}
+
+// GetRoleChanges fetches the history of the roles of a user
+func (c *Client) GetRoleChanges(ctx context.Context, idOrEmail string) ([]model.RoleChange, error) {
+	var res []model.RoleChange
+	err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(idOrEmail)+"/role-changes", nil, &res)
+	return res, err
+}
+
+// GetUserTrash fetches the deleted users that can still be restored
+func (c *Client) GetUserTrash(ctx context.Context) ([]model.User, error) {
+	var res []model.User
+	err := c.do(ctx, http.MethodGet, "/users/trash", nil, &res)
+	return res, err
+}
+
+// DeleteUser moves a user and their urls to the trash
+func (c *Client) DeleteUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(idOrEmail), nil, res)
+	return res, err
+}
+
+// RestoreUser restores a deleted user and the urls deleted with them
+func (c *Client) RestoreUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	res := new(model.User)
+	err := c.do(ctx, http.MethodPatch, "/users/"+url.PathEscape(idOrEmail)+"/restore", nil, res)
+	return res, err
+}
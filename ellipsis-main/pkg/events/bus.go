@@ -0,0 +1,144 @@
+package events
+
+import (
+	"brief/internal/config"
+	"brief/pkg/logging"
+	"brief/pkg/metrics"
+	"brief/pkg/repository/storage"
+	"brief/pkg/tracing"
+	"brief/utility"
+	"context"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Handler reacts to an event. Its error is logged and counted, it fails neither the action that
+// published the event nor the other subscribers
+type Handler func(ctx context.Context, event Event) error
+
+// Mode tells when a subscriber is run
+type Mode int
+
+const (
+	Sync  Mode = iota // in the publisher's goroutine, before Publish returns
+	Async             // in a goroutine of its own, Publish does not wait for it
+)
+
+type Bus interface {
+	// Subscribe runs 'handler', called 'subscriber' in logs and metrics, on every event called 'name'
+	Subscribe(name, subscriber string, mode Mode, handler Handler)
+	// Publish dispatches 'event' to its subscribers
+	Publish(ctx context.Context, event Event)
+	// Drain waits for the async subscribers that are running, or for 'ctx' to be done
+	Drain(ctx context.Context) error
+}
+
+var bus Bus = NewBus()
+
+// Setup chooses the bus services publish on. Events are dispatched in memory unless 'EVENT_OUTBOX'
+// is set, then they are stored in postgres with 'dbRepo' and relayed to subscribers at least once
+func Setup(logger *log.Logger, dbRepo storage.StorageRepository) {
+	getConfig := config.GetConfig()
+	if !getConfig.EventOutbox {
+		bus = NewBus()
+		return
+	}
+
+	bus = NewOutbox(dbRepo, getConfig.EventOutboxMaxAttempts)
+	logger.Info("EVENT OUTBOX ENABLED")
+}
+
+// GetBus returns the configured bus
+func GetBus() Bus {
+	return bus
+}
+
+type subscription struct {
+	subscriber string
+	mode       Mode
+	handler    Handler
+}
+
+// local dispatches events in memory, they are lost if the process stops before async
+// subscribers handled them
+type local struct {
+	mu            sync.RWMutex
+	subscriptions map[string][]subscription
+	running       sync.WaitGroup
+}
+
+// NewBus returns a bus dispatching events in memory
+func NewBus() Bus {
+	return newLocal()
+}
+
+func newLocal() *local {
+	return &local{subscriptions: map[string][]subscription{}}
+}
+
+func (b *local) Subscribe(name, subscriber string, mode Mode, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[name] = append(b.subscriptions[name], subscription{subscriber, mode, handler})
+}
+
+// Publish runs the sync subscribers of 'event' in the order they subscribed, then returns while
+// the async ones keep running with the values of 'ctx' but not its cancellation
+func (b *local) Publish(ctx context.Context, event Event) {
+	for _, s := range b.subscribers(event.Name()) {
+		if s.mode == Async {
+			b.running.Add(1)
+			go func(s subscription) {
+				defer b.running.Done()
+				handle(utility.Detach(ctx), event, s)
+			}(s)
+			continue
+		}
+		handle(ctx, event, s)
+	}
+}
+
+func (b *local) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("async subscribers are still running: %w", ctx.Err())
+	}
+}
+
+// subscribers returns a copy of the subscriptions to the event called 'name'
+func (b *local) subscribers(name string) []subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]subscription(nil), b.subscriptions[name]...)
+}
+
+// handle runs one subscriber on 'event'. A panic is recovered as an error, so that a faulty
+// subscriber cannot take the publisher or the other subscribers down
+func handle(ctx context.Context, event Event, s subscription) (err error) {
+	ctx, span := tracing.Start(ctx, "events.Handle",
+		attribute.String("event.name", event.Name()), attribute.String("event.subscriber", s.subscriber))
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panicked: %v", r)
+		}
+		if err != nil {
+			metrics.EventFailures.WithLabelValues(event.Name(), s.subscriber).Inc()
+			logging.FromContext(ctx).Errorf("subscriber '%s' could not handle '%s' event, got error: %s",
+				s.subscriber, event.Name(), err)
+		}
+		tracing.End(span, err)
+	}()
+
+	return s.handler(ctx, event)
+}
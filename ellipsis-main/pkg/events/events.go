@@ -0,0 +1,81 @@
+package events
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"encoding/json"
+	"fmt"
+)
+
+// Event is something that happened in the domain, it is published once the change it describes
+// is stored. Events are values so that async subscribers cannot see them change
+type Event interface {
+	Name() string
+}
+
+// URLCreated is published when a url is shortened, its hash is the full short url
+type URLCreated struct {
+	URL model.URL `json:"url"`
+}
+
+// URLDeleted is published when a url is moved to the trash
+type URLDeleted struct {
+	URL model.URL `json:"url"`
+}
+
+// Redirected is published when a visitor is redirected by a url, with the click recorded for it
+type Redirected struct {
+	URL   model.URL   `json:"url"`
+	Click model.Click `json:"click"`
+}
+
+// URLBroken is published when the destination of a url starts failing the link checker's checks,
+// with the check that made it broken
+type URLBroken struct {
+	URL   model.URL       `json:"url"`
+	Check model.LinkCheck `json:"check"`
+}
+
+// UserRegistered is published when a user signs up, without their password
+type UserRegistered struct {
+	User model.User `json:"user"`
+}
+
+// UserLocked is published when a user's account is locked, without their password
+type UserLocked struct {
+	User model.User `json:"user"`
+}
+
+func (URLCreated) Name() string     { return constant.DomainURLCreated }
+func (URLDeleted) Name() string     { return constant.DomainURLDeleted }
+func (Redirected) Name() string     { return constant.DomainRedirected }
+func (URLBroken) Name() string      { return constant.DomainURLBroken }
+func (UserRegistered) Name() string { return constant.DomainUserRegistered }
+func (UserLocked) Name() string     { return constant.DomainUserLocked }
+
+// Decode returns the event called 'name' from its JSON 'payload', as stored in the outbox
+func Decode(name string, payload []byte) (Event, error) {
+	switch name {
+	case constant.DomainURLCreated:
+		return decode[URLCreated](payload)
+	case constant.DomainURLDeleted:
+		return decode[URLDeleted](payload)
+	case constant.DomainRedirected:
+		return decode[Redirected](payload)
+	case constant.DomainURLBroken:
+		return decode[URLBroken](payload)
+	case constant.DomainUserRegistered:
+		return decode[UserRegistered](payload)
+	case constant.DomainUserLocked:
+		return decode[UserLocked](payload)
+	}
+	return nil, fmt.Errorf("unknown event '%s'", name)
+}
+
+func decode[E Event](payload []byte) (Event, error) {
+	var event E
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("could not decode '%s' event, got error: %w", event.Name(), err)
+	}
+	return event, nil
+}
@@ -0,0 +1,282 @@
+// build+ unit
+package events_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/pkg/repository/storage"
+	"brief/service/mock"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// outbox keeps stored events in memory, a transaction only keeps its events if it succeeds
+type outbox struct {
+	mock.Repo
+	mu     sync.Mutex
+	events []model.OutboxEvent
+}
+
+func (o *outbox) CreateOutboxEvents(ctx context.Context, events []model.OutboxEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func (o *outbox) ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []model.OutboxEvent
+	for i, e := range o.events {
+		if e.Status == constant.OutboxPending && !e.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, e)
+			o.events[i].NextAttemptAt = now.Add(lease)
+		}
+	}
+	return due, nil
+}
+
+func (o *outbox) UpdateOutboxEvent(ctx context.Context, event *model.OutboxEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.events {
+		if o.events[i].ID == event.ID {
+			o.events[i] = *event
+		}
+	}
+	return nil
+}
+
+func (o *outbox) Transaction(ctx context.Context, fn func(tx storage.StorageRepository) error) error {
+	tx := &outbox{}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return o.CreateOutboxEvents(ctx, tx.events)
+}
+
+// recorder records the subscribers that handled an event, in order
+type recorder struct {
+	mu      sync.Mutex
+	handled []string
+}
+
+func (r *recorder) handler(subscriber string, err error) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		r.mu.Lock()
+		r.handled = append(r.handled, subscriber)
+		r.mu.Unlock()
+		return err
+	}
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.handled...)
+}
+
+func TestPublish(t *testing.T) {
+	bus := events.NewBus()
+	rec := &recorder{}
+	release := make(chan struct{})
+
+	bus.Subscribe(constant.DomainURLCreated, "first", events.Sync, rec.handler("first", nil))
+	bus.Subscribe(constant.DomainURLCreated, "failing", events.Sync, rec.handler("failing", errors.New("unavailable")))
+	bus.Subscribe(constant.DomainURLCreated, "panicking", events.Sync, func(ctx context.Context, event events.Event) error {
+		panic("nil map")
+	})
+	bus.Subscribe(constant.DomainURLCreated, "async", events.Async, func(ctx context.Context, event events.Event) error {
+		<-release
+		return rec.handler("async", nil)(ctx, event)
+	})
+	bus.Subscribe(constant.DomainURLCreated, "last", events.Sync, rec.handler("last", nil))
+	bus.Subscribe(constant.DomainURLDeleted, "other", events.Sync, rec.handler("other", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.Publish(ctx, events.URLCreated{URL: model.URL{ID: "url-id"}})
+	cancel()
+
+	t.Run("Sync", func(t *testing.T) {
+		got := rec.get()
+		if len(got) != 3 || got[0] != "first" || got[1] != "failing" || got[2] != "last" {
+			t.Errorf("Expected '[first failing last]', got '%v'", got)
+		}
+	})
+
+	t.Run("Drain_Timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := bus.Drain(ctx); err == nil {
+			t.Errorf("Expected 'error' to be not nil while a subscriber is running")
+		}
+	})
+
+	t.Run("Async", func(t *testing.T) {
+		close(release)
+		if err := bus.Drain(context.Background()); err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		if got := rec.get(); len(got) != 4 || got[3] != "async" {
+			t.Errorf("Expected 'async' to handle the event once the publisher's context was cancelled, got '%v'", got)
+		}
+	})
+}
+
+func TestOutbox(t *testing.T) {
+	repo := &outbox{}
+	bus := events.NewOutbox(repo, 3)
+	rec := &recorder{}
+	fail := errors.New("unavailable")
+
+	bus.Subscribe(constant.DomainUserLocked, "sync", events.Sync, rec.handler("sync", nil))
+	bus.Subscribe(constant.DomainUserLocked, "async", events.Async, rec.handler("async", nil))
+	bus.Subscribe(constant.DomainUserLocked, "flaky", events.Sync, func(ctx context.Context, event events.Event) error {
+		if e := event.(events.UserLocked); e.User.ID != "test-id" {
+			t.Errorf("Expected 'test-id', got '%s'", e.User.ID)
+		}
+		if fail != nil {
+			return fail
+		}
+		return rec.handler("flaky", nil)(ctx, event)
+	})
+
+	bus.Publish(context.Background(), events.UserLocked{User: model.User{ID: "test-id"}})
+	if got := rec.get(); len(got) != 0 {
+		t.Fatalf("Expected subscribers to wait for the relay, got '%v'", got)
+	}
+
+	now := time.Now()
+	backoff := 10 * time.Second // before the second attempt
+	t.Run("Partial_Failure", func(t *testing.T) {
+		if n, err := bus.Relay(context.Background(), now); n != 1 || err != nil {
+			t.Fatalf("Expected '1' event, got '%d' and '%v'", n, err)
+		}
+
+		stored := repo.events[0]
+		if stored.Status != constant.OutboxPending || stored.Attempts != 1 || len(stored.Completed) != 2 {
+			t.Errorf("Expected a pending event handled by 2 subscribers, got '%+v'", stored)
+		}
+		if !stored.NextAttemptAt.Equal(now.Add(backoff)) {
+			t.Errorf("Expected next attempt in '%s', got '%s'", backoff, stored.NextAttemptAt.Sub(now))
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		if n, _ := bus.Relay(context.Background(), now); n != 0 {
+			t.Errorf("Expected '0' events before the backoff, got '%d'", n)
+		}
+
+		fail = nil
+		bus.Relay(context.Background(), now.Add(backoff))
+
+		stored := repo.events[0]
+		if stored.Status != constant.OutboxProcessed || stored.ProcessedAt == nil || stored.Error != "" {
+			t.Errorf("Expected a processed event, got '%+v'", stored)
+		}
+		if got := rec.get(); len(got) != 3 || got[2] != "flaky" {
+			t.Errorf("Expected only 'flaky' to be retried, got '%v'", got)
+		}
+	})
+
+	t.Run("Out_Of_Attempts", func(t *testing.T) {
+		fail = errors.New("unavailable")
+		bus.Publish(context.Background(), events.UserLocked{User: model.User{ID: "test-id"}})
+		for i := 0; i < 3; i++ {
+			bus.Relay(context.Background(), now.Add(24*time.Hour*time.Duration(i+1)))
+		}
+
+		stored := repo.events[1]
+		if stored.Status != constant.OutboxFailed || stored.Attempts != 3 || stored.Error != "flaky: unavailable" {
+			t.Errorf("Expected a failed event, got '%+v'", stored)
+		}
+	})
+
+	t.Run("Unknown_Event", func(t *testing.T) {
+		repo.CreateOutboxEvents(context.Background(), []model.OutboxEvent{
+			{ID: "event-id", Name: "url.renamed", Payload: []byte("{}"), Status: constant.OutboxPending}})
+		bus.Relay(context.Background(), now)
+
+		if stored := repo.events[2]; stored.Status != constant.OutboxFailed || stored.Attempts != 1 {
+			t.Errorf("Expected a failed event, got '%+v'", stored)
+		}
+	})
+}
+
+func TestTransact(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Outbox   bool
+		Err      error
+		Expected int // events stored in the outbox or published on the bus
+	}{
+		{"Outbox_Committed", true, nil, 2},
+		{"Outbox_Rolled_Back", true, errors.New("could not delete url"), 0},
+		{"Bus_Committed", false, nil, 2},
+		{"Bus_Rolled_Back", false, errors.New("could not delete url"), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			repo := &outbox{}
+			var bus events.Bus = events.NewBus()
+			if test.Outbox {
+				bus = events.NewOutbox(repo, 0)
+			}
+			rec := &recorder{}
+			bus.Subscribe(constant.DomainURLDeleted, "audit", events.Sync, rec.handler("audit", nil))
+			bus.Subscribe(constant.DomainURLCreated, "audit", events.Sync, rec.handler("audit", nil))
+
+			err := events.Transact(context.Background(), bus, repo, func(tx storage.StorageRepository) ([]events.Event, error) {
+				return []events.Event{events.URLDeleted{URL: model.URL{ID: "url-id"}}, events.URLCreated{URL: model.URL{ID: "url-id"}}}, test.Err
+			})
+			if err != test.Err {
+				t.Fatalf("Expected '%v', got '%v'", test.Err, err)
+			}
+
+			got := len(rec.get())
+			if test.Outbox {
+				got = len(repo.events)
+				if len(rec.get()) != 0 {
+					t.Errorf("Expected the outbox to leave events to its relay, got '%v'", rec.get())
+				}
+			}
+			if got != test.Expected {
+				t.Errorf("Expected '%d' events, got '%d'", test.Expected, got)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Event   string
+		Payload string
+		IsError bool
+	}{
+		{"URL_Created", constant.DomainURLCreated, `{"url":{"id":"url-id"}}`, false},
+		{"Redirected", constant.DomainRedirected, `{"url":{"id":"url-id"},"click":{"id":"click-id"}}`, false},
+		{"URL_Broken", constant.DomainURLBroken, `{"url":{"id":"url-id"},"check":{"status_code":404}}`, false},
+		{"User_Registered", constant.DomainUserRegistered, `{"user":{"id":"test-id"}}`, false},
+		{"Malformed", constant.DomainURLDeleted, `{"url":[]}`, true},
+		{"Unknown", "url.renamed", `{}`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			event, err := events.Decode(test.Event, []byte(test.Payload))
+			if (err != nil) != test.IsError {
+				t.Fatalf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+			if err == nil && event.Name() != test.Event {
+				t.Errorf("Expected '%s', got '%s'", test.Event, event.Name())
+			}
+		})
+	}
+}
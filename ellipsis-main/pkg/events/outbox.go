@@ -0,0 +1,190 @@
+package events
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/logging"
+	"brief/pkg/repository/storage"
+	"brief/pkg/retry"
+	"brief/utility"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	defaultMaxAttempts = 10
+	batchSize          = 50
+	lease              = 5 * time.Minute
+	baseBackoff        = 10 * time.Second
+	maxBackoff         = time.Hour
+)
+
+// Outbox is a bus storing events in postgres when they are published. Its relay then runs every
+// subscriber, sync or async, until each handled the event once, retrying the failed ones with a
+// backoff. A subscriber can see an event again if an instance stops before storing the outcome of
+// an attempt, so subscribers must be idempotent, and their names unique per event
+type Outbox struct {
+	*local
+	dbRepo      storage.StorageRepository
+	maxAttempts int
+}
+
+// NewOutbox returns an outbox failing an event after 'maxAttempts' attempts, 10 when it is not positive
+func NewOutbox(dbRepo storage.StorageRepository, maxAttempts int) *Outbox {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &Outbox{local: newLocal(), dbRepo: dbRepo, maxAttempts: maxAttempts}
+}
+
+// Publish stores 'event' to be relayed, even if 'ctx' has been cancelled since the action completed
+func (o *Outbox) Publish(ctx context.Context, event Event) {
+	if err := o.Enqueue(utility.Detach(ctx), o.dbRepo, event); err != nil {
+		logging.FromContext(ctx).Errorf("could not store '%s' event, got error: %s", event.Name(), err)
+	}
+}
+
+// Enqueue stores 'events' to be relayed with 'dbRepo'. Given the repository of a transaction, the
+// events are only stored if the change they describe is committed with them
+func (o *Outbox) Enqueue(ctx context.Context, dbRepo storage.StorageRepository, events ...Event) error {
+	now := time.Now()
+	stored := make([]model.OutboxEvent, len(events))
+	for i, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("could not encode '%s' event, got error: %w", event.Name(), err)
+		}
+
+		stored[i] = model.OutboxEvent{
+			ID:            uuid.NewString(),
+			Name:          event.Name(),
+			Payload:       payload,
+			Status:        constant.OutboxPending,
+			NextAttemptAt: now,
+			Completed:     []string{},
+			CreatedAt:     now,
+		}
+	}
+
+	return dbRepo.CreateOutboxEvents(ctx, stored)
+}
+
+// Transact runs 'fn' in a transaction of 'dbRepo' and publishes the events it returns on 'bus'
+// once the transaction is committed. The outbox stores them in the transaction itself, so that
+// an event is relayed if and only if the change it describes is stored
+func Transact(ctx context.Context, bus Bus, dbRepo storage.StorageRepository, fn func(tx storage.StorageRepository) ([]Event, error)) error {
+	outbox, isOutbox := bus.(*Outbox)
+
+	var committed []Event
+	err := dbRepo.Transaction(ctx, func(tx storage.StorageRepository) error {
+		events, err := fn(tx)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		if isOutbox {
+			return outbox.Enqueue(ctx, tx, events...)
+		}
+		committed = events
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range committed {
+		bus.Publish(ctx, event)
+	}
+	return nil
+}
+
+// Relay runs the subscribers of a batch of events due at 'now', oldest first, and returns how many
+// events were relayed. Claimed events are leased, an instance that stops while relaying them leaves
+// them to be retried once the lease is over
+func (o *Outbox) Relay(ctx context.Context, now time.Time) (int, error) {
+	events, err := o.dbRepo.ClaimOutboxEvents(ctx, now, lease, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("could not claim events, got error: %w", err)
+	}
+
+	for i := range events {
+		o.relay(ctx, &events[i], now)
+	}
+	return len(events), nil
+}
+
+// Run relays stored events every 'interval' until 'ctx' is done, full batches are followed by the
+// next one right away
+func (o *Outbox) Run(ctx context.Context, logger *log.Logger, interval time.Duration) {
+	retry.Poll(ctx, interval, batchSize, o.Relay, func(err error) {
+		logger.Errorf("event relay failed: %s", err)
+	})
+}
+
+// policy returns how the relay retries events, with a backoff doubling from 10 seconds up to an hour
+func (o *Outbox) policy() retry.Policy {
+	return retry.Policy{Base: baseBackoff, Max: maxBackoff, MaxAttempts: o.maxAttempts}
+}
+
+// relay runs the subscribers that have not handled 'stored' yet and stores the outcome
+func (o *Outbox) relay(ctx context.Context, stored *model.OutboxEvent, now time.Time) {
+	stored.Attempts++
+	event, err := Decode(stored.Name, stored.Payload)
+	if err != nil {
+		// Events that cannot be decoded will not be on a later attempt either
+		err = retry.Permanent(err)
+	} else {
+		err = o.dispatch(ctx, event, stored)
+	}
+
+	outcome, retryAt := o.policy().Next(stored.Attempts, err, now)
+	switch outcome {
+	case retry.Succeeded:
+		stored.Status = constant.OutboxProcessed
+		stored.Error = ""
+		stored.ProcessedAt = &now
+	case retry.Failed:
+		stored.Status = constant.OutboxFailed
+		stored.Error = err.Error()
+	default:
+		stored.Error = err.Error()
+		stored.NextAttemptAt = retryAt
+	}
+
+	// The outcome is stored even if the relay is stopping
+	if err := o.dbRepo.UpdateOutboxEvent(utility.Detach(ctx), stored); err != nil {
+		logging.FromContext(ctx).Errorf("could not update event '%s', got error: %s", stored.ID, err)
+	}
+}
+
+// dispatch runs the subscribers of 'event' missing from the completed ones of 'stored', and adds
+// those that handle it
+func (o *Outbox) dispatch(ctx context.Context, event Event, stored *model.OutboxEvent) error {
+	completed := map[string]bool{}
+	for _, subscriber := range stored.Completed {
+		completed[subscriber] = true
+	}
+
+	var failed []string
+	for _, s := range o.subscribers(event.Name()) {
+		if completed[s.subscriber] {
+			continue
+		}
+		if err := handle(ctx, event, s); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", s.subscriber, err))
+			continue
+		}
+		stored.Completed = append(stored.Completed, s.subscriber)
+	}
+
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package geoip
+
+import (
+	"brief/internal/config"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+	log "github.com/sirupsen/logrus"
+)
+
+// Locator resolves the ISO 3166-1 alpha-2 country code of an IP address.
+// An empty code is returned when the country is unknown
+type Locator interface {
+	Country(ip net.IP) (string, error)
+}
+
+var locator Locator = noop{}
+
+// Setup opens the local MaxMind database configured in 'GEOIP_DATABASE', no lookups
+// are made over the network. Geo targeting is disabled when no database is configured
+func Setup(logger *log.Logger) {
+	path := config.GetConfig().GeoIPDatabase
+	if path == "" {
+		logger.Info("GEOIP DATABASE NOT CONFIGURED")
+		return
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		logger.Fatalf("could not open geoip database, got error: %s", err)
+	}
+
+	locator = &MaxMind{reader: reader}
+	logger.Info("GEOIP DATABASE LOADED")
+}
+
+// GetLocator returns the configured locator
+func GetLocator() Locator {
+	return locator
+}
+
+type MaxMind struct {
+	reader *maxminddb.Reader
+}
+
+// Country looks up 'ip' in a MaxMind country or city database
+func (m *MaxMind) Country(ip net.IP) (string, error) {
+	if ip == nil {
+		return "", nil
+	}
+
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := m.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+
+	return strings.ToUpper(record.Country.ISOCode), nil
+}
+
+type noop struct{}
+
+func (noop) Country(ip net.IP) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/model"
+	"brief/utility"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//	Get Audit Logs
+//
+// @Summary		get audit logs - Admin
+// @Description	get audit logs of security-relevant and administrative actions, latest first - Admin
+// @Tags			Audit - Admin
+// @Accept			json
+// @Produce		json
+// @Param			actor_id	query		string	false	"ID of the user who performed the action"
+// @Param			action		query		string	false	"Action, e.g. user.lock"
+// @Param			target_type	query		string	false	"Target type, e.g. user or url"
+// @Param			target_id	query		string	false	"Target ID"
+// @Param			from		query		string	false	"Earliest time, RFC 3339"
+// @Param			to			query		string	false	"Latest time (exclusive), RFC 3339"
+// @Param			limit		query		int		false	"Maximum number of logs, defaults to 100"
+// @Param			offset		query		int		false	"Number of logs to skip"
+// @Success		200	{object}	utility.Response{data=[]model.AuditLog}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/audit-logs [get]
+// @Security		JWTToken
+func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	entries, err := base.AuditService.GetAll(r.Context(), filter)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", entries)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Export Audit Logs
+//
+// @Summary		export audit logs - Admin
+// @Description	export every audit log matching the filters as JSON lines, oldest first - Admin
+// @Tags			Audit - Admin
+// @Produce		application/x-ndjson
+// @Param			actor_id	query		string	false	"ID of the user who performed the action"
+// @Param			action		query		string	false	"Action, e.g. user.lock"
+// @Param			target_type	query		string	false	"Target type, e.g. user or url"
+// @Param			target_id	query		string	false	"Target ID"
+// @Param			from		query		string	false	"Earliest time, RFC 3339"
+// @Param			to			query		string	false	"Latest time (exclusive), RFC 3339"
+// @Success		200	{string}	string	"JSON lines of model.AuditLog"
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/audit-logs/export [get]
+// @Security		JWTToken
+func (base *Controller) Export(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=audit-logs-%s.jsonl", time.Now().Format("20060102150405")))
+	w.WriteHeader(http.StatusOK)
+
+	// The status has been sent, failures can only be logged
+	if err := base.AuditService.Export(r.Context(), filter, w); err != nil {
+		base.Logger.Errorf("could not export audit logs, got error: %s", err)
+	}
+}
+
+// parseFilter reads the filters of audit logs from the query of 'r'
+func parseFilter(r *http.Request) (*model.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := &model.AuditFilter{
+		ActorID:    q.Get("actor_id"),
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+	}
+
+	for key, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := q.Get(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, apperror.Validation(apperror.CodeInvalidOption, "'%s' must be an RFC 3339 time", key)
+			}
+			*dst = &t
+		}
+	}
+
+	for key, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := q.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, apperror.Validation(apperror.CodeInvalidOption, "'%s' must be a number", key)
+			}
+			*dst = n
+		}
+	}
+
+	return filter, nil
+}
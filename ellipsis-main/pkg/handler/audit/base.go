@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"brief/service/audit"
+
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Validate     *validator.Validate
+	Logger       *log.Logger
+	AuditService audit.AuditService
+}
+
+func NewController(validate *validator.Validate, logger *log.Logger, aService audit.AuditService) *Controller {
+	return &Controller{
+		validate, logger, aService,
+	}
+}
@@ -0,0 +1,20 @@
+package campaign
+
+import (
+	"brief/service/campaign"
+
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Validate        *validator.Validate
+	Logger          *log.Logger
+	CampaignService campaign.CampaignService
+}
+
+func NewController(validate *validator.Validate, logger *log.Logger, cService campaign.CampaignService) *Controller {
+	return &Controller{
+		validate, logger, cService,
+	}
+}
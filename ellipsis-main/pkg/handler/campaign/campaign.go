@@ -0,0 +1,250 @@
+package campaign
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/model"
+	mdw "brief/pkg/middleware"
+	"brief/utility"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//	Create
+//
+// @Summary		create a campaign
+// @Description	create a campaign whose utm fields are merged into its links
+// @Tags			Campaign
+// @Accept			json
+// @Produce		json
+// @Param			campaign	body		model.Campaign	true	"Campaign"
+// @Success		201		{object}	utility.Response{data=model.Campaign}
+// @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
+// @Router			/campaigns [post]
+// @Security		JWTToken
+func (base *Controller) Create(w http.ResponseWriter, r *http.Request) {
+	req := new(model.Campaign)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	if err := base.CampaignService.Create(r.Context(), req, uInfo); err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "successfully created campaign", req)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(res)
+}
+
+//	Get Campaigns
+//
+// @Summary		get all my campaigns
+// @Description	get all my campaigns
+// @Tags			Campaign
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.Campaign}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/campaigns [get]
+// @Security		JWTToken
+func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	campaigns, err := base.CampaignService.GetAll(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", campaigns)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Campaign
+//
+// @Summary		get a campaign
+// @Description	get a campaign
+// @Tags			Campaign
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"campaign ID"
+// @Success		200	{object}	utility.Response{data=model.Campaign}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/campaigns/{id} [get]
+// @Security		JWTToken
+func (base *Controller) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	campaign, err := base.CampaignService.Get(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", campaign)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Update Campaign
+//
+// @Summary		update a campaign
+// @Description	update a campaign's name and utm fields, existing links are not rewritten
+// @Tags			Campaign
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string			true	"campaign ID"
+// @Param			campaign	body		model.Campaign	true	"Campaign Update"
+// @Success		200	{object}	utility.Response{data=model.Campaign}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/campaigns/{id} [patch]
+// @Security		JWTToken
+func (base *Controller) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	req := new(model.Campaign)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	if err := base.CampaignService.Update(r.Context(), uInfo, id, req); err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "updated successfully", req)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Delete Campaign
+//
+// @Summary		delete a campaign
+// @Description	delete a campaign, its links are kept but no longer grouped
+// @Tags			Campaign
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"campaign ID"
+// @Success		200	{object}	utility.Response{data=model.Campaign}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/campaigns/{id} [delete]
+// @Security		JWTToken
+func (base *Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	campaign, err := base.CampaignService.Delete(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully deleted campaign", campaign)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Campaign Urls
+//
+// @Summary		list the links of a campaign
+// @Description	list the links of a campaign along with aggregate figures
+// @Tags			Campaign
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"campaign ID"
+// @Success		200	{object}	utility.Response{data=model.CampaignSummary}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/campaigns/{id}/urls [get]
+// @Security		JWTToken
+func (base *Controller) GetUrls(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	summary, err := base.CampaignService.GetUrls(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", summary)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
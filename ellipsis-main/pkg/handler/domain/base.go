@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"brief/service/domain"
+
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Validate      *validator.Validate
+	Logger        *log.Logger
+	DomainService domain.DomainService
+}
+
+func NewController(validate *validator.Validate, logger *log.Logger, dService domain.DomainService) *Controller {
+	return &Controller{
+		validate, logger, dService,
+	}
+}
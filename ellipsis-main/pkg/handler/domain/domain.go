@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/model"
+	mdw "brief/pkg/middleware"
+	"brief/utility"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//	Add
+//
+// @Summary		register a custom domain
+// @Description	register a custom domain, the returned challenge has to be published before verifying it
+// @Tags			Domain
+// @Accept			json
+// @Produce		json
+// @Param			domain	body		model.Domain	true	"Domain"
+// @Success		201		{object}	utility.Response{data=model.DomainChallenge}
+// @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		409		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
+// @Router			/domains [post]
+// @Security		JWTToken
+func (base *Controller) Add(w http.ResponseWriter, r *http.Request) {
+	req := new(model.Domain)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	challenge, err := base.DomainService.Add(r.Context(), req, uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "successfully registered domain", challenge)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(res)
+}
+
+//	Get Domains
+//
+// @Summary		get all my domains
+// @Description	get all my domains
+// @Tags			Domain
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.Domain}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/domains [get]
+// @Security		JWTToken
+func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	domains, err := base.DomainService.GetAll(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", domains)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Challenge
+//
+// @Summary		get the verification challenge of my domain
+// @Description	get the DNS TXT record or well-known file that proves control over my domain
+// @Tags			Domain
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"domain ID"
+// @Success		200	{object}	utility.Response{data=model.DomainChallenge}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/domains/{id}/challenge [get]
+// @Security		JWTToken
+func (base *Controller) Challenge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	challenge, err := base.DomainService.Challenge(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", challenge)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Verify
+//
+// @Summary		verify my domain
+// @Description	check the verification challenge of my domain so links can be issued under it
+// @Tags			Domain
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"domain ID"
+// @Success		200	{object}	utility.Response{data=model.Domain}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/domains/{id}/verify [post]
+// @Security		JWTToken
+func (base *Controller) Verify(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	domain, err := base.DomainService.Verify(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully verified domain", domain)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Delete Domain
+//
+// @Summary		delete my domain
+// @Description	delete my domain along with the links issued under it
+// @Tags			Domain
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"domain ID"
+// @Success		200	{object}	utility.Response{data=model.Domain}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/domains/{id} [delete]
+// @Security		JWTToken
+func (base *Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	domain, err := base.DomainService.Delete(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully deleted domain", domain)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
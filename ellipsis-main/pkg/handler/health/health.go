@@ -1,12 +1,12 @@
 package health
 
 import (
+	"brief/pkg/logging"
 	"encoding/json"
-	"fmt"
 	"net/http"
 
-	"brief/internal/model"
-	"brief/service/ping"
+	"brief/internal/constant"
+	"brief/service/health"
 	"brief/utility"
 
 	log "github.com/sirupsen/logrus"
@@ -17,82 +17,48 @@ import (
 type Controller struct {
 	Validate      *validator.Validate
 	Logger        *log.Logger
-	HealthService ping.HealthService
+	HealthService health.HealthService
 }
 
-func NewController(validate *validator.Validate, logger *log.Logger, hService ping.HealthService) *Controller {
+func NewController(validate *validator.Validate, logger *log.Logger, hService health.HealthService) *Controller {
 	return &Controller{
 		validate, logger, hService,
 	}
 }
 
-// Post godoc
-//
-//	@Summary		check api health
-//	@Description	check api health
-//	@Tags			Health
-//	@Accept			json
-//	@Produce		json
-//	@Param			ping	body		model.Ping	true	"Ping"
-//	@Success		200		{object}	utility.Response
-//	@Failure		400		{object}	utility.Response
-//	@Router			/health [post]
-func (base *Controller) Post(w http.ResponseWriter, r *http.Request) {
-	var req model.Ping
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
-		return
-	}
-
-	if err := base.Validate.Struct(&req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Validation failed", utility.ValidationResponse(err, base.Validate), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
-		return
-	}
-
-	if !base.HealthService.ReturnTrue() {
-		rd := utility.BuildErrorResponse(http.StatusInternalServerError, "error", "ping failed", fmt.Errorf("ping failed"), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
-		return
-	}
-
-	base.Logger.Info("ping successfull")
-
-	rd := utility.BuildSuccessResponse(http.StatusOK, "ping successfull", req.Message)
+// Live reports that the instance is running, dependencies are not checked. Served on /livez
+func (base *Controller) Live(w http.ResponseWriter, r *http.Request) {
+	rd := utility.BuildSuccessResponse(http.StatusOK, "instance is live", base.HealthService.Live())
 	res, _ := json.Marshal(rd)
 	w.WriteHeader(http.StatusOK)
 	w.Write(res)
 }
 
-// Get godoc
+// Ready godoc. Served on /readyz, and on /api/v1/health for existing monitors
 //
-//	@Summary		check api health
-//	@Description	check api health
+//	@Summary		check that the instance can serve requests
+//	@Description	check that the instance and its dependencies can serve requests, with the status and latency of each check
 //	@Tags			Health
-//	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	utility.Response
-//	@Failure		400	{object}	utility.Response
+//	@Success		200	{object}	utility.Response{data=model.Health}
+//	@Failure		503	{object}	utility.Response{data=model.Health}
 //	@Router			/health [get]
-func (base *Controller) Get(w http.ResponseWriter, r *http.Request) {
-	if !base.HealthService.ReturnTrue() {
-		rd := utility.BuildErrorResponse(http.StatusInternalServerError, "error", "ping failed", fmt.Errorf("ping failed"), nil)
+func (base *Controller) Ready(w http.ResponseWriter, r *http.Request) {
+	report := base.HealthService.Ready(r.Context())
+	if report.Status != constant.HealthOK {
+		base.Logger.WithField("request_id", logging.RequestID(r.Context())).
+			WithField("checks", report.Checks).Warn("instance is not ready")
+
+		rd := utility.BuildErrorResponse(http.StatusServiceUnavailable, constant.StatusFailed,
+			constant.ErrUnavailable, "instance is not ready", report)
+		rd.RequestID = logging.RequestID(r.Context())
 		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(http.StatusServiceUnavailable)
 		w.Write(res)
 		return
 	}
 
-	base.Logger.Info("ping successfull")
-	rd := utility.BuildSuccessResponse(http.StatusOK, "ping successfull", map[string]interface{}{"user": "user object"})
+	rd := utility.BuildSuccessResponse(http.StatusOK, "instance is ready", report)
 	res, _ := json.Marshal(rd)
 	w.WriteHeader(http.StatusOK)
 	w.Write(res)
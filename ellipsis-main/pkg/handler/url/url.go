@@ -1,30 +1,42 @@
 package url
 
 import (
-	"brief/internal/constant"
+	"brief/internal/apperror"
 	"brief/internal/model"
+	mdw "brief/pkg/middleware"
+	urlSrv "brief/service/url"
 	"brief/utility"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
-// Redirect - /api/v1/{hash} - GET
+// Redirect - /{hash} and /{hash}/* - GET
 func (base *Controller) Redirect(w http.ResponseWriter, r *http.Request) {
 	hash := chi.URLParam(r, "hash")
+	rest := chi.URLParam(r, "*")
 
-	url, err := base.UrlService.Redirect(hash)
+	redirection, err := base.UrlService.Redirect(r.Context(), hash, rest, r)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
-	http.Redirect(w, r, url.LongURL, http.StatusTemporaryRedirect)
+	// Pin the visitor to the variant they were served
+	if redirection.Variant != nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:     urlSrv.VariantCookie(redirection.URL.Hash),
+			Value:    redirection.Variant.ID,
+			Path:     "/" + redirection.URL.Hash,
+			Expires:  time.Now().Add(30 * 24 * time.Hour),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	http.Redirect(w, r, redirection.Destination, http.StatusTemporaryRedirect)
 }
 
 //	Shorten
@@ -37,42 +49,30 @@ func (base *Controller) Redirect(w http.ResponseWriter, r *http.Request) {
 // @Param			url	body		model.URL	true	"URL"
 // @Success		201		{object}	utility.Response{data=model.URL}
 // @Failure		400		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		404		{object}	utility.Response
+// @Failure		409		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
 // @Router			/url/shorten [post]
 // @Security		JWTToken
 func (base *Controller) Shorten(w http.ResponseWriter, r *http.Request) {
 	req := new(model.URL)
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Binding(err))
 		return
 	}
 
 	// Fetch user information from context
-	uInfo := r.Context().Value(struct{}{})
-	ctxInfo, ok := uInfo.(*model.ContextInfo)
-	if !ok {
-		ctxInfo = nil
-	}
+	ctxInfo := mdw.GetContextInfo(r.Context())
 
 	if err := base.Validate.Struct(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrValidation, utility.ValidationResponse(err, base.Validate), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
 		return
 	}
 
-	if err := base.UrlService.Shorten(req, ctxInfo, r); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+	if err := base.UrlService.Shorten(r.Context(), req, ctxInfo, r); err != nil {
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -85,35 +85,29 @@ func (base *Controller) Shorten(w http.ResponseWriter, r *http.Request) {
 //	Get Url's
 //
 // @Summary		get all my urls
-// @Description	get all my urls
+// @Description	get all my urls, or all urls of a workspace I am a member of
 // @Tags			URL
 // @Accept			json
 // @Produce		json
+// @Param			workspace_id	query		string	false	"workspace ID"
 // @Success		200	{object}	utility.Response{data=[]model.URL}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/url [get]
 // @Security		JWTToken
 func (base *Controller) GetUrls(w http.ResponseWriter, r *http.Request) {
-	uInfo := r.Context().Value(struct{}{}) // fetch user's info from context
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
 
 	if uInfo == nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, "user ID not found", nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
 		return
 	}
 
-	uId := uInfo.(*model.ContextInfo).ID
-	urls, err := base.UrlService.GetURLs(uId)
+	urls, err := base.UrlService.GetURLs(r.Context(), uInfo, uInfo.ID, r.URL.Query().Get("workspace_id"))
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -126,7 +120,7 @@ func (base *Controller) GetUrls(w http.ResponseWriter, r *http.Request) {
 //	Delete
 //
 // @Summary		delete my url
-// @Description	delete my url
+// @Description	move my url to the trash, it can be restored and its hash stays reserved until the retention period is over
 // @Tags			URL
 // @Accept			json
 // @Produce		json
@@ -134,29 +128,23 @@ func (base *Controller) GetUrls(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=[]model.URL}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/url/{id} [delete]
 // @Security		JWTToken
 func (base *Controller) Delete(w http.ResponseWriter, r *http.Request) {
 	urlId := chi.URLParam(r, "id")
-	uInfo := r.Context().Value(struct{}{}) // fetch user's info from context
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
 
 	if uInfo == nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, "user ID not found", nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
 		return
 	}
 
-	uContextInfo := uInfo.(*model.ContextInfo)
-	url, err := base.UrlService.Delete(uContextInfo, urlId)
+	url, err := base.UrlService.Delete(r.Context(), uInfo, urlId)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -166,6 +154,402 @@ func (base *Controller) Delete(w http.ResponseWriter, r *http.Request) {
 	w.Write(res)
 }
 
+//	Trash
+//
+// @Summary		get my deleted urls
+// @Description	get my deleted urls that can still be restored, latest first
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.URL}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/trash [get]
+// @Security		JWTToken
+func (base *Controller) Trash(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	urls, err := base.UrlService.Trash(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", urls)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Restore
+//
+// @Summary		restore my deleted url
+// @Description	take my url out of the trash, within the retention period
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"url ID"
+// @Success		200	{object}	utility.Response{data=model.URL}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		409	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/restore [patch]
+// @Security		JWTToken
+func (base *Controller) Restore(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	url, err := base.UrlService.Restore(r.Context(), uInfo, urlId)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully restored url", url)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Move To Workspace
+//
+// @Summary		move my url into a workspace
+// @Description	move my url into a workspace I can edit, an empty workspace_id makes it personal again
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string				true	"url ID"
+// @Param			workspace	body		model.URLWorkspace	true	"Workspace"
+// @Success		200	{object}	utility.Response{data=model.URL}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/workspace [patch]
+// @Security		JWTToken
+func (base *Controller) MoveToWorkspace(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	req := new(model.URLWorkspace)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	url, err := base.UrlService.MoveToWorkspace(r.Context(), uInfo, urlId, req.WorkspaceID)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully moved url", url)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Rules
+//
+// @Summary		get the redirect rules of my url
+// @Description	get the redirect rules of my url in evaluation order
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"url ID"
+// @Success		200	{object}	utility.Response{data=[]model.RedirectRule}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/rules [get]
+// @Security		JWTToken
+func (base *Controller) GetRules(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	rules, err := base.UrlService.GetRules(r.Context(), uInfo, urlId)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", rules)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Set Rules
+//
+// @Summary		replace the redirect rules of my url
+// @Description	replace the redirect rules of my url, rules are matched in the order given and the url's long_url is used when none match
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string			true	"url ID"
+// @Param			rules	body		model.RuleSet	true	"Rules"
+// @Success		200	{object}	utility.Response{data=[]model.RedirectRule}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/rules [put]
+// @Security		JWTToken
+func (base *Controller) SetRules(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	req := new(model.RuleSet)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	rules, err := base.UrlService.SetRules(r.Context(), uInfo, urlId, req.Rules)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully updated rules", rules)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Variants
+//
+// @Summary		get the split test variants of my url
+// @Description	get the split test variants of my url along with the number of clicks each received
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"url ID"
+// @Success		200	{object}	utility.Response{data=[]model.VariantStats}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/variants [get]
+// @Security		JWTToken
+func (base *Controller) GetVariants(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	stats, err := base.UrlService.GetVariants(r.Context(), uInfo, urlId)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", stats)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Stats
+//
+// @Summary		get the click stats of my url
+// @Description	get the number of clicks my url received, by country, with the first and last click
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"url ID"
+// @Success		200	{object}	utility.Response{data=model.LinkStats}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/stats [get]
+// @Security		JWTToken
+func (base *Controller) GetStats(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	stats, err := base.UrlService.GetStats(r.Context(), uInfo, urlId)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", stats)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Broken Links
+//
+// @Summary		get my broken urls
+// @Description	get my urls, and the urls of my workspaces, whose destination failed its latest checks in a row, with the latest check of each. Destinations are checked periodically
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.BrokenLink}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/broken [get]
+// @Security		JWTToken
+func (base *Controller) GetBrokenLinks(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	broken, err := base.UrlService.GetBrokenLinks(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", broken)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Link Checks
+//
+// @Summary		get the checks of my url's destination
+// @Description	get the latest checks of my url's destination by the link checker, latest first
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"url ID"
+// @Success		200	{object}	utility.Response{data=[]model.LinkCheck}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/checks [get]
+// @Security		JWTToken
+func (base *Controller) GetLinkChecks(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	checks, err := base.UrlService.GetLinkChecks(r.Context(), uInfo, urlId)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", checks)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Set Variants
+//
+// @Summary		replace the split test variants of my url
+// @Description	replace the split test variants of my url, traffic is shared according to each variant's weight
+// @Tags			URL
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string				true	"url ID"
+// @Param			variants	body		model.VariantSet	true	"Variants"
+// @Success		200	{object}	utility.Response{data=[]model.Variant}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/url/{id}/variants [put]
+// @Security		JWTToken
+func (base *Controller) SetVariants(w http.ResponseWriter, r *http.Request) {
+	urlId := chi.URLParam(r, "id")
+	req := new(model.VariantSet)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	variants, err := base.UrlService.SetVariants(r.Context(), uInfo, urlId, req.Variants)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully updated variants", variants)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
 // ADMIN ENDPOINTS
 
 //	Get All
@@ -178,22 +562,20 @@ func (base *Controller) Delete(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=[]model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/url/get-all [get]
 // @Security		JWTToken
 func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
-	urls, err := base.UrlService.GetAll()
+	urls, err := base.UrlService.GetAll(r.Context())
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
 	rd := utility.BuildSuccessResponse(http.StatusOK, "", urls)
 	res, _ := json.Marshal(rd)
-	w.WriteHeader(http.StatusBadRequest)
+	w.WriteHeader(http.StatusOK)
 	w.Write(res)
 }
 
@@ -208,17 +590,17 @@ func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=[]model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/url/get-all/{user-id} [get]
 // @Security		JWTToken
 func (base *Controller) GetUrlsByUserID(w http.ResponseWriter, r *http.Request) {
 	uID := chi.URLParam(r, "user-id")
-	urls, err := base.UrlService.GetURLs(uID)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+	urls, err := base.UrlService.GetURLs(r.Context(), uInfo, uID, "")
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -1,10 +1,13 @@
 package user
 
 import (
-	"brief/internal/constant"
+	"brief/internal/apperror"
 	"brief/internal/model"
+	mdw "brief/pkg/middleware"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"brief/utility"
 
@@ -21,35 +24,25 @@ import (
 // @Param			user	body		model.User	true	"User"
 // @Success		201		{object}	utility.Response{data=model.User}
 // @Failure		400		{object}	utility.Response
+// @Failure		409		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
 // @Router			/users [post]
 func (base *Controller) Register(w http.ResponseWriter, r *http.Request) {
 	req := new(model.User)
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Binding(err))
 		return
 	}
 
 	if err := base.Validate.Struct(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrValidation, utility.ValidationResponse(err, base.Validate), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
 		return
 	}
 
-	token, err := base.UserService.Register(req)
+	token, err := base.UserService.Register(r.Context(), req)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -73,35 +66,25 @@ func (base *Controller) Register(w http.ResponseWriter, r *http.Request) {
 // @Param			userInfo	body		model.UserLogin	true	"Login Info"
 // @Success		201		{object}	utility.Response{data=model.User}
 // @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
 // @Router			/users/login [post]
 func (base *Controller) Login(w http.ResponseWriter, r *http.Request) {
 	req := new(model.UserLogin)
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Binding(err))
 		return
 	}
 
 	if err := base.Validate.Struct(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrValidation, utility.ValidationResponse(err, base.Validate), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
 		return
 	}
 
-	usr, err := base.UserService.Login(req)
+	usr, err := base.UserService.Login(r.Context(), req, r)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -122,27 +105,21 @@ func (base *Controller) Login(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/users [get]
 // @Security		JWTToken
 func (base *Controller) GetMe(w http.ResponseWriter, r *http.Request) {
-	uInfo := r.Context().Value(struct{}{}) // fetch user's info from context
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
 	if uInfo == nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, "user ID not found", nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
 		return
 	}
 
-	uId := uInfo.(*model.ContextInfo).ID
-	usr, err := base.UserService.Get(uId)
+	uId := uInfo.ID
+	usr, err := base.UserService.Get(r.Context(), uId)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -155,7 +132,7 @@ func (base *Controller) GetMe(w http.ResponseWriter, r *http.Request) {
 //	UpdateMe
 //
 // @Summary		update a user
-// @Description	update a user
+// @Description	update the firstname and lastname of a user, other fields are ignored
 // @Tags			User
 // @Accept			json
 // @Produce		json
@@ -163,39 +140,29 @@ func (base *Controller) GetMe(w http.ResponseWriter, r *http.Request) {
 // @Success		200		{object}	utility.Response{data=model.User}
 // @Failure		400		{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
 // @Router			/users [patch]
 // @Security		JWTToken
 func (base *Controller) UpdateMe(w http.ResponseWriter, r *http.Request) {
 
 	req := new(model.User)
-	uInfo := r.Context().Value(struct{}{}) // fetch user's info from context
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
 
 	if uInfo == nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, "user ID not found", nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
 		return
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Binding(err))
 		return
 	}
 
-	uId := uInfo.(*model.ContextInfo).ID
-	err := base.UserService.Update(uId, req)
+	uId := uInfo.ID
+	err := base.UserService.Update(r.Context(), uId, req)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -216,39 +183,28 @@ func (base *Controller) UpdateMe(w http.ResponseWriter, r *http.Request) {
 // @Success		200		{object}	utility.Response{data=model.User}
 // @Failure		400		{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
 // @Router			/users/reset-password [patch]
 // @Security		JWTToken
 func (base *Controller) ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	req := new(model.ResetPassword)
-	uInfo := r.Context().Value(struct{}{}) // fetch user's info from context
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
 
 	if uInfo == nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, "user ID not found", nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
 		return
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrBinding, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, apperror.Binding(err))
 		return
 	}
 
-	uId := uInfo.(*model.ContextInfo).ID
-	usr, err := base.UserService.ResetPassword(uId, req)
+	usr, err := base.UserService.ResetPassword(r.Context(), uInfo, req)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -270,23 +226,21 @@ func (base *Controller) ResetPassword(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=[]model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/users/get-all [get]
 // @Security		JWTToken
 func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
 
-	usrs, err := base.UserService.GetAll()
+	usrs, err := base.UserService.GetAll(r.Context())
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
 	rd := utility.BuildSuccessResponse(http.StatusOK, "", usrs)
 	res, _ := json.Marshal(rd)
-	w.WriteHeader(http.StatusBadRequest)
+	w.WriteHeader(http.StatusOK)
 	w.Write(res)
 }
 
@@ -301,17 +255,16 @@ func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/users/{idOrEmail} [get]
 // @Security		JWTToken
 func (base *Controller) GetUserByIdOrEmail(w http.ResponseWriter, r *http.Request) {
 	idOrEmail := chi.URLParam(r, "idOrEmail")
-	usr, err := base.UserService.Get(idOrEmail)
+	usr, err := base.UserService.Get(r.Context(), idOrEmail)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -324,7 +277,7 @@ func (base *Controller) GetUserByIdOrEmail(w http.ResponseWriter, r *http.Reques
 //	Lock User
 //
 // @Summary		lock user - Admin
-// @Description	lock user - Admin
+// @Description	lock user, staff can only lock users of a lower rank and admins cannot be locked - Admin
 // @Tags			User - Admin
 // @Accept			json
 // @Produce		json
@@ -332,17 +285,17 @@ func (base *Controller) GetUserByIdOrEmail(w http.ResponseWriter, r *http.Reques
 // @Success		200	{object}	utility.Response{data=model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/users/lock/{idOrEmail} [patch]
 // @Security		JWTToken
 func (base *Controller) LockUser(w http.ResponseWriter, r *http.Request) {
 	idOrEmail := chi.URLParam(r, "idOrEmail")
-	user, err := base.UserService.LockUser(idOrEmail)
+	ctxInfo := mdw.GetContextInfo(r.Context()) // fetch admin's info from context
+	user, err := base.UserService.LockUser(r.Context(), ctxInfo, idOrEmail)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -355,7 +308,7 @@ func (base *Controller) LockUser(w http.ResponseWriter, r *http.Request) {
 //	Unlock User
 //
 // @Summary		unlock user - Admin
-// @Description	unlock user - Admin
+// @Description	unlock user, staff can only unlock users of a lower rank - Admin
 // @Tags			User - Admin
 // @Accept			json
 // @Produce		json
@@ -363,17 +316,17 @@ func (base *Controller) LockUser(w http.ResponseWriter, r *http.Request) {
 // @Success		200	{object}	utility.Response{data=model.User}
 // @Failure		400	{object}	utility.Response
 // @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
 // @Router			/users/unlock/{idOrEmail} [patch]
 // @Security		JWTToken
 func (base *Controller) UnlockUser(w http.ResponseWriter, r *http.Request) {
 	idOrEmail := chi.URLParam(r, "idOrEmail")
-	user, err := base.UserService.UnlockUser(idOrEmail)
+	ctxInfo := mdw.GetContextInfo(r.Context()) // fetch admin's info from context
+	user, err := base.UserService.UnlockUser(r.Context(), ctxInfo, idOrEmail)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, constant.StatusFailed,
-			constant.ErrRequest, err.Error(), nil)
-		res, _ := json.Marshal(rd)
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(res)
+		utility.WriteError(w, r, err)
 		return
 	}
 
@@ -382,3 +335,251 @@ func (base *Controller) UnlockUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(res)
 }
+
+//	Assign Role
+//
+// @Summary		assign a role to a user - Admin
+// @Description	assign a role (admin, user, moderator or analyst) to a user, the change is recorded and takes effect on the user's next request - Admin
+// @Tags			User - Admin
+// @Accept			json
+// @Produce		json
+// @Param			idOrEmail	path		string					true	"User ID or Email"
+// @Param			role		body		model.RoleAssignment	true	"Role"
+// @Success		200	{object}	utility.Response{data=model.User}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/users/{idOrEmail}/role [patch]
+// @Security		JWTToken
+func (base *Controller) AssignRole(w http.ResponseWriter, r *http.Request) {
+	idOrEmail := chi.URLParam(r, "idOrEmail")
+	req := new(model.RoleAssignment)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	user, err := base.UserService.AssignRole(r.Context(), uInfo, idOrEmail, req.Role)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "role assigned successfully", user)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Role Changes
+//
+// @Summary		get the role history of a user - Admin
+// @Description	get the role changes of a user, latest first - Admin
+// @Tags			User - Admin
+// @Accept			json
+// @Produce		json
+// @Param			idOrEmail		path		string					true	"User ID or Email"
+// @Success		200	{object}	utility.Response{data=[]model.RoleChange}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/users/{idOrEmail}/role-changes [get]
+// @Security		JWTToken
+func (base *Controller) GetRoleChanges(w http.ResponseWriter, r *http.Request) {
+	idOrEmail := chi.URLParam(r, "idOrEmail")
+	changes, err := base.UserService.GetRoleChanges(r.Context(), idOrEmail)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", changes)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Delete User
+//
+// @Summary		delete user - Admin
+// @Description	move a user and their urls to the trash, their links in workspaces stay live. Their email and hashes stay reserved until the retention period is over - Admin
+// @Tags			User - Admin
+// @Accept			json
+// @Produce		json
+// @Param			idOrEmail		path		string					true	"User ID or Email"
+// @Success		200	{object}	utility.Response{data=model.User}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/users/{idOrEmail} [delete]
+// @Security		JWTToken
+func (base *Controller) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	idOrEmail := chi.URLParam(r, "idOrEmail")
+	ctxInfo := mdw.GetContextInfo(r.Context()) // fetch admin's info from context
+	user, err := base.UserService.Delete(r.Context(), ctxInfo, idOrEmail)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user deleted successfully", user)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Trash
+//
+// @Summary		list deleted users - Admin
+// @Description	list deleted users that can still be restored, latest first - Admin
+// @Tags			User - Admin
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.User}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/users/trash [get]
+// @Security		JWTToken
+func (base *Controller) Trash(w http.ResponseWriter, r *http.Request) {
+	users, err := base.UserService.Trash(r.Context())
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", users)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Restore User
+//
+// @Summary		restore user - Admin
+// @Description	take a user and the urls deleted with them out of the trash, within the retention period - Admin
+// @Tags			User - Admin
+// @Accept			json
+// @Produce		json
+// @Param			idOrEmail		path		string					true	"User ID or Email"
+// @Success		200	{object}	utility.Response{data=model.User}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		409	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/users/{idOrEmail}/restore [patch]
+// @Security		JWTToken
+func (base *Controller) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	idOrEmail := chi.URLParam(r, "idOrEmail")
+	ctxInfo := mdw.GetContextInfo(r.Context()) // fetch admin's info from context
+	user, err := base.UserService.Restore(r.Context(), ctxInfo, idOrEmail)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user restored successfully", user)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Delete Me
+//
+// @Summary		delete my account
+// @Description	delete my account after confirming my password. With 'delete' the account and my links are permanently deleted at once. With 'transfer' my links are given to the admin and the account is then permanently deleted. With 'keep' my links stay live under an anonymized account. The audit log only keeps the id of the account
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			confirmation	body		model.DeleteAccount	true	"Confirmation"
+// @Success		200		{object}	utility.Response
+// @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
+// @Router			/users [delete]
+// @Security		JWTToken
+func (base *Controller) DeleteMe(w http.ResponseWriter, r *http.Request) {
+
+	req := new(model.DeleteAccount)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	if err := base.UserService.DeleteAccount(r.Context(), uInfo, req); err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "account deleted successfully", nil)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Export Me
+//
+// @Summary		export my data
+// @Description	download a ZIP archive of my profile, links, clicks and click statistics
+// @Tags			User
+// @Produce		application/zip
+// @Success		200		{file}		binary
+// @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
+// @Router			/users/export [get]
+// @Security		JWTToken
+func (base *Controller) ExportMe(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	archive, err := base.UserService.Export(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=brief-export-%s.zip", time.Now().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive)
+}
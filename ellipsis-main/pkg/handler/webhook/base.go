@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"brief/service/webhook"
+
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Validate       *validator.Validate
+	Logger         *log.Logger
+	WebhookService webhook.WebhookService
+}
+
+func NewController(validate *validator.Validate, logger *log.Logger, wService webhook.WebhookService) *Controller {
+	return &Controller{
+		validate, logger, wService,
+	}
+}
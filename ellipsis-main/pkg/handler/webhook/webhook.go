@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/model"
+	mdw "brief/pkg/middleware"
+	"brief/utility"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//	Create
+//
+// @Summary		register a webhook
+// @Description	register an endpoint to be sent the events it subscribes to: url.created, url.deleted, url.clicked, url.broken, and user.locked for staff, sent only while they remain staff. Payloads are signed with the returned secret in the X-Brief-Signature header, 't=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">'. The url must resolve to public addresses and redirects are not followed. The secret is only returned once
+// @Tags			Webhook
+// @Accept			json
+// @Produce		json
+// @Param			webhook	body		model.Webhook	true	"Webhook"
+// @Success		201		{object}	utility.Response{data=model.Webhook}
+// @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
+// @Router			/webhooks [post]
+// @Security		JWTToken
+func (base *Controller) Create(w http.ResponseWriter, r *http.Request) {
+	req := new(model.Webhook)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	webhook, err := base.WebhookService.Create(r.Context(), req, uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "successfully registered webhook", webhook)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(res)
+}
+
+//	Get Webhooks
+//
+// @Summary		get all my webhooks
+// @Description	get all my webhooks, without their secrets
+// @Tags			Webhook
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.Webhook}
+// @Failure		401	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/webhooks [get]
+// @Security		JWTToken
+func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	webhooks, err := base.WebhookService.GetAll(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", webhooks)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Delete Webhook
+//
+// @Summary		delete my webhook
+// @Description	delete my webhook, events not delivered yet are dropped
+// @Tags			Webhook
+// @Accept			json
+// @Produce		json
+// @Param			id	path		string	true	"webhook ID"
+// @Success		200	{object}	utility.Response{data=model.Webhook}
+// @Failure		401	{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/webhooks/{id} [delete]
+// @Security		JWTToken
+func (base *Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	webhook, err := base.WebhookService.Delete(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully deleted webhook", webhook)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Deliveries
+//
+// @Summary		get the delivery log of my webhook
+// @Description	get the latest 100 deliveries of my webhook with the outcome of their last attempt, latest first
+// @Tags			Webhook
+// @Accept			json
+// @Produce		json
+// @Param			id	path		string	true	"webhook ID"
+// @Success		200	{object}	utility.Response{data=[]model.WebhookDelivery}
+// @Failure		401	{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/webhooks/{id}/deliveries [get]
+// @Security		JWTToken
+func (base *Controller) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	deliveries, err := base.WebhookService.GetDeliveries(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", deliveries)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Replay Delivery
+//
+// @Summary		replay a delivery of my webhook
+// @Description	send the event of a delivery of my webhook again, as a new delivery with the same event ID
+// @Tags			Webhook
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string	true	"webhook ID"
+// @Param			delivery-id	path		string	true	"delivery ID"
+// @Success		202			{object}	utility.Response{data=model.WebhookDelivery}
+// @Failure		401			{object}	utility.Response
+// @Failure		403			{object}	utility.Response
+// @Failure		404			{object}	utility.Response
+// @Failure		500			{object}	utility.Response
+// @Router			/webhooks/{id}/deliveries/{delivery-id}/replay [post]
+// @Security		JWTToken
+func (base *Controller) Replay(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	deliveryID := chi.URLParam(r, "delivery-id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	delivery, err := base.WebhookService.Replay(r.Context(), uInfo, id, deliveryID)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusAccepted, "successfully queued delivery", delivery)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(res)
+}
@@ -0,0 +1,20 @@
+package workspace
+
+import (
+	"brief/service/workspace"
+
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Validate         *validator.Validate
+	Logger           *log.Logger
+	WorkspaceService workspace.WorkspaceService
+}
+
+func NewController(validate *validator.Validate, logger *log.Logger, wService workspace.WorkspaceService) *Controller {
+	return &Controller{
+		validate, logger, wService,
+	}
+}
@@ -0,0 +1,356 @@
+package workspace
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/model"
+	mdw "brief/pkg/middleware"
+	"brief/utility"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//	Create
+//
+// @Summary		create a workspace
+// @Description	create a workspace owned by me, links created in it are shared with its members
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			workspace	body		model.Workspace	true	"Workspace"
+// @Success		201		{object}	utility.Response{data=model.Workspace}
+// @Failure		400		{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403		{object}	utility.Response
+// @Failure		500		{object}	utility.Response
+// @Router			/workspaces [post]
+// @Security		JWTToken
+func (base *Controller) Create(w http.ResponseWriter, r *http.Request) {
+	req := new(model.Workspace)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	if err := base.WorkspaceService.Create(r.Context(), req, uInfo); err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "successfully created workspace", req)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(res)
+}
+
+//	Get Workspaces
+//
+// @Summary		get all my workspaces
+// @Description	get all workspaces I am a member of
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	utility.Response{data=[]model.Workspace}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces [get]
+// @Security		JWTToken
+func (base *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	workspaces, err := base.WorkspaceService.GetAll(r.Context(), uInfo)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", workspaces)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Get Members
+//
+// @Summary		get the members of a workspace
+// @Description	get the members of a workspace and their roles
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"workspace ID"
+// @Success		200	{object}	utility.Response{data=[]model.Membership}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces/{id}/members [get]
+// @Security		JWTToken
+func (base *Controller) GetMembers(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	members, err := base.WorkspaceService.GetMembers(r.Context(), uInfo, id)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "", members)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Update Member
+//
+// @Summary		change the role of a member - Owner
+// @Description	change the role of a member of a workspace - Owner
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string				true	"workspace ID"
+// @Param			user-id	path		string				true	"user ID"
+// @Param			role	body		model.MemberRole	true	"Role"
+// @Success		200	{object}	utility.Response{data=model.Membership}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		409	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces/{id}/members/{user-id} [patch]
+// @Security		JWTToken
+func (base *Controller) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	userID := chi.URLParam(r, "user-id")
+	req := new(model.MemberRole)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	membership, err := base.WorkspaceService.UpdateMember(r.Context(), uInfo, id, userID, req.Role)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully updated member", membership)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Remove Member
+//
+// @Summary		remove a member from a workspace
+// @Description	remove a member from a workspace, members can remove themselves and owners can remove anyone else. Links stay in the workspace
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string					true	"workspace ID"
+// @Param			user-id	path		string					true	"user ID"
+// @Success		200	{object}	utility.Response{data=model.Membership}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		409	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces/{id}/members/{user-id} [delete]
+// @Security		JWTToken
+func (base *Controller) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	userID := chi.URLParam(r, "user-id")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	membership, err := base.WorkspaceService.RemoveMember(r.Context(), uInfo, id, userID)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully removed member", membership)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Invite
+//
+// @Summary		invite a user to a workspace - Owner
+// @Description	invite a user to a workspace by email - Owner
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string				true	"workspace ID"
+// @Param			invitation	body		model.Invitation	true	"Invitation"
+// @Success		201	{object}	utility.Response{data=model.Invitation}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces/{id}/invitations [post]
+// @Security		JWTToken
+func (base *Controller) Invite(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	req := new(model.Invitation)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	if err := base.WorkspaceService.Invite(r.Context(), uInfo, id, req); err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "successfully created invitation", req)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(res)
+}
+
+//	Accept Invitation
+//
+// @Summary		accept an invitation to a workspace
+// @Description	join a workspace using an invitation sent to my email, members keep their role if it ranks higher than the invited one
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			token	path		string					true	"invitation token"
+// @Success		200	{object}	utility.Response{data=model.Membership}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		409	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces/invitations/{token}/accept [post]
+// @Security		JWTToken
+func (base *Controller) Accept(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	membership, err := base.WorkspaceService.Accept(r.Context(), uInfo, token)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully joined workspace", membership)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
+
+//	Transfer
+//
+// @Summary		transfer a workspace - Owner
+// @Description	make another member the owner of a workspace, the previous owner becomes an editor - Owner
+// @Tags			Workspace
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string					true	"workspace ID"
+// @Param			transfer	body		model.WorkspaceTransfer	true	"New Owner"
+// @Success		200	{object}	utility.Response{data=model.Workspace}
+// @Failure		400	{object}	utility.Response
+// @Failure		401		{object}	utility.Response
+// @Failure		403	{object}	utility.Response
+// @Failure		404	{object}	utility.Response
+// @Failure		409	{object}	utility.Response
+// @Failure		500	{object}	utility.Response
+// @Router			/workspaces/{id}/transfer [post]
+// @Security		JWTToken
+func (base *Controller) Transfer(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	req := new(model.WorkspaceTransfer)
+	uInfo := mdw.GetContextInfo(r.Context()) // fetch user's info from context
+
+	if uInfo == nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user ID not found"))
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utility.WriteError(w, r, apperror.Binding(err))
+		return
+	}
+
+	if err := base.Validate.Struct(req); err != nil {
+		utility.WriteError(w, r, apperror.Invalid(utility.ValidationResponse(err, base.Validate)))
+		return
+	}
+
+	workspace, err := base.WorkspaceService.Transfer(r.Context(), uInfo, id, req.UserID)
+	if err != nil {
+		utility.WriteError(w, r, err)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "successfully transferred workspace", workspace)
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
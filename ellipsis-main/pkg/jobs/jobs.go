@@ -0,0 +1,321 @@
+package jobs
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/logging"
+	"brief/pkg/metrics"
+	"brief/pkg/retry"
+	"brief/pkg/tracing"
+	"brief/utility"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	defaultConcurrency = 4
+	defaultTimeout     = 5 * time.Minute
+	defaultMaxAttempts = 5
+	baseBackoff        = 30 * time.Second
+	maxBackoff         = 6 * time.Hour
+	// leaseRetry is how long a singleton job waits for the replica running one to be done
+	leaseRetry = time.Minute
+)
+
+// Handler runs a job. A job whose handler fails is retried with a backoff until it runs out of
+// attempts, handlers must then be idempotent
+type Handler func(ctx context.Context, job *model.Job) error
+
+// Options of the jobs with a name
+type Options struct {
+	MaxAttempts int  // attempts at a job before it is failed, 5 when not positive
+	Singleton   bool // at most one replica runs a job with the name at a time
+}
+
+// Queue stores jobs and the leases of singleton jobs. The postgres repository is one, claimed jobs
+// are skipped by other replicas until their lease is over
+type Queue interface {
+	CreateJob(ctx context.Context, job *model.Job) error
+	ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Job, error)
+	UpdateJob(ctx context.Context, job *model.Job) error
+	AcquireLease(ctx context.Context, name, holder string, now, expiresAt time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+type registration struct {
+	options Options
+	handler Handler
+}
+
+type scheduled struct {
+	name     string
+	schedule Schedule
+	next     time.Time
+}
+
+// Runner runs the jobs of a queue, up to a number at once, and queues scheduled jobs when they
+// are due
+type Runner struct {
+	queue   Queue
+	holder  string // the replica in leases
+	timeout time.Duration
+	slots   chan struct{}
+
+	mu        sync.Mutex
+	handlers  map[string]registration
+	schedules []*scheduled
+
+	running    sync.WaitGroup
+	draining   bool
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+}
+
+// NewRunner returns a runner running up to 'concurrency' jobs of 'queue' at once, each for at most
+// 'timeout'. Defaults are used for values that are not positive
+func NewRunner(queue Queue, concurrency int, timeout time.Duration) *Runner {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	return &Runner{
+		queue:      queue,
+		holder:     uuid.NewString(),
+		timeout:    timeout,
+		slots:      make(chan struct{}, concurrency),
+		handlers:   map[string]registration{},
+		jobCtx:     jobCtx,
+		cancelJobs: cancelJobs,
+	}
+}
+
+// Register runs jobs called 'name' with 'handler'
+func (r *Runner) Register(name string, options Options, handler Handler) {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[name] = registration{options, handler}
+}
+
+// Schedule queues a job called 'name' with an empty payload whenever the cron expression 'spec'
+// is due. Replicas queue the same job for a due time, so that it runs once
+func (r *Runner) Schedule(name, spec string) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedules = append(r.schedules, &scheduled{name: name, schedule: schedule})
+	return nil
+}
+
+// Enqueue queues a job called 'name' with 'payload' to run at 'runAt'
+func (r *Runner) Enqueue(ctx context.Context, name string, payload interface{}, runAt time.Time) (*model.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode payload of '%s' job, got error: %w", name, err)
+	}
+
+	job := &model.Job{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Payload:   data,
+		Status:    constant.JobPending,
+		RunAt:     runAt,
+		CreatedAt: time.Now(),
+	}
+	if err := r.queue.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("could not queue '%s' job, got error: %w", name, err)
+	}
+	return job, nil
+}
+
+// Tick queues the scheduled jobs due at 'now', then starts as many jobs due at 'now' as there are
+// free slots and returns how many were started. Nothing is started once the runner is draining
+func (r *Runner) Tick(ctx context.Context, now time.Time) (int, error) {
+	// The tick counts as running, so that Drain waits for the jobs it starts
+	r.mu.Lock()
+	if r.draining {
+		r.mu.Unlock()
+		return 0, nil
+	}
+	r.running.Add(1)
+	r.mu.Unlock()
+	defer r.running.Done()
+
+	if err := r.schedule(ctx, now); err != nil {
+		return 0, err
+	}
+
+	free := cap(r.slots) - len(r.slots)
+	if free == 0 {
+		return 0, nil
+	}
+
+	// Jobs are leased for longer than they may run, a replica that stops while running them
+	// leaves them to be retried once the lease is over
+	jobs, err := r.queue.ClaimJobs(ctx, now, r.timeout+time.Minute, free)
+	if err != nil {
+		return 0, fmt.Errorf("could not claim jobs, got error: %w", err)
+	}
+
+	for i := range jobs {
+		r.slots <- struct{}{}
+		r.running.Add(1)
+		go func(job *model.Job) {
+			defer r.running.Done()
+			defer func() { <-r.slots }()
+			r.run(job, now)
+		}(&jobs[i])
+	}
+	return len(jobs), nil
+}
+
+// Run ticks every 'interval' until 'ctx' is done, running jobs are left for Drain to wait for
+func (r *Runner) Run(ctx context.Context, logger *log.Logger, interval time.Duration) {
+	retry.Poll(ctx, interval, 0, r.Tick, func(err error) {
+		logger.Errorf("job runner failed: %s", err)
+	})
+}
+
+// Drain stops starting jobs and waits for the running ones. If 'ctx' is done first, they are
+// cancelled and will be retried
+func (r *Runner) Drain(ctx context.Context) error {
+	r.mu.Lock()
+	r.draining = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.cancelJobs()
+		return fmt.Errorf("jobs are still running: %w", ctx.Err())
+	}
+}
+
+// schedule queues the scheduled jobs due at 'now'. Due times missed while no replica was running
+// are skipped, but the latest one
+func (r *Runner) schedule(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.schedules {
+		if s.next.IsZero() {
+			s.next = s.schedule.Next(now)
+		}
+		if s.next.After(now) {
+			continue
+		}
+
+		job := &model.Job{
+			ID:        fmt.Sprintf("%s@%d", s.name, s.next.Unix()),
+			Name:      s.name,
+			Payload:   json.RawMessage("{}"),
+			Status:    constant.JobPending,
+			RunAt:     s.next,
+			CreatedAt: now,
+		}
+		if err := r.queue.CreateJob(ctx, job); err != nil {
+			return fmt.Errorf("could not queue '%s' job, got error: %w", s.name, err)
+		}
+		s.next = s.schedule.Next(now)
+	}
+	return nil
+}
+
+// run attempts 'job' once and stores the outcome
+func (r *Runner) run(job *model.Job, now time.Time) {
+	ctx, cancel := context.WithTimeout(r.jobCtx, r.timeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "jobs.Run", attribute.String("job.name", job.Name), attribute.String("job.id", job.ID))
+
+	r.mu.Lock()
+	reg, ok := r.handlers[job.Name]
+	r.mu.Unlock()
+
+	if ok && reg.options.Singleton {
+		acquired, err := r.queue.AcquireLease(ctx, job.Name, r.holder, now, now.Add(r.timeout+time.Minute))
+		if err != nil || !acquired {
+			// Another replica runs a job with this name, waiting for it does not use an attempt
+			job.RunAt = now.Add(leaseRetry)
+			tracing.End(span, err)
+			r.update(ctx, job)
+			return
+		}
+		defer r.queue.ReleaseLease(utility.Detach(ctx), job.Name, r.holder)
+	}
+
+	job.Attempts++
+	var err error
+	if ok {
+		err = call(ctx, reg.handler, job)
+	} else {
+		err = retry.Permanent(fmt.Errorf("no handler for '%s' jobs", job.Name))
+	}
+	tracing.End(span, err)
+
+	policy := retry.Policy{Base: baseBackoff, Max: maxBackoff, MaxAttempts: reg.options.MaxAttempts}
+	outcome, retryAt := policy.Next(job.Attempts, err, now)
+	switch outcome {
+	case retry.Succeeded:
+		finished := time.Now()
+		job.Status = constant.JobSucceeded
+		job.Error = ""
+		job.FinishedAt = &finished
+	case retry.Failed:
+		finished := time.Now()
+		job.Status = constant.JobFailed
+		job.Error = err.Error()
+		job.FinishedAt = &finished
+	default:
+		job.Error = err.Error()
+		job.RunAt = retryAt
+	}
+	metrics.Jobs.WithLabelValues(job.Name, outcome).Inc()
+	if err != nil {
+		logging.FromContext(ctx).Errorf("job '%s' failed on attempt %d, got error: %s", job.ID, job.Attempts, err)
+	}
+
+	r.update(ctx, job)
+}
+
+// update stores 'job', even if the runner is stopping
+func (r *Runner) update(ctx context.Context, job *model.Job) {
+	if err := r.queue.UpdateJob(utility.Detach(ctx), job); err != nil {
+		logging.FromContext(ctx).Errorf("could not update job '%s', got error: %s", job.ID, err)
+	}
+}
+
+// call runs 'handler' on 'job', a panic is recovered as an error so that it cannot take the
+// runner down
+func call(ctx context.Context, handler Handler, job *model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,213 @@
+// build+ unit
+package jobs_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/jobs"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTick(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Job      string
+		Attempts int // failed attempts before this one
+		Err      error
+		Panic    bool
+		Expected string
+		RunAt    time.Duration
+		Error    string
+	}{
+		{"Succeeded", "test.job", 0, nil, false, constant.JobSucceeded, 0, ""},
+		{"Retried", "test.job", 0, errors.New("unavailable"), false, constant.JobPending, 30 * time.Second, "unavailable"},
+		{"Backed_Off", "test.job", 1, errors.New("unavailable"), false, constant.JobPending, time.Minute, "unavailable"},
+		{"Out_Of_Attempts", "test.job", 2, errors.New("unavailable"), false, constant.JobFailed, 0, "unavailable"},
+		{"Panicked", "test.job", 0, nil, true, constant.JobPending, 30 * time.Second, "job panicked: nil map"},
+		{"Unknown_Job", "test.unknown", 0, nil, false, constant.JobFailed, 0, "no handler for 'test.unknown' jobs"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			queue := jobs.NewMemoryQueue()
+			runner := jobs.NewRunner(queue, 2, time.Second)
+			runner.Register("test.job", jobs.Options{MaxAttempts: 3}, func(ctx context.Context, job *model.Job) error {
+				if string(job.Payload) != `{"url_id":"url-id"}` {
+					t.Errorf("Expected the queued payload, got '%s'", job.Payload)
+				}
+				if test.Panic {
+					panic("nil map")
+				}
+				return test.Err
+			})
+
+			now := time.Now()
+			job, err := runner.Enqueue(context.Background(), test.Job, map[string]string{"url_id": "url-id"}, now)
+			if err != nil {
+				t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+			}
+			job.Attempts = test.Attempts
+			queue.UpdateJob(context.Background(), job)
+
+			if n, err := runner.Tick(context.Background(), now); n != 1 || err != nil {
+				t.Fatalf("Expected '1' job, got '%d' and '%v'", n, err)
+			}
+			if err := runner.Drain(context.Background()); err != nil {
+				t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+			}
+
+			stored := queue.Jobs()[0]
+			if stored.Status != test.Expected || stored.Attempts != test.Attempts+1 {
+				t.Errorf("Expected '%s' after attempt '%d', got '%+v'", test.Expected, test.Attempts+1, stored)
+			}
+			if stored.Error != test.Error {
+				t.Errorf("Expected '%s', got '%s'", test.Error, stored.Error)
+			}
+			if test.RunAt > 0 && !stored.RunAt.Equal(now.Add(test.RunAt)) {
+				t.Errorf("Expected next attempt in '%s', got '%s'", test.RunAt, stored.RunAt.Sub(now))
+			}
+			if (stored.FinishedAt != nil) != (test.Expected != constant.JobPending) {
+				t.Errorf("Expected 'finished_at' to be set once the job is done, got '%v'", stored.FinishedAt)
+			}
+		})
+	}
+}
+
+func TestSingleton(t *testing.T) {
+	queue := jobs.NewMemoryQueue()
+	started, release := make(chan struct{}), make(chan struct{})
+	var runs int32
+
+	runners := make([]*jobs.Runner, 2)
+	for i := range runners {
+		runners[i] = jobs.NewRunner(queue, 1, time.Second)
+		runners[i].Register("test.sweep", jobs.Options{Singleton: true}, func(ctx context.Context, job *model.Job) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				close(started)
+				<-release
+			}
+			return nil
+		})
+	}
+
+	now := time.Now()
+	first, _ := runners[0].Enqueue(context.Background(), "test.sweep", nil, now)
+	runners[0].Tick(context.Background(), now)
+	<-started
+
+	second, _ := runners[1].Enqueue(context.Background(), "test.sweep", nil, now)
+	runners[1].Tick(context.Background(), now)
+	runners[1].Drain(context.Background())
+
+	t.Run("Waiting", func(t *testing.T) {
+		for _, job := range queue.Jobs() {
+			if job.ID == second.ID && (job.Status != constant.JobPending || job.Attempts != 0 || !job.RunAt.After(now)) {
+				t.Errorf("Expected the second job to wait without using an attempt, got '%+v'", job)
+			}
+		}
+	})
+
+	close(release)
+	runners[0].Drain(context.Background())
+
+	t.Run("Released", func(t *testing.T) {
+		runner := jobs.NewRunner(queue, 1, time.Second)
+		runner.Register("test.sweep", jobs.Options{Singleton: true}, func(ctx context.Context, job *model.Job) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+		runner.Tick(context.Background(), now.Add(time.Hour))
+		runner.Drain(context.Background())
+
+		for _, job := range queue.Jobs() {
+			if job.Status != constant.JobSucceeded {
+				t.Errorf("Expected '%s' and '%s' to succeed, got '%+v'", first.ID, second.ID, job)
+			}
+		}
+		if atomic.LoadInt32(&runs) != 2 {
+			t.Errorf("Expected '2' runs, got '%d'", runs)
+		}
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	queue := jobs.NewMemoryQueue()
+	var runs int32
+
+	// Two replicas with the same schedule
+	runners := make([]*jobs.Runner, 2)
+	for i := range runners {
+		runners[i] = jobs.NewRunner(queue, 1, time.Second)
+		runners[i].Register("test.report", jobs.Options{}, func(ctx context.Context, job *model.Job) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+		if err := runners[i].Schedule("test.report", "@every 10m"); err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+	}
+
+	now := time.Date(2024, time.January, 31, 10, 17, 0, 0, time.UTC)
+	for _, tick := range []time.Time{now, now.Add(time.Minute), now.Add(3 * time.Minute), now.Add(4 * time.Minute)} {
+		for _, runner := range runners {
+			runner.Tick(context.Background(), tick)
+		}
+	}
+	for _, runner := range runners {
+		runner.Drain(context.Background())
+	}
+
+	stored := queue.Jobs()
+	if len(stored) != 1 || stored[0].ID != "test.report@1706696400" || stored[0].Status != constant.JobSucceeded {
+		t.Errorf("Expected one job due at 10:20, got '%+v'", stored)
+	}
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Errorf("Expected '1' run, got '%d'", runs)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	queue := jobs.NewMemoryQueue()
+	runner := jobs.NewRunner(queue, 1, time.Minute)
+	started := make(chan struct{})
+	runner.Register("test.export", jobs.Options{}, func(ctx context.Context, job *model.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	now := time.Now()
+	runner.Enqueue(context.Background(), "test.export", nil, now)
+	runner.Enqueue(context.Background(), "test.export", nil, now)
+	runner.Tick(context.Background(), now)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := runner.Drain(ctx); err == nil {
+		t.Fatalf("Expected 'error' to be not nil while a job is running")
+	}
+
+	// The cancelled job stores its outcome, no job is started anymore
+	runner.Drain(context.Background())
+	if n, _ := runner.Tick(context.Background(), now); n != 0 {
+		t.Errorf("Expected '0' jobs once draining, got '%d'", n)
+	}
+
+	var cancelled, waiting int
+	for _, job := range queue.Jobs() {
+		switch {
+		case job.Attempts == 1 && job.Status == constant.JobPending && job.Error == context.Canceled.Error():
+			cancelled++
+		case job.Attempts == 0 && job.Status == constant.JobPending:
+			waiting++
+		}
+	}
+	if cancelled != 1 || waiting != 1 {
+		t.Errorf("Expected a cancelled job to be retried and one to wait, got '%+v'", queue.Jobs())
+	}
+}
@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory is a queue keeping jobs and leases in memory, for tests and single replica setups. Its
+// jobs are lost when the process stops
+type Memory struct {
+	mu     sync.Mutex
+	jobs   map[string]model.Job
+	leases map[string]model.JobLease
+}
+
+// NewMemoryQueue returns an empty in-memory queue
+func NewMemoryQueue() *Memory {
+	return &Memory{jobs: map[string]model.Job{}, leases: map[string]model.JobLease{}}
+}
+
+func (m *Memory) CreateJob(ctx context.Context, job *model.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.jobs[job.ID]; !ok {
+		m.jobs[job.ID] = *job
+	}
+	return nil
+}
+
+func (m *Memory) ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []model.Job
+	for _, job := range m.jobs {
+		if job.Status == constant.JobPending && !job.RunAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, job := range due {
+		job.RunAt = now.Add(lease)
+		m.jobs[job.ID] = job
+	}
+	return due, nil
+}
+
+func (m *Memory) UpdateJob(ctx context.Context, job *model.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *Memory) AcquireLease(ctx context.Context, name, holder string, now, expiresAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, ok := m.leases[name]; ok && lease.Holder != holder && lease.ExpiresAt.After(now) {
+		return false, nil
+	}
+	m.leases[name] = model.JobLease{Name: name, Holder: holder, ExpiresAt: expiresAt}
+	return true, nil
+}
+
+func (m *Memory) ReleaseLease(ctx context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, ok := m.leases[name]; ok && lease.Holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+// Jobs returns every job of the queue, oldest first
+func (m *Memory) Jobs() []model.Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]model.Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].ID < jobs[j].ID
+		}
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
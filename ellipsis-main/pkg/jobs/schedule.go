@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule tells when a scheduled job is due
+type Schedule interface {
+	// Next returns the first time the job is due after 'after', or the zero time if it never is
+	Next(after time.Time) time.Time
+}
+
+// descriptors are the shorthands accepted in place of a cron expression
+var descriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseSchedule parses a cron expression, 'minute hour day-of-month month day-of-week' in UTC.
+// Each field is '*', a value, a range 'a-b', a step '*/n' or 'a-b/n', or a list of those. Days of
+// the week go from 0, Sunday, to 6. '@hourly', '@daily', '@weekly', '@monthly' and '@every <duration>'
+// are accepted too, the latter being due at multiples of the duration since the zero time
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil || interval < time.Minute {
+			return nil, fmt.Errorf("invalid schedule '%s', the interval must be at least a minute", spec)
+		}
+		return every(interval), nil
+	}
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule '%s', expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var c cron
+	var err error
+	for i, f := range []struct {
+		bits   *uint64
+		lo, hi int
+	}{{&c.minute, 0, 59}, {&c.hour, 0, 23}, {&c.dom, 1, 31}, {&c.month, 1, 12}, {&c.dow, 0, 6}} {
+		if *f.bits, err = parseField(fields[i], f.lo, f.hi); err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s', %w", spec, err)
+		}
+	}
+	c.anyDay = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")
+
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid schedule '%s', it is never due", spec)
+	}
+	return &c, nil
+}
+
+// parseField returns the values of a field between 'lo' and 'hi' as a bit set
+func parseField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step '%s'", part)
+			}
+		}
+
+		start, end := lo, hi
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err1, err2 error
+			start, err1 = strconv.Atoi(first)
+			end, err2 = start, nil
+			if isRange {
+				end, err2 = strconv.Atoi(last)
+			} else if hasStep {
+				end = hi
+			}
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid value '%s'", part)
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", part, lo, hi)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// anyDay is set when either day field is unrestricted, a day must then match both fields
+	// rather than either of them
+	anyDay bool
+}
+
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	// Schedules that are due at all are due within 5 years, leap days included
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	return after.UTC().Truncate(time.Duration(e)).Add(time.Duration(e))
+}
@@ -0,0 +1,52 @@
+// build+ unit
+package jobs_test
+
+import (
+	"brief/pkg/jobs"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// A Wednesday
+	after := time.Date(2024, time.January, 31, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		Name     string
+		Spec     string
+		Expected time.Time
+		IsError  bool
+	}{
+		{"Every_Minute", "* * * * *", time.Date(2024, time.January, 31, 10, 18, 0, 0, time.UTC), false},
+		{"Hourly", "@hourly", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC), false},
+		{"Daily", "@daily", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), false},
+		{"Step", "*/15 * * * *", time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC), false},
+		{"Range_Step", "5-50/20 9-17 * * *", time.Date(2024, time.January, 31, 10, 25, 0, 0, time.UTC), false},
+		{"List", "0 8,20 * * *", time.Date(2024, time.January, 31, 20, 0, 0, 0, time.UTC), false},
+		{"Weekday", "30 9 * * 1-5", time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC), false},
+		{"Day_Or_Weekday", "0 0 15 * 5", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC), false},
+		{"Leap_Day", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), false},
+		{"Every", "@every 10m", time.Date(2024, time.January, 31, 10, 20, 0, 0, time.UTC), false},
+		{"Never_Due", "0 0 30 2 *", time.Time{}, true},
+		{"Out_Of_Range", "60 * * * *", time.Time{}, true},
+		{"Missing_Field", "* * * *", time.Time{}, true},
+		{"Invalid_Step", "*/0 * * * *", time.Time{}, true},
+		{"Short_Interval", "@every 10s", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			schedule, err := jobs.ParseSchedule(test.Spec)
+			if (err != nil) != test.IsError {
+				t.Fatalf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if next := schedule.Next(after); !next.Equal(test.Expected) {
+				t.Errorf("Expected '%s', got '%s'", test.Expected, next)
+			}
+		})
+	}
+}
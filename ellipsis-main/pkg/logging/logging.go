@@ -0,0 +1,107 @@
+// Package logging configures the service's single logger from 'LOG_FORMAT', 'LOG_LEVEL' and
+// 'LOG_FILE', and carries the correlation id of a request in its context
+package logging
+
+import (
+	"brief/internal/config"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Formats of log lines
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Rotation defaults of the log file
+const (
+	defaultMaxSize    = 100 // megabytes
+	defaultMaxBackups = 5
+	defaultMaxAge     = 28 // days
+)
+
+// Setup configures the standard logger, so that package level log calls and the logger injected
+// into handlers and services are the same, and returns it
+func Setup() (*log.Logger, error) {
+	getConfig := config.GetConfig()
+	logger := log.StandardLogger()
+
+	switch strings.ToLower(getConfig.LogFormat) {
+	case FormatJSON, "":
+		logger.SetFormatter(&log.JSONFormatter{})
+	case FormatText:
+		logger.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("unknown log format '%s', expected json or text", getConfig.LogFormat)
+	}
+
+	level := log.InfoLevel
+	if getConfig.LogLevel != "" {
+		var err error
+		if level, err = log.ParseLevel(getConfig.LogLevel); err != nil {
+			return nil, fmt.Errorf("invalid log level '%s', got error: %w", getConfig.LogLevel, err)
+		}
+	}
+	logger.SetLevel(level)
+
+	logger.SetOutput(output(getConfig))
+	logger.ReplaceHooks(log.LevelHooks{})
+	logger.AddHook(Redactor{})
+
+	return logger, nil
+}
+
+// output returns the rotated log file when one is configured, stdout otherwise
+func output(getConfig *config.Configuration) io.Writer {
+	if getConfig.LogFile == "" {
+		return os.Stdout
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   getConfig.LogFile,
+		MaxSize:    defaultMaxSize,
+		MaxBackups: defaultMaxBackups,
+		MaxAge:     defaultMaxAge,
+		Compress:   true,
+	}
+	if getConfig.LogMaxSize > 0 {
+		file.MaxSize = getConfig.LogMaxSize
+	}
+	if getConfig.LogMaxBackups > 0 {
+		file.MaxBackups = getConfig.LogMaxBackups
+	}
+	if getConfig.LogMaxAge > 0 {
+		file.MaxAge = getConfig.LogMaxAge
+	}
+	return file
+}
+
+// requestIDKey is the key the correlation id of a request is stored under
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of 'ctx' carrying the correlation id of a request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the correlation id stored in 'ctx', or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns a log entry of the standard logger tagged with the request id in 'ctx'
+func FromContext(ctx context.Context) *log.Entry {
+	entry := log.NewEntry(log.StandardLogger())
+	if id := RequestID(ctx); id != "" {
+		entry = entry.WithField("request_id", id)
+	}
+	return entry
+}
@@ -0,0 +1,112 @@
+// build+ unit
+package logging_test
+
+import (
+	"brief/internal/config"
+	"brief/pkg/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Config  config.Configuration
+		Level   log.Level
+		IsError bool
+	}{
+		{"Defaults", config.Configuration{}, log.InfoLevel, false},
+		{"Text_Debug", config.Configuration{LogFormat: "text", LogLevel: "debug"}, log.DebugLevel, false},
+		{"Unknown_Format", config.Configuration{LogFormat: "xml"}, 0, true},
+		{"Unknown_Level", config.Configuration{LogLevel: "loud"}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cfg := test.Config
+			config.Config = &cfg
+
+			logger, err := logging.Setup()
+			if test.IsError {
+				if err == nil {
+					t.Errorf("Expected 'error' to be not nil, got '%v'", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+			}
+			if logger.GetLevel() != test.Level {
+				t.Errorf("Expected '%v', got '%v'", test.Level, logger.GetLevel())
+			}
+		})
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	config.Config = &config.Configuration{}
+	logger, err := logging.Setup()
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	ctx := logging.WithRequestID(context.Background(), "request-1")
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"email":         "user@mail.com",
+		"password":      "hunter2",
+		"Authorization": "Bearer abc",
+		"reset_token":   "xyz",
+	}).Info("test")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a JSON log line, got '%s'", buf.String())
+	}
+
+	tests := []struct {
+		Field    string
+		Expected string
+	}{
+		{"email", "user@mail.com"},
+		{"request_id", "request-1"},
+		{"password", logging.Redacted},
+		{"Authorization", logging.Redacted},
+		{"reset_token", logging.Redacted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Field, func(t *testing.T) {
+			if line[test.Field] != test.Expected {
+				t.Errorf("Expected '%s', got '%v'", test.Expected, line[test.Field])
+			}
+		})
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Query    string
+		Expected string
+	}{
+		{"Nothing_Sensitive", "page=2&workspace_id=ws", "page=2&workspace_id=ws"},
+		{"Token", "token=abc&page=2", "page=2&token=%5BREDACTED%5D"},
+		{"API_Key", "api_key=abc", "api_key=%5BREDACTED%5D"},
+		{"Malformed", "%zz", logging.Redacted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if got := logging.RedactQuery(test.Query); got != test.Expected {
+				t.Errorf("Expected '%s', got '%s'", test.Expected, got)
+			}
+		})
+	}
+}
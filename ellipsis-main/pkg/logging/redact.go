@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the value of a sensitive field
+const Redacted = "[REDACTED]"
+
+// sensitive are the fragments of field and query parameter names whose values are never logged
+var sensitive = []string{"password", "secret", "token", "authorization", "cookie", "salt", "api_key", "apikey"}
+
+// Sensitive reports whether values named 'key' must be redacted
+func Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range sensitive {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redactor is a hook replacing the values of sensitive fields before an entry is written
+type Redactor struct{}
+
+func (Redactor) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (Redactor) Fire(entry *log.Entry) error {
+	data := make(log.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if Sensitive(key) {
+			value = Redacted
+		}
+		data[key] = value
+	}
+	entry.Data = data
+	return nil
+}
+
+// RedactQuery returns the query string 'rawQuery' with the values of sensitive parameters redacted
+func RedactQuery(rawQuery string) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+
+	for key := range query {
+		if Sensitive(key) {
+			query[key] = []string{Redacted}
+		}
+	}
+	return query.Encode()
+}
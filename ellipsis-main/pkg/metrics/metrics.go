@@ -0,0 +1,123 @@
+// Package metrics holds the Prometheus collectors of the service, they are exposed by Handler
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "brief"
+
+var (
+	// HTTPRequests counts requests by route pattern, e.g. /api/v1/url/{id}, method and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPDuration observes the latency of requests by route pattern, method and status
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route pattern, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// Redirects counts short links that were found (hit) or not (miss)
+	Redirects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Redirects of short links by result, hit or miss.",
+	}, []string{"result"})
+
+	// HashCollisions counts the hashes generated by Shorten that were already taken
+	HashCollisions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hash_collisions_total",
+		Help:      "Generated hashes that collided with an existing one and were retried.",
+	})
+
+	// DBQueryDuration observes the duration of database queries by operation and table
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database queries by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// CacheRequests counts cache lookups by cache and result, the hit ratio is
+	// rate(hit) / rate(hit + miss)
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache and result, hit or miss.",
+	}, []string{"cache", "result"})
+
+	// WebhookDeliveries counts attempts at delivering webhook events by event and outcome,
+	// succeeded, retried or failed
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Attempts at delivering webhook events by event and outcome, succeeded, retried or failed.",
+	}, []string{"event", "outcome"})
+
+	// EventFailures counts domain events a subscriber of the event bus failed to handle, by
+	// event and subscriber
+	EventFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_failures_total",
+		Help:      "Domain events a subscriber of the event bus failed to handle, by event and subscriber.",
+	}, []string{"event", "subscriber"})
+
+	// Jobs counts attempts at running background jobs by job and outcome, succeeded, retried
+	// or failed
+	Jobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_total",
+		Help:      "Attempts at running background jobs by job and outcome, succeeded, retried or failed.",
+	}, []string{"job", "outcome"})
+
+	// LinkChecks counts requests to the destinations of urls by the link checker, by result, ok or
+	// failed
+	LinkChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "link_checks_total",
+		Help:      "Requests to the destinations of urls by the link checker, by result, ok or failed.",
+	}, []string{"result"})
+)
+
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		Redirects,
+		HashCollisions,
+		DBQueryDuration,
+		CacheRequests,
+		WebhookDeliveries,
+		EventFailures,
+		Jobs,
+		LinkChecks,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Result returns the label of a lookup that found what it looked for or not
+func Result(hit bool) string {
+	if hit {
+		return "hit"
+	}
+	return "miss"
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"brief/pkg/logging"
+	"brief/utility"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the correlation id of a request, from the caller and back in the response
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID restricts the ids accepted from callers to what is safe to log and echo
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID gives every request a correlation id, the caller's X-Request-ID if it sent a valid
+// one and a new id otherwise. The id is stored in the request's context and echoed in the response
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// Logger logs a structured line for every request with 'logger'. Sensitive query parameters and
+// path parameters are redacted
+func Logger(logger *log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			fields := log.Fields{
+				"request_id":  logging.RequestID(r.Context()),
+				"method":      r.Method,
+				"path":        redactPath(r),
+				"status":      status,
+				"bytes":       ww.BytesWritten(),
+				"duration_ms": time.Since(start).Milliseconds(),
+				"remote_ip":   utility.RemoteIP(r),
+				"user_agent":  r.UserAgent(),
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				fields["route"] = rctx.RoutePattern()
+			}
+			if r.URL.RawQuery != "" {
+				fields["query"] = logging.RedactQuery(r.URL.RawQuery)
+			}
+			if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+				fields["trace_id"] = span.TraceID().String()
+			}
+
+			entry := logger.WithFields(fields)
+			switch {
+			case status >= http.StatusInternalServerError:
+				entry.Error("request failed")
+			case status >= http.StatusBadRequest:
+				entry.Warn("request rejected")
+			default:
+				entry.Info("request served")
+			}
+		})
+	}
+}
+
+// redactPath returns the path of 'r' with the values of sensitive route parameters, such as
+// invitation tokens, redacted
+func redactPath(r *http.Request) string {
+	path := r.URL.Path
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return path
+	}
+
+	for i, key := range rctx.URLParams.Keys {
+		if logging.Sensitive(key) && rctx.URLParams.Values[i] != "" {
+			path = strings.ReplaceAll(path, rctx.URLParams.Values[i], logging.Redacted)
+		}
+	}
+	return path
+}
@@ -0,0 +1,101 @@
+// build+ unit
+package middleware_test
+
+import (
+	"brief/pkg/logging"
+	mdw "brief/pkg/middleware"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRequestID(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(mdw.RequestID)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(logging.RequestID(r.Context())))
+	})
+
+	tests := []struct {
+		Name     string
+		Header   string
+		Accepted bool
+	}{
+		{"Generated", "", false},
+		{"Accepted", "caller-id.42", true},
+		{"Too_Long", strings.Repeat("a", 129), false},
+		{"Unsafe_Characters", "id\nforged=1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(mdw.RequestIDHeader, test.Header)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(mdw.RequestIDHeader)
+			if id == "" {
+				t.Fatalf("Expected a request id in the response, got none")
+			}
+			if id != rec.Body.String() {
+				t.Errorf("Expected '%s', got '%s'", id, rec.Body.String())
+			}
+			if accepted := id == test.Header; accepted != test.Accepted {
+				t.Errorf("Expected '%v', got '%v'", test.Accepted, accepted)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.SetFormatter(&log.JSONFormatter{})
+	logger.SetOutput(&buf)
+
+	r := chi.NewRouter()
+	r.Use(mdw.RequestID)
+	r.Use(mdw.Logger(logger))
+	r.Post("/workspaces/invitations/{token}/accept", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/workspaces/invitations/secret-invite/accept?token=abc", nil)
+	req.Header.Set(mdw.RequestIDHeader, "caller-id")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "secret-invite") || strings.Contains(buf.String(), "abc") {
+		t.Errorf("Expected sensitive values to be redacted, got '%s'", buf.String())
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a JSON log line, got '%s'", buf.String())
+	}
+
+	tests := []struct {
+		Field    string
+		Expected interface{}
+	}{
+		{"request_id", "caller-id"},
+		{"route", "/workspaces/invitations/{token}/accept"},
+		{"path", "/workspaces/invitations/[REDACTED]/accept"},
+		{"status", float64(http.StatusNotFound)},
+		{"level", "warning"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Field, func(t *testing.T) {
+			if line[test.Field] != test.Expected {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, line[test.Field])
+			}
+		})
+	}
+}
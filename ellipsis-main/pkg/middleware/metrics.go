@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"brief/internal/apperror"
+	"brief/pkg/metrics"
+	"brief/utility"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Metrics records the count and latency of requests by route pattern, method and status
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		// The pattern is only known once the request has been routed. Unmatched paths share a
+		// label so that scanners cannot create a series per path
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := []string{route, r.Method, strconv.Itoa(status)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// RequireToken is the middleware for endpoints protected by a static 'token', sent as a bearer
+// token. Requests pass through when no token is configured
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" && subtle.ConstantTimeCompare([]byte(getToken(r)), []byte(token)) != 1 {
+				utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "invalid token"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// build+ unit
+package middleware_test
+
+import (
+	"brief/pkg/metrics"
+	mdw "brief/pkg/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(mdw.Metrics)
+	r.Get("/url/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		Name   string
+		Path   string
+		Route  string
+		Status string
+	}{
+		{"Route_Pattern", "/url/some-id", "/url/{id}", "418"},
+		{"Unmatched", "/wp-login.php", "unmatched", "404"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			counter := metrics.HTTPRequests.WithLabelValues(test.Route, http.MethodGet, test.Status)
+			before := testutil.ToFloat64(counter)
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.Path, nil))
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("Expected '1' request counted, got '%v'", got)
+			}
+		})
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Token    string
+		Header   string
+		Expected int
+	}{
+		{"No_Token_Configured", "", "", http.StatusOK},
+		{"Valid_Token", "secret", "Bearer secret", http.StatusOK},
+		{"Invalid_Token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"Missing_Token", "secret", "", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			handler := mdw.RequireToken(test.Token)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if test.Header != "" {
+				req.Header.Set("Authorization", test.Header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.Expected {
+				t.Errorf("Expected '%d', got '%d'", test.Expected, rec.Code)
+			}
+		})
+	}
+}
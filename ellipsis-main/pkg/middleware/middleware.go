@@ -1,120 +1,82 @@
 package middleware
 
 import (
-	"brief/internal/constant"
+	"brief/internal/apperror"
 	"brief/internal/model"
 	"brief/utility"
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
-)
-
-// Admin is the middleware for admin-only endpoints
-func Admin(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		token := getToken(r)
-		if token == "" {
-			rd := utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
-				constant.ErrUnauthorized, "no token specified", nil)
-			res, _ := json.Marshal(rd)
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write(res)
-			return
-		}
+	"gorm.io/gorm"
+)
 
-		claims, err := VerifyToken(token)
-		if err != nil {
-			rd := utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
-				constant.ErrUnauthorized, err.Error(), nil)
-			res, _ := json.Marshal(rd)
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write(res)
-			return
-		}
+// Users fetches users, authenticated requests carry the role they currently have in it
+type Users interface {
+	GetUser(ctx context.Context, idOrEmail string) (*model.User, error)
+}
 
-		// Check if request is from an admin
-		if claims.Role != constant.Roles[constant.Admin] {
-			rd := utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
-				constant.ErrUnauthorized, "cannot access this endpoint", nil)
-			res, _ := json.Marshal(rd)
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write(res)
-			return
-		}
+var users Users
 
-		// Set details from token into context and execute next handler
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, struct{}{}, &model.ContextInfo{
-			ID:    claims.ID,
-			Role:  claims.Role,
-			Email: claims.Email,
-		})
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// SetUsers makes authenticated requests carry the role users currently have rather than the one
+// in their token, so that a role change takes effect right away
+func SetUsers(u Users) {
+	users = u
 }
 
 // Me is the middleware for user endpoints
 func Me(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		token := getToken(r)
-		if token == "" {
-			rd := utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
-				constant.ErrUnauthorized, "no token specified", nil)
-			res, _ := json.Marshal(rd)
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write(res)
-			return
-		}
-
-		claims, err := VerifyToken(token)
-		if err != nil {
-			rd := utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
-				constant.ErrUnauthorized, err.Error(), nil)
-			res, _ := json.Marshal(rd)
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write(res)
+		claims := authenticate(w, r)
+		if claims == nil {
 			return
 		}
 
 		// Set details from token in context and execute next handler
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, struct{}{}, &model.ContextInfo{
-			ID:    claims.ID,
-			Role:  claims.Role,
-			Email: claims.Email,
-		})
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(withClaims(r, claims)))
 	})
 }
 
+// RequirePermission is the middleware for endpoints restricted to roles granted all of 'permissions'
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			claims := authenticate(w, r)
+			if claims == nil {
+				return
+			}
+
+			for _, permission := range permissions {
+				if !utility.HasPermission(claims.Role, permission) {
+					utility.WriteError(w, r, apperror.Forbidden(apperror.CodeForbidden, "missing permission '%s'", permission))
+					return
+				}
+			}
+
+			// Set details from token in context and execute next handler
+			next.ServeHTTP(w, r.WithContext(withClaims(r, claims)))
+		})
+	}
+}
+
 // Shorten is the middleware for the endpoint to shorten a url
 // /api/v1/url/shorten - POST
 func Shorten(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		// Get token if available
-		token := getToken(r)
-		if token != "" {
-			claims, err := VerifyToken(token)
-			if err != nil {
-				rd := utility.BuildErrorResponse(http.StatusUnauthorized, constant.StatusFailed,
-					constant.ErrUnauthorized, err.Error(), nil)
-				res, _ := json.Marshal(rd)
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write(res)
+		// Anonymous requests are allowed, a token is checked like on other endpoints
+		if getToken(r) != "" {
+			claims := authenticate(w, r)
+			if claims == nil {
 				return
 			}
 
 			// Set details from token in context and execute next handler
-			ctx = context.WithValue(ctx, struct{}{}, &model.ContextInfo{
-				ID:    claims.ID,
-				Role:  claims.Role,
-				Email: claims.Email,
-			})
+			ctx = withClaims(r, claims)
 
 		}
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -122,6 +84,64 @@ func Shorten(next http.Handler) http.Handler {
 
 }
 
+// authenticate verifies the token of a request and returns its claims, with the current role of
+// the user. A response is written and nil returned if the request is not authenticated
+func authenticate(w http.ResponseWriter, r *http.Request) *Claims {
+	token := getToken(r)
+	if token == "" {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "no token specified"))
+		return nil
+	}
+
+	claims, err := VerifyToken(token)
+	if err != nil {
+		utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "%s", err))
+		return nil
+	}
+
+	// The role may have changed since the token was issued
+	if users != nil {
+		user, err := users.GetUser(r.Context(), claims.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utility.WriteError(w, r, apperror.Unauthorized(apperror.CodeUnauthorized, "user does not exist"))
+				return nil
+			}
+			utility.WriteError(w, r, apperror.Internal(err, "could not fetch user"))
+			return nil
+		}
+		claims.Role = user.Role
+	}
+
+	return claims
+}
+
+// contextInfoKey is the key the details of an authenticated request are stored under
+type contextInfoKey struct{}
+
+// WithContextInfo returns a copy of 'ctx' carrying the details of the requesting user
+func WithContextInfo(ctx context.Context, ctxInfo *model.ContextInfo) context.Context {
+	return context.WithValue(ctx, contextInfoKey{}, ctxInfo)
+}
+
+// GetContextInfo returns the details of the requesting user stored in 'ctx', or nil if the
+// request is not authenticated
+func GetContextInfo(ctx context.Context) *model.ContextInfo {
+	ctxInfo, _ := ctx.Value(contextInfoKey{}).(*model.ContextInfo)
+	return ctxInfo
+}
+
+// withClaims returns the context of 'r' with the details from a token's 'claims' set in it
+func withClaims(r *http.Request, claims *Claims) context.Context {
+	return WithContextInfo(r.Context(), &model.ContextInfo{
+		ID:        claims.ID,
+		Role:      claims.Role,
+		Email:     claims.Email,
+		IP:        utility.RemoteIP(r),
+		UserAgent: r.UserAgent(),
+	})
+}
+
 // getToken contains logic to fetch token from headers
 func getToken(r *http.Request) (token string) {
 	auth := r.Header.Get("Authorization")
@@ -0,0 +1,136 @@
+// build+ unit
+package middleware_test
+
+import (
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	mdw "brief/pkg/middleware"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestContextInfo(t *testing.T) {
+	ctxInfo := &model.ContextInfo{ID: "test-id"}
+
+	tests := []struct {
+		Name     string
+		Ctx      context.Context
+		Expected *model.ContextInfo
+	}{
+		{"Authenticated", mdw.WithContextInfo(context.Background(), ctxInfo), ctxInfo},
+		{"Anonymous", context.Background(), nil},
+		// Values stored under other empty struct keys must not be mistaken for a user
+		{"Foreign_Key", context.WithValue(context.Background(), struct{}{}, ctxInfo), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if got := mdw.GetContextInfo(test.Ctx); got != test.Expected {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, got)
+			}
+		})
+	}
+}
+
+// users keeps the current role of users
+type users map[string]int
+
+func (u users) GetUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	role, ok := u[idOrEmail]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.User{ID: idOrEmail, Role: role}, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	config.Config = &config.Configuration{SecretKey: "test-secret"}
+	mdw.SetUsers(users{"admin-id": constant.Roles[constant.Admin], "demoted-id": constant.Roles[constant.User]})
+	defer mdw.SetUsers(nil)
+
+	handler := mdw.RequirePermission(constant.PermRoleAssign)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctxInfo := mdw.GetContextInfo(r.Context()); ctxInfo.Role != constant.Roles[constant.Admin] {
+			t.Errorf("Expected the current role in the context, got '%d'", ctxInfo.Role)
+		}
+	}))
+
+	tests := []struct {
+		Name     string
+		ID       string
+		Expected int
+	}{
+		{"Admin", "admin-id", http.StatusOK},
+		// The token still carries the admin role
+		{"Demoted", "demoted-id", http.StatusForbidden},
+		{"Deleted", "deleted-id", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			token, err := mdw.CreateToken(test.ID, test.ID, constant.Roles[constant.Admin])
+			if err != nil {
+				t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.Expected {
+				t.Errorf("Expected '%d', got '%d'", test.Expected, rec.Code)
+			}
+		})
+	}
+}
+
+func TestShorten(t *testing.T) {
+	config.Config = &config.Configuration{SecretKey: "test-secret"}
+	mdw.SetUsers(users{"demoted-id": constant.Roles[constant.User]})
+	defer mdw.SetUsers(nil)
+
+	var ctxInfo *model.ContextInfo
+	handler := mdw.Shorten(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxInfo = mdw.GetContextInfo(r.Context())
+	}))
+
+	tests := []struct {
+		Name     string
+		ID       string
+		Expected int
+		Role     int
+	}{
+		{"Anonymous", "", http.StatusOK, 0},
+		// The token still carries the admin role
+		{"Demoted", "demoted-id", http.StatusOK, constant.Roles[constant.User]},
+		{"Deleted", "deleted-id", http.StatusUnauthorized, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctxInfo = nil
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if test.ID != "" {
+				token, err := mdw.CreateToken(test.ID, test.ID, constant.Roles[constant.Admin])
+				if err != nil {
+					t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.Expected {
+				t.Errorf("Expected '%d', got '%d'", test.Expected, rec.Code)
+			}
+			if ctxInfo != nil && ctxInfo.Role != test.Role {
+				t.Errorf("Expected role '%d', got '%d'", test.Role, ctxInfo.Role)
+			}
+		})
+	}
+}
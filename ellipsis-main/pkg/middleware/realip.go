@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"brief/utility"
+	"net"
+	"net/http"
+)
+
+// RealIP sets the request's RemoteAddr to the address of the client, resolving
+// X-Forwarded-For only for requests coming through 'trustedProxies'
+func RealIP(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := utility.ClientIP(r, trustedProxies); ip != nil {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
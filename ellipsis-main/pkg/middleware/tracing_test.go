@@ -0,0 +1,71 @@
+// build+ unit
+package middleware_test
+
+import (
+	mdw "brief/pkg/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	r := chi.NewRouter()
+	r.Use(mdw.Tracing)
+	r.Get("/url/{id}", func(w http.ResponseWriter, r *http.Request) {
+		// Spans started by handlers are children of the request's span
+		if !trace.SpanContextFromContext(r.Context()).IsValid() {
+			t.Errorf("Expected a span in the request context, got none")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	tests := []struct {
+		Name   string
+		Path   string
+		Parent string
+		Span   string
+		Status codes.Code
+	}{
+		{"Route_Pattern", "/url/some-id", "", "GET /url/{id}", codes.Unset},
+		{"Server_Error", "/fail", "", "GET /fail", codes.Error},
+		{"Unmatched", "/wp-login.php", "", "GET /wp-login.php", codes.Unset},
+		{"Remote_Parent", "/url/some-id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "GET /url/{id}", codes.Unset},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.Path, nil)
+			if test.Parent != "" {
+				req.Header.Set("traceparent", test.Parent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			span := spans[len(spans)-1]
+			if span.Name() != test.Span {
+				t.Errorf("Expected '%s', got '%s'", test.Span, span.Name())
+			}
+			if span.Status().Code != test.Status {
+				t.Errorf("Expected '%v', got '%v'", test.Status, span.Status().Code)
+			}
+			if test.Parent != "" && span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("Expected '4bf92f3577b34da6a3ce929d0e0e4736', got '%s'", span.Parent().TraceID())
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// lockKey identifies the advisory lock held while migrating, so that only one instance
+// migrates the schema at a time
+const lockKey int64 = 0x6272696566 // "brief"
+
+var filePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change of the database schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration has been applied to the database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *log.Logger
+}
+
+func New(db *sql.DB, migrations []Migration, logger *log.Logger) *Migrator {
+	return &Migrator{db: db, migrations: migrations, logger: logger}
+}
+
+// Load reads the migrations in 'fsys', named '<version>_<name>.up.sql' and
+// '<version>_<name>.down.sql', ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		if version <= 0 {
+			return nil, fmt.Errorf("migration '%s' must have a version above 0", entry.Name())
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations '%s' and '%s' share version %d", m.Name, match[2], version)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Plan returns the migrations to apply, oldest first, and the migrations to roll back, latest
+// first, to bring a schema with the 'applied' versions to the 'target' version
+func Plan(migrations []Migration, applied map[int64]bool, target int64) (up []Migration, down []Migration, err error) {
+	known := map[int64]bool{}
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+	for version := range applied {
+		if !known[version] && version > target {
+			return nil, nil, fmt.Errorf("version %d is applied but unknown to this build, it cannot be rolled back", version)
+		}
+	}
+	if target != 0 && !known[target] {
+		return nil, nil, fmt.Errorf("unknown version %d", target)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= target && !applied[m.Version] {
+			up = append(up, m)
+		}
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if m := migrations[i]; m.Version > target && applied[m.Version] {
+			down = append(down, m)
+		}
+	}
+
+	return up, down, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the latest 'steps' applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+		var target int64
+		if steps < len(versions) {
+			target = versions[len(versions)-steps-1]
+		}
+
+		return m.migrate(ctx, conn, applied, target)
+	})
+}
+
+// To applies or rolls back migrations until 'version' is the latest applied one, 0 rolls back
+// every migration
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return m.migrate(ctx, conn, applied, version)
+	})
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			s := Status{Migration: migration}
+			if at, ok := applied[migration.Version]; ok {
+				s.Applied = true
+				s.AppliedAt = &at
+			}
+			statuses = append(statuses, s)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Pending lists the known migrations that have not been applied. Unlike Status it does not take
+// the migration lock, so that it can be polled while another instance migrates
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// migrate runs the migrations planned to reach 'target', each in its own transaction
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, applied map[int64]time.Time, target int64) error {
+	isApplied := make(map[int64]bool, len(applied))
+	for version := range applied {
+		isApplied[version] = true
+	}
+
+	up, down, err := Plan(m.migrations, isApplied, target)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range down {
+		if migration.Down == "" {
+			return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		if err := step(ctx, conn, migration.Down,
+			"DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+			return fmt.Errorf("could not roll back migration %d_%s, got error: %w", migration.Version, migration.Name, err)
+		}
+		m.logger.Infof("rolled back migration %d_%s", migration.Version, migration.Name)
+	}
+
+	for _, migration := range up {
+		if err := step(ctx, conn, migration.Up,
+			"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name); err != nil {
+			return fmt.Errorf("could not apply migration %d_%s, got error: %w", migration.Version, migration.Name, err)
+		}
+		m.logger.Infof("applied migration %d_%s", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+// withLock runs 'fn' on a connection holding the migration lock, creating the table of applied
+// migrations if needed
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Advisory locks belong to a session, they must be taken and released on the same connection
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("could not acquire migration lock, got error: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("could not create schema_migrations, got error: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions fetches the applied migrations and when they were applied
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+
+	return applied, rows.Err()
+}
+
+// step runs the statements of a migration and records it in a single transaction
+func step(ctx context.Context, conn *sql.Conn, statements, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,133 @@
+// build+ unit
+package migrate_test
+
+import (
+	"brief/pkg/migrate"
+	"brief/pkg/repository/storage/postgres/migrations"
+	"testing"
+	"testing/fstest"
+)
+
+func versions(migrations []migrate.Migration) []int64 {
+	var v []int64
+	for _, m := range migrations {
+		v = append(v, m.Version)
+	}
+	return v
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Ordered_By_Version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0002_add_name.up.sql":       {Data: []byte("ALTER TABLE t ADD name text")},
+			"0002_add_name.down.sql":     {Data: []byte("ALTER TABLE t DROP name")},
+			"0001_create_t.up.sql":       {Data: []byte("CREATE TABLE t (id text)")},
+			"0001_create_t.down.sql":     {Data: []byte("DROP TABLE t")},
+			"0010_no_down.up.sql":        {Data: []byte("SELECT 1")},
+			"README.md":                  {Data: []byte("not a migration")},
+			"0003_not_a_direction.sql":   {Data: []byte("SELECT 1")},
+			"0004_wrong_extension.up.go": {Data: []byte("SELECT 1")},
+		}
+
+		got, err := migrate.Load(fsys)
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		if v := versions(got); !equal(v, []int64{1, 2, 10}) {
+			t.Errorf("Expected '[1 2 10]', got '%v'", v)
+		}
+		if got[0].Name != "create_t" || got[0].Down != "DROP TABLE t" {
+			t.Errorf("Expected 'create_t' with its down file, got '%s' '%s'", got[0].Name, got[0].Down)
+		}
+	})
+
+	tests := []struct {
+		Name string
+		FS   fstest.MapFS
+	}{
+		{"Missing_Up", fstest.MapFS{"0001_create_t.down.sql": {Data: []byte("DROP TABLE t")}}},
+		{"Shared_Version", fstest.MapFS{
+			"0001_create_t.up.sql": {Data: []byte("CREATE TABLE t (id text)")},
+			"0001_create_u.up.sql": {Data: []byte("CREATE TABLE u (id text)")},
+		}},
+		{"Version_Zero", fstest.MapFS{"0000_create_t.up.sql": {Data: []byte("CREATE TABLE t (id text)")}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if _, err := migrate.Load(test.FS); err == nil {
+				t.Errorf("Expected 'error', got nil")
+			}
+		})
+	}
+
+	t.Run("Embedded_Migrations", func(t *testing.T) {
+		got, err := migrate.Load(migrations.FS)
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		for i, m := range got {
+			if m.Version != int64(i+1) {
+				t.Errorf("Expected version '%d', got '%d'", i+1, m.Version)
+			}
+			if m.Down == "" {
+				t.Errorf("Expected '%04d_%s' to have a down file", m.Version, m.Name)
+			}
+		}
+	})
+}
+
+func TestPlan(t *testing.T) {
+	all := []migrate.Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	tests := []struct {
+		Name    string
+		Applied map[int64]bool
+		Target  int64
+		Up      []int64
+		Down    []int64
+		Error   bool
+	}{
+		{"Up_From_Empty", map[int64]bool{}, 3, []int64{1, 2, 3}, nil, false},
+		{"Up_To_Date", map[int64]bool{1: true, 2: true, 3: true}, 3, nil, nil, false},
+		{"Up_Fills_Gap", map[int64]bool{1: true, 3: true}, 3, []int64{2}, nil, false},
+		{"Down_One", map[int64]bool{1: true, 2: true, 3: true}, 2, nil, []int64{3}, false},
+		{"Down_To_Zero", map[int64]bool{1: true, 2: true}, 0, nil, []int64{2, 1}, false},
+		{"Unknown_Target", map[int64]bool{}, 4, nil, nil, true},
+		{"Unknown_Applied_Above_Target", map[int64]bool{1: true, 4: true}, 3, nil, nil, true},
+		{"Unknown_Applied_Below_Target", map[int64]bool{1: true, 0: true}, 2, []int64{2}, nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			up, down, err := migrate.Plan(all, test.Applied, test.Target)
+			if test.Error {
+				if err == nil {
+					t.Errorf("Expected 'error', got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+			}
+			if v := versions(up); !equal(v, test.Up) {
+				t.Errorf("Expected up '%v', got '%v'", test.Up, v)
+			}
+			if v := versions(down); !equal(v, test.Down) {
+				t.Errorf("Expected down '%v', got '%v'", test.Down, v)
+			}
+		})
+	}
+}
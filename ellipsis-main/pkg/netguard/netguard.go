@@ -0,0 +1,82 @@
+package netguard
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	urlPkg "net/url"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, 100.64.0.0/10, which is not public either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// CheckIP returns an error if 'ip' is not a public unicast address
+func CheckIP(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("'%s' is not a public address", ip)
+	}
+	return nil
+}
+
+// CheckURL ensures that every address the host of 'rawURL' resolves to is public
+func CheckURL(ctx context.Context, rawURL string) error {
+	parsed, err := urlPkg.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil {
+		return CheckIP(ip)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("could not resolve '%s'", parsed.Hostname())
+	}
+	for _, addr := range addrs {
+		if err := CheckIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewClient returns a client giving servers 'timeout' to answer. It only connects to public
+// addresses, checked once the host is resolved so that DNS cannot be used to get around it,
+// unless 'allowPrivate' reports true when dialing. Redirects are not followed, a caller that
+// sets 'CheckRedirect' still has every hop dialed through the check
+func NewClient(timeout time.Duration, allowPrivate func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if allowPrivate() {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("'%s' is not an address", host)
+			}
+			return CheckIP(ip)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed in place of the server
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
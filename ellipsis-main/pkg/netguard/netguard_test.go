@@ -0,0 +1,87 @@
+// build+ unit
+package netguard_test
+
+import (
+	"brief/pkg/netguard"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckIP(t *testing.T) {
+	tests := []struct {
+		Name    string
+		IP      string
+		IsError bool
+	}{
+		{"Public", "1.1.1.1", false},
+		{"Public_IPv6", "2606:4700:4700::1111", false},
+		{"Loopback", "127.0.0.1", true},
+		{"Private", "10.0.0.7", true},
+		{"Metadata", "169.254.169.254", true},
+		{"Shared_Address_Space", "100.64.0.1", true},
+		{"Unspecified", "0.0.0.0", true},
+		{"IPv6_Loopback", "::1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if err := netguard.CheckIP(net.ParseIP(test.IP)); (err != nil) != test.IsError {
+				t.Errorf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	if err := netguard.CheckURL(context.Background(), "http://localhost:8080/path"); err == nil {
+		t.Errorf("Expected 'error' to be not nil")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var hit int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hit, 1)
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer srv.Close()
+
+	t.Run("Private_Address", func(t *testing.T) {
+		client := netguard.NewClient(time.Second, func() bool { return false })
+		if _, err := client.Get(target.URL); err == nil || !strings.Contains(err.Error(), "not a public address") {
+			t.Errorf("Expected the loopback address not to be dialed, got '%v'", err)
+		}
+	})
+
+	t.Run("No_Redirects", func(t *testing.T) {
+		client := netguard.NewClient(time.Second, func() bool { return true })
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusFound || atomic.LoadInt32(&hit) != 0 {
+			t.Errorf("Expected the redirect not to be followed, got '%d'", resp.StatusCode)
+		}
+	})
+
+	// Only the first server is taken as public, the address it redirects to is checked again
+	t.Run("Redirect_To_Private_Address", func(t *testing.T) {
+		var dials int32
+		client := netguard.NewClient(time.Second, func() bool { return atomic.AddInt32(&dials, 1) == 1 })
+		client.CheckRedirect = nil
+		if _, err := client.Get(srv.URL); err == nil || !strings.Contains(err.Error(), "not a public address") {
+			t.Errorf("Expected the redirect not to be dialed, got '%v'", err)
+		}
+		if atomic.LoadInt32(&hit) != 0 {
+			t.Errorf("Expected the private address not to be requested")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"brief/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// CreateAuditLog stores 'entry' in the database
+func (p *Postgres) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(entry).Error
+}
+
+// GetAuditLogs fetches the audit logs matching 'filter', latest first
+func (p *Postgres) GetAuditLogs(ctx context.Context, filter *model.AuditFilter) ([]model.AuditLog, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var entries []model.AuditLog
+	err := filterAuditLogs(db, filter).Order("created_at desc").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+
+	return entries, err
+}
+
+// StreamAuditLogs calls 'fn' with every audit log matching 'filter', oldest first, without
+// loading them all in memory. 'filter.Limit' and 'filter.Offset' are ignored
+func (p *Postgres) StreamAuditLogs(ctx context.Context, filter *model.AuditFilter, fn func(entry *model.AuditLog) error) error {
+	db := p.db.WithContext(ctx)
+
+	rows, err := filterAuditLogs(db, filter).Order("created_at asc").Model(&model.AuditLog{}).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry model.AuditLog
+		if err := db.ScanRows(rows, &entry); err != nil {
+			return err
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// filterAuditLogs applies the conditions of 'filter' to a query on audit logs
+func filterAuditLogs(db *gorm.DB, filter *model.AuditFilter) *gorm.DB {
+	if filter.ActorID != "" {
+		db = db.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		db = db.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		db = db.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("created_at < ?", *filter.To)
+	}
+	return db
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"brief/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateCampaign stores 'campaign' in the database
+func (p *Postgres) CreateCampaign(ctx context.Context, campaign *model.Campaign) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(campaign).Error
+}
+
+// GetCampaign fetches a campaign from the database using its 'id'
+func (p *Postgres) GetCampaign(ctx context.Context, id string) (*model.Campaign, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var campaign model.Campaign
+	err := db.First(&campaign, "id = ?", id).Error
+	return &campaign, err
+}
+
+// GetCampaigns fetches all campaigns owned by a user with 'userID'
+func (p *Postgres) GetCampaigns(ctx context.Context, userID string) ([]model.Campaign, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var campaigns []model.Campaign
+	err := db.Where("user_id = ?", userID).Order("created_at desc").Find(&campaigns).Error
+	return campaigns, err
+}
+
+// UpdateCampaign updates the name and utm fields of a campaign with 'id'
+func (p *Postgres) UpdateCampaign(ctx context.Context, id string, campaign *model.Campaign) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	// Ensure the owner of a campaign cannot be changed using this function
+	return db.Model(campaign).Clauses(clause.Returning{}).
+		Omit("id", "user_id", "created_at").
+		Where("id = ?", id).Updates(campaign).Error
+}
+
+// DeleteCampaign deletes a campaign by its 'id' and detaches its url's
+func (p *Postgres) DeleteCampaign(ctx context.Context, id string) (*model.Campaign, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	campaign := model.Campaign{ID: id}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.URL{}).Where("campaign_id = ?", id).
+			Update("campaign_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&campaign).Clauses(clause.Returning{}).Delete(&campaign)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	return &campaign, err
+}
+
+// GetCampaignUrls fetches all url's belonging to a campaign with 'campaignID'
+func (p *Postgres) GetCampaignUrls(ctx context.Context, campaignID string) ([]model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	campaign := model.Campaign{ID: campaignID}
+	var urls []model.URL
+
+	err := db.Model(&campaign).Association("Urls").Find(&urls)
+	return urls, err
+}
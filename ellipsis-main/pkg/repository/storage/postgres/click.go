@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"brief/internal/model"
+	"context"
+)
+
+// CreateClick stores a visit of a short url in the database
+func (p *Postgres) CreateClick(ctx context.Context, click *model.Click) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(click).Error
+}
+
+// GetClicks fetches the clicks on a url with 'urlID', oldest first
+func (p *Postgres) GetClicks(ctx context.Context, urlID string) ([]model.Click, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var clicks []model.Click
+	err := db.Where("url_id = ?", urlID).Order("created_at asc").Find(&clicks).Error
+	return clicks, err
+}
+
+// GetUserClicks fetches the clicks on every url made by a user with 'userID', oldest first
+func (p *Postgres) GetUserClicks(ctx context.Context, userID string) ([]model.Click, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var clicks []model.Click
+	urls := db.Unscoped().Model(&model.URL{}).Select("id").Where("user_id = ?", userID)
+	err := db.Where("url_id IN (?)", urls).Order("created_at asc").Find(&clicks).Error
+	return clicks, err
+}
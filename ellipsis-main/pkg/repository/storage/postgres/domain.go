@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"brief/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateDomain stores 'domain' in the database
+func (p *Postgres) CreateDomain(ctx context.Context, domain *model.Domain) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(domain).Error
+}
+
+// GetDomain fetches a domain from the database using its 'id'
+func (p *Postgres) GetDomain(ctx context.Context, id string) (*model.Domain, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var domain model.Domain
+	err := db.First(&domain, "id = ?", id).Error
+	return &domain, err
+}
+
+// GetDomainByHost fetches a domain from the database using its 'host'
+func (p *Postgres) GetDomainByHost(ctx context.Context, host string) (*model.Domain, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var domain model.Domain
+	err := db.First(&domain, "host = ?", host).Error
+	return &domain, err
+}
+
+// GetDomains fetches all domains registered by a user with 'userID'
+func (p *Postgres) GetDomains(ctx context.Context, userID string) ([]model.Domain, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var domains []model.Domain
+	err := db.Where("user_id = ?", userID).Order("created_at desc").Find(&domains).Error
+	return domains, err
+}
+
+// VerifyDomain marks a domain with 'id' as verified
+func (p *Postgres) VerifyDomain(ctx context.Context, id string) (*model.Domain, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	domain := model.Domain{ID: id}
+	err := db.Model(&domain).Clauses(clause.Returning{}).
+		Updates(map[string]interface{}{"verified": true, "verified_at": &now}).Error
+	return &domain, err
+}
+
+// DeleteDomain deletes a domain by its 'id' along with the url's issued under it
+func (p *Postgres) DeleteDomain(ctx context.Context, id string) (*model.Domain, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	domain := model.Domain{ID: id}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("domain_id = ?", id).Delete(&model.URL{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&domain).Clauses(clause.Returning{}).Delete(&domain)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	return &domain, err
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateOutboxEvents stores 'events' in the event outbox
+func (p *Postgres) CreateOutboxEvents(ctx context.Context, events []model.OutboxEvent) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(&events).Error
+}
+
+// ClaimOutboxEvents fetches up to 'limit' pending events due at 'now', oldest first, and postpones
+// their next attempt by 'lease' so that other instances skip them while they are relayed
+func (p *Postgres) ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var events []model.OutboxEvent
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", constant.OutboxPending, now).
+			Order("next_attempt_at asc, created_at asc").Limit(limit).Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+		return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return events, err
+}
+
+// UpdateOutboxEvent stores the outcome of an attempt at relaying 'event'
+func (p *Postgres) UpdateOutboxEvent(ctx context.Context, event *model.OutboxEvent) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Model(event).Select("status", "attempts", "next_attempt_at", "completed", "error", "processed_at").
+		Updates(event).Error
+}
+
+// Transaction runs 'fn' with a repository bound to one transaction, every query it makes is
+// committed when 'fn' returns nil and rolled back otherwise
+func (p *Postgres) Transaction(ctx context.Context, fn func(tx storage.StorageRepository) error) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Postgres{tx})
+	})
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"brief/pkg/metrics"
+	"brief/pkg/tracing"
+	"errors"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	queryStartKey = "metrics:query_start"
+	querySpanKey  = "tracing:query_span"
+)
+
+// instrument observes the duration of every query made through 'db', by operation and table,
+// and traces it as a child of the span in the query's context
+func instrument(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", startQuery("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", startQuery("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", startQuery("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startQuery("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", startQuery("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startQuery("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw"))
+}
+
+func startQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		db.InstanceSet(queryStartKey, time.Now())
+
+		_, span := tracing.Start(db.Statement.Context, "postgres "+operation,
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBSQLTable(db.Statement.Table),
+		)
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+func observeQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if start, ok := db.InstanceGet(queryStartKey); ok {
+			metrics.DBQueryDuration.WithLabelValues(operation, db.Statement.Table).
+				Observe(time.Since(start.(time.Time)).Seconds())
+		}
+
+		if span, ok := db.InstanceGet(querySpanKey); ok {
+			span := span.(trace.Span)
+			span.SetAttributes(semconv.DBStatement(db.Statement.SQL.String()))
+
+			// Missing records are answers, not failures
+			err := db.Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = nil
+			}
+			tracing.End(span, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateJob stores 'job' in the queue, a job with the id of a stored one is ignored
+func (p *Postgres) CreateJob(ctx context.Context, job *model.Job) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(job).Error
+}
+
+// ClaimJobs fetches up to 'limit' pending jobs due at 'now', oldest first, and postpones them by
+// 'lease' so that other instances skip them while they run
+func (p *Postgres) ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var jobs []model.Job
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", constant.JobPending, now).
+			Order("run_at asc").Limit(limit).Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(jobs))
+		for i, j := range jobs {
+			ids[i] = j.ID
+		}
+		return tx.Model(&model.Job{}).Where("id IN ?", ids).Update("run_at", now.Add(lease)).Error
+	})
+	return jobs, err
+}
+
+// UpdateJob stores the outcome of an attempt at 'job'
+func (p *Postgres) UpdateJob(ctx context.Context, job *model.Job) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Model(job).Select("status", "attempts", "run_at", "error", "finished_at").Updates(job).Error
+}
+
+// AcquireLease gives the lease called 'name' to 'holder' until 'expiresAt', if it is free, expired
+// at 'now' or already held by 'holder'. It reports whether 'holder' holds the lease
+func (p *Postgres) AcquireLease(ctx context.Context, name, holder string, now, expiresAt time.Time) (bool, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	result := db.Exec(`INSERT INTO job_leases (name, holder, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+		WHERE job_leases.holder = EXCLUDED.holder OR job_leases.expires_at <= ?`, name, holder, expiresAt, now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseLease frees the lease called 'name' if it is still held by 'holder'
+func (p *Postgres) ReleaseLease(ctx context.Context, name, holder string) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Where("name = ? AND holder = ?", name, holder).Delete(&model.JobLease{}).Error
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetUrlsToCheck fetches up to 'limit' urls whose destination was never checked or last checked
+// before 'checkedBefore', the least recently checked first
+func (p *Postgres) GetUrlsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var urls []model.URL
+	err := db.Where("checked_at IS NULL OR checked_at < ?", checkedBefore).
+		Order("checked_at asc nulls first").Order("created_at asc").Limit(limit).Find(&urls).Error
+	return urls, err
+}
+
+// RecordLinkCheck stores 'check' and the link health of 'url' it led to, only the latest 'keep'
+// checks of the url are kept
+func (p *Postgres) RecordLinkCheck(ctx context.Context, url *model.URL, check *model.LinkCheck, keep int) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(check).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&model.URL{}).Where("id = ?", url.ID).Updates(map[string]interface{}{
+			"link_status":   url.LinkStatus,
+			"link_failures": url.LinkFailures,
+			"checked_at":    url.CheckedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		latest := tx.Model(&model.LinkCheck{}).Select("id").Where("url_id = ?", url.ID).
+			Order("checked_at desc").Limit(keep)
+		return tx.Where("url_id = ? AND id NOT IN (?)", url.ID, latest).Delete(&model.LinkCheck{}).Error
+	})
+}
+
+// GetBrokenUrls fetches the urls made by a user with 'userID', or in the workspaces they are a
+// member of, whose destination is broken, the most recently checked first
+func (p *Postgres) GetBrokenUrls(ctx context.Context, userID string) ([]model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	workspaces := db.Model(&model.Membership{}).Select("workspace_id").Where("user_id = ?", userID)
+	var urls []model.URL
+	err := db.Where("link_status = ? AND (user_id = ? OR workspace_id IN (?))", constant.LinkBroken, userID, workspaces).
+		Order("checked_at desc").Find(&urls).Error
+	return urls, err
+}
+
+// GetLinkChecks fetches the latest 'limit' checks of a url with 'urlID', latest first
+func (p *Postgres) GetLinkChecks(ctx context.Context, urlID string, limit int) ([]model.LinkCheck, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var checks []model.LinkCheck
+	err := db.Where("url_id = ?", urlID).Order("checked_at desc").Limit(limit).Find(&checks).Error
+	return checks, err
+}
+
+// GetLatestLinkChecks fetches the latest check of each url with an id in 'urlIDs', in one query
+func (p *Postgres) GetLatestLinkChecks(ctx context.Context, urlIDs []string) ([]model.LinkCheck, error) {
+	if len(urlIDs) == 0 {
+		return nil, nil
+	}
+
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var checks []model.LinkCheck
+	err := db.Select("DISTINCT ON (url_id) *").Where("url_id IN ?", urlIDs).
+		Order("url_id").Order("checked_at desc").Find(&checks).Error
+	return checks, err
+}
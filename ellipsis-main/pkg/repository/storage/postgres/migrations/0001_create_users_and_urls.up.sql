@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS users (
+    id varchar(50) NOT NULL UNIQUE,
+    firstname varchar(100) NOT NULL,
+    lastname varchar(100),
+    email varchar(100) NOT NULL UNIQUE,
+    password varchar(100) NOT NULL,
+    role smallint NOT NULL,
+    is_locked boolean,
+    salt varchar(50) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_users_id ON users (id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
+
+CREATE TABLE IF NOT EXISTS urls (
+    id varchar(50) NOT NULL UNIQUE,
+    long_url text NOT NULL,
+    hash text NOT NULL UNIQUE,
+    user_id text,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_urls FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_urls_id ON urls (id);
+CREATE INDEX IF NOT EXISTS idx_urls_hash ON urls (hash);
+CREATE INDEX IF NOT EXISTS idx_urls_user_id ON urls (user_id);
+CREATE INDEX IF NOT EXISTS idx_urls_created_at ON urls (created_at);
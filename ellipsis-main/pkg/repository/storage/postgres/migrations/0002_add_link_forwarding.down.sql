@@ -0,0 +1,3 @@
+ALTER TABLE urls DROP COLUMN IF EXISTS forward_path;
+ALTER TABLE urls DROP COLUMN IF EXISTS query_mode;
+ALTER TABLE urls DROP COLUMN IF EXISTS forward_query;
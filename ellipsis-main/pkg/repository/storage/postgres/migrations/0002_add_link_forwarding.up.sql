@@ -0,0 +1,3 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS forward_query boolean NOT NULL DEFAULT false;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS query_mode varchar(20);
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS forward_path boolean NOT NULL DEFAULT false;
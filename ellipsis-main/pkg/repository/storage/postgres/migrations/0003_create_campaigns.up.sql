@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id varchar(50) NOT NULL UNIQUE,
+    name varchar(100) NOT NULL,
+    utm_source varchar(100),
+    utm_medium varchar(100),
+    utm_campaign varchar(100),
+    utm_term varchar(100),
+    utm_content varchar(100),
+    user_id varchar(50) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_campaigns_id ON campaigns (id);
+CREATE INDEX IF NOT EXISTS idx_campaigns_user_id ON campaigns (user_id);
+CREATE INDEX IF NOT EXISTS idx_campaigns_created_at ON campaigns (created_at);
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS campaign_id varchar(50) DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_urls_campaign_id ON urls (campaign_id);
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_campaigns_urls') THEN
+        ALTER TABLE urls ADD CONSTRAINT fk_campaigns_urls FOREIGN KEY (campaign_id) REFERENCES campaigns (id);
+    END IF;
+END $$;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS redirect_rules (
+    id varchar(50) NOT NULL UNIQUE,
+    url_id varchar(50) NOT NULL,
+    position bigint NOT NULL,
+    os varchar(20),
+    device varchar(20),
+    browser varchar(20),
+    language varchar(20),
+    country varchar(2),
+    starts_at timestamptz,
+    ends_at timestamptz,
+    destination text NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_urls_rules FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_redirect_rules_id ON redirect_rules (id);
+CREATE INDEX IF NOT EXISTS idx_redirect_rules_url_id ON redirect_rules (url_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS clicks (
+    id varchar(50) NOT NULL UNIQUE,
+    url_id varchar(50) NOT NULL,
+    country varchar(2),
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_clicks_id ON clicks (id);
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id ON clicks (url_id);
+CREATE INDEX IF NOT EXISTS idx_clicks_created_at ON clicks (created_at);
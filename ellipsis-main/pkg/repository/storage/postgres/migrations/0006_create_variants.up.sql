@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS variants (
+    id varchar(50) NOT NULL UNIQUE,
+    url_id varchar(50) NOT NULL,
+    position bigint NOT NULL,
+    name varchar(50) NOT NULL,
+    destination text NOT NULL,
+    weight bigint NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_urls_variants FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_variants_id ON variants (id);
+CREATE INDEX IF NOT EXISTS idx_variants_url_id ON variants (url_id);
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant_id varchar(50) DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_clicks_variant_id ON clicks (variant_id);
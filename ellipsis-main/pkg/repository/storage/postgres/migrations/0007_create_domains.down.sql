@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_urls_domain_hash;
+ALTER TABLE urls ADD CONSTRAINT urls_hash_key UNIQUE (hash);
+ALTER TABLE urls DROP COLUMN IF EXISTS domain_id;
+DROP TABLE IF EXISTS domains;
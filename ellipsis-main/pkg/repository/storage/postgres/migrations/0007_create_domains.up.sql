@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS domains (
+    id varchar(50) NOT NULL UNIQUE,
+    host varchar(255) NOT NULL UNIQUE,
+    user_id varchar(50) NOT NULL,
+    method varchar(10) NOT NULL,
+    token varchar(100) NOT NULL,
+    verified boolean NOT NULL DEFAULT false,
+    verified_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_domains_id ON domains (id);
+CREATE INDEX IF NOT EXISTS idx_domains_host ON domains (host);
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains (user_id);
+CREATE INDEX IF NOT EXISTS idx_domains_created_at ON domains (created_at);
+
+-- Hashes used to be unique across all links, they are now unique per domain
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS domain_id varchar(50) NOT NULL DEFAULT '';
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_hash_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_domain_hash ON urls (hash, domain_id);
@@ -0,0 +1,4 @@
+ALTER TABLE urls DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS workspaces;
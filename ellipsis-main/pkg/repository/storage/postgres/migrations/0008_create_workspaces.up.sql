@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id varchar(50) NOT NULL UNIQUE,
+    name varchar(100) NOT NULL,
+    owner_id varchar(50) NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_workspaces_id ON workspaces (id);
+CREATE INDEX IF NOT EXISTS idx_workspaces_owner_id ON workspaces (owner_id);
+CREATE INDEX IF NOT EXISTS idx_workspaces_created_at ON workspaces (created_at);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    workspace_id varchar(50),
+    user_id varchar(50),
+    role varchar(10) NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (workspace_id, user_id),
+    CONSTRAINT fk_workspaces_members FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_memberships_user_id ON memberships (user_id);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id varchar(50) NOT NULL UNIQUE,
+    workspace_id varchar(50) NOT NULL,
+    email varchar(100) NOT NULL,
+    role varchar(10) NOT NULL,
+    token varchar(50) NOT NULL UNIQUE,
+    invited_by varchar(50) NOT NULL,
+    expires_at timestamptz,
+    accepted_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_invitations_id ON invitations (id);
+CREATE INDEX IF NOT EXISTS idx_invitations_workspace_id ON invitations (workspace_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS workspace_id varchar(50) DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_urls_workspace_id ON urls (workspace_id);
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_workspaces_urls') THEN
+        ALTER TABLE urls ADD CONSTRAINT fk_workspaces_urls FOREIGN KEY (workspace_id) REFERENCES workspaces (id);
+    END IF;
+END $$;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS role_changes (
+    id varchar(50),
+    user_id varchar(50) NOT NULL,
+    changed_by varchar(50) NOT NULL,
+    old_role smallint NOT NULL,
+    new_role smallint NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_role_changes_user_id ON role_changes (user_id);
+CREATE INDEX IF NOT EXISTS idx_role_changes_created_at ON role_changes (created_at);
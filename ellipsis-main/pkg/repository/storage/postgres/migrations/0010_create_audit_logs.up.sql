@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id varchar(50),
+    actor_id varchar(50),
+    action varchar(50) NOT NULL,
+    target_type varchar(20) NOT NULL,
+    target_id varchar(100),
+    ip varchar(45),
+    user_agent text,
+    before jsonb,
+    after jsonb,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+-- Audit logs are append-only
+CREATE OR REPLACE RULE audit_logs_no_update AS ON UPDATE TO audit_logs DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_logs_no_delete AS ON DELETE TO audit_logs DO INSTEAD NOTHING;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id varchar(50) NOT NULL,
+    user_id varchar(50) NOT NULL,
+    url text NOT NULL,
+    events jsonb NOT NULL,
+    secret varchar(100) NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_events ON webhooks USING gin (events);
+CREATE INDEX IF NOT EXISTS idx_webhooks_created_at ON webhooks (created_at);
+
+-- Pending deliveries are the outbox, events survive restarts until they are delivered
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id varchar(50) NOT NULL,
+    webhook_id varchar(50) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event varchar(50) NOT NULL,
+    payload jsonb NOT NULL,
+    status varchar(20) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    response_status integer,
+    error text,
+    replay_of varchar(50),
+    created_at timestamptz,
+    delivered_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
@@ -0,0 +1,16 @@
+-- Domain events waiting to be relayed to the subscribers of the event bus
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id varchar(50) NOT NULL,
+    name varchar(50) NOT NULL,
+    payload jsonb NOT NULL,
+    status varchar(20) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    completed jsonb NOT NULL DEFAULT '[]',
+    error text,
+    created_at timestamptz,
+    processed_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
@@ -0,0 +1,23 @@
+-- Pending jobs are the queue, the others are kept as the history of background work
+CREATE TABLE IF NOT EXISTS jobs (
+    id varchar(100) NOT NULL,
+    name varchar(50) NOT NULL,
+    payload jsonb NOT NULL,
+    status varchar(20) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    run_at timestamptz,
+    error text,
+    created_at timestamptz,
+    finished_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (status, run_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs (created_at);
+
+-- Replicas running a singleton job hold its lease
+CREATE TABLE IF NOT EXISTS job_leases (
+    name varchar(50) NOT NULL,
+    holder varchar(50) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY (name)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS link_checks;
+ALTER TABLE urls DROP COLUMN IF EXISTS checked_at;
+ALTER TABLE urls DROP COLUMN IF EXISTS link_failures;
+ALTER TABLE urls DROP COLUMN IF EXISTS link_status;
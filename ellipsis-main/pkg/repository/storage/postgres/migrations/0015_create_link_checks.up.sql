@@ -0,0 +1,20 @@
+-- Health of the destination of each url, as found by the link checker
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS link_status varchar(20);
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS link_failures integer NOT NULL DEFAULT 0;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS checked_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_urls_link_status ON urls (link_status);
+CREATE INDEX IF NOT EXISTS idx_urls_checked_at ON urls (checked_at);
+
+-- The latest checks of each url
+CREATE TABLE IF NOT EXISTS link_checks (
+    id varchar(50) NOT NULL,
+    url_id varchar(50) NOT NULL,
+    ok boolean NOT NULL,
+    status_code integer,
+    error text,
+    latency_ms bigint,
+    checked_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_link_checks_url_id ON link_checks (url_id);
+CREATE INDEX IF NOT EXISTS idx_link_checks_checked_at ON link_checks (checked_at);
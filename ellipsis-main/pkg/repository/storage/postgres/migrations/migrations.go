@@ -0,0 +1,11 @@
+// Package migrations holds the versioned changes of the database schema, named
+// '<version>_<name>.up.sql' and '<version>_<name>.down.sql'.
+//
+// Migrations create what they need only if it does not exist yet, so that databases
+// created before versioned migrations adopt them without changes.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -2,8 +2,9 @@ package postgres
 
 import (
 	"brief/internal/config"
-	"brief/internal/model"
+	"brief/pkg/migrate"
 	"brief/pkg/repository/storage"
+	"brief/pkg/repository/storage/postgres/migrations"
 	"context"
 	"time"
 
@@ -26,17 +27,16 @@ func GetDB() storage.StorageRepository {
 	return &Postgres{db}
 }
 
-func ConnectToDB() *gorm.DB {
-	logger := log.New()
+func ConnectToDB(logger *log.Logger) *gorm.DB {
 
-	database, err := gorm.Open(postgres.Open(dsn()), &gorm.Config{})
+	database, err := gorm.Open(postgres.Open(dsn()), &gorm.Config{TranslateError: true})
 	if err != nil {
 		logger.Fatalf("could not connect to postgres, got error: %s", err)
 	}
 	db = database
 
-	if err := migrateDB(logger); err != nil {
-		logger.Fatalf("could not run db migrations, got error: %s", err)
+	if err := instrument(db); err != nil {
+		logger.Fatalf("could not instrument postgres, got error: %s", err)
 	}
 
 	// IF EVERYTHING IS OKAY, THEN CONNECTION IS ESTABLISHED
@@ -59,18 +59,28 @@ func dsn() string {
 	return dsn
 }
 
-// migrateDB creates db schemas
-func migrateDB(logger *log.Logger) error {
-	err := db.AutoMigrate(
-		&model.User{},
-		&model.URL{},
-	)
+// Migrator returns the migrator of the database schema, built from the embedded migrations
+func Migrator(logger *log.Logger) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	logger.Info("DATABASE MIGRATION SUCCESSFUL")
-	return nil
+	migrations, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(sqlDB, migrations, logger), nil
+}
+
+// Ping checks that the database accepts connections
+func (p *Postgres) Ping(ctx context.Context) error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
 }
 
 // DBWithTimeout returns a database with timeout, and the context's cancel func
@@ -3,7 +3,9 @@ package postgres
 import (
 	"brief/internal/model"
 	"context"
+	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -14,13 +16,19 @@ func (p *Postgres) CreateURL(ctx context.Context, url *model.URL) error {
 	return db.Model(&model.User{ID: url.UserID}).Association("Urls").Append(url)
 }
 
-// GetURL fetches a url entry from the database using its 'hash'
-func (p *Postgres) GetURL(ctx context.Context, hash string) (*model.URL, error) {
+// GetURL fetches a url entry of a domain from the database using its 'hash', along with its
+// redirect rules and split test variants. The shared domain has an empty 'domainID'
+func (p *Postgres) GetURL(ctx context.Context, domainID, hash string) (*model.URL, error) {
 	db, cancel := p.DBWithTimeout(ctx)
 	defer cancel()
 
+	byPosition := func(db *gorm.DB) *gorm.DB {
+		return db.Order("position asc")
+	}
+
 	var url model.URL
-	err := db.First(&url, "hash = ?", hash).Error
+	err := db.Preload("Rules", byPosition).Preload("Variants", byPosition).
+		First(&url, "domain_id = ? AND hash = ?", domainID, hash).Error
 	return &url, err
 }
 
@@ -46,6 +54,36 @@ func (p *Postgres) GetUrls(ctx context.Context, userID string) ([]model.URL, err
 	return urls, err
 }
 
+// GetWorkspaceUrls fetches all url's owned by a workspace with 'workspaceID'
+func (p *Postgres) GetWorkspaceUrls(ctx context.Context, workspaceID string) ([]model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	workspace := model.Workspace{ID: workspaceID}
+	var urls []model.URL
+
+	err := db.Model(&workspace).Association("Urls").Find(&urls)
+	return urls, err
+}
+
+// SetURLWorkspace moves a url with 'id' into a workspace, an empty 'workspaceID' makes it personal again
+func (p *Postgres) SetURLWorkspace(ctx context.Context, id, workspaceID string) (*model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var value interface{}
+	if workspaceID != "" {
+		value = workspaceID
+	}
+
+	url := model.URL{ID: id}
+	result := db.Model(&url).Clauses(clause.Returning{}).Update("workspace_id", value)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return &url, gorm.ErrRecordNotFound
+	}
+	return &url, result.Error
+}
+
 // GetAll fetches all url's in the database
 func (p *Postgres) GetAll(ctx context.Context) ([]model.URL, error) {
 	db, cancel := p.DBWithTimeout(ctx)
@@ -56,7 +94,7 @@ func (p *Postgres) GetAll(ctx context.Context) ([]model.URL, error) {
 	return urls, err
 }
 
-// DeleteUrl deletes a random url by its 'id'
+// DeleteUrl moves a url with 'id' to the trash, its hash stays reserved until it is purged
 func (p *Postgres) DeleteUrl(ctx context.Context, id string) (*model.URL, error) {
 	db, cancel := p.DBWithTimeout(ctx)
 	defer cancel()
@@ -65,3 +103,135 @@ func (p *Postgres) DeleteUrl(ctx context.Context, id string) (*model.URL, error)
 	err := db.Model(&url).Clauses(clause.Returning{}).Delete(&url).Error
 	return &url, err
 }
+
+// GetDeletedURL fetches a url with 'id' from the trash
+func (p *Postgres) GetDeletedURL(ctx context.Context, id string) (*model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var url model.URL
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&url, "id = ?", id).Error
+	return &url, err
+}
+
+// GetDeletedUrls fetches the urls of a user with 'userID' that are in the trash, latest first
+func (p *Postgres) GetDeletedUrls(ctx context.Context, userID string) ([]model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var urls []model.URL
+	err := db.Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").Find(&urls).Error
+	return urls, err
+}
+
+// RestoreUrl takes a url with 'id' out of the trash
+func (p *Postgres) RestoreUrl(ctx context.Context, id string) (*model.URL, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	url := model.URL{ID: id}
+	res := db.Unscoped().Model(&url).Clauses(clause.Returning{}).
+		Where("deleted_at IS NOT NULL").Update("deleted_at", nil)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &url, nil
+}
+
+// PurgeUrls permanently deletes the urls that were moved to the trash before 'deletedBefore',
+// along with their clicks, link checks, rules and variants
+func (p *Postgres) PurgeUrls(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&model.URL{}).Select("id").Where("deleted_at < ?", deletedBefore)
+		if err := tx.Where("url_id IN (?)", expired).Delete(&model.Click{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("url_id IN (?)", expired).Delete(&model.LinkCheck{}).Error; err != nil {
+			return err
+		}
+
+		res := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&model.URL{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+
+	return purged, err
+}
+
+// GetRules fetches the redirect rules of a url with 'urlID' in evaluation order
+func (p *Postgres) GetRules(ctx context.Context, urlID string) ([]model.RedirectRule, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var rules []model.RedirectRule
+	err := db.Where("url_id = ?", urlID).Order("position asc").Find(&rules).Error
+	return rules, err
+}
+
+// ReplaceRules replaces all redirect rules of a url with 'urlID' by 'rules'
+func (p *Postgres) ReplaceRules(ctx context.Context, urlID string, rules []model.RedirectRule) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("url_id = ?", urlID).Delete(&model.RedirectRule{}).Error; err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+}
+
+// ReplaceVariants replaces all split test variants of a url with 'urlID' by 'variants'
+func (p *Postgres) ReplaceVariants(ctx context.Context, urlID string, variants []model.Variant) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("url_id = ?", urlID).Delete(&model.Variant{}).Error; err != nil {
+			return err
+		}
+
+		if len(variants) == 0 {
+			return nil
+		}
+		return tx.Create(&variants).Error
+	})
+}
+
+// GetVariantStats fetches the split test variants of a url with 'urlID' along with their click counts
+func (p *Postgres) GetVariantStats(ctx context.Context, urlID string) ([]model.VariantStats, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var stats []model.VariantStats
+	err := db.Model(&model.Variant{}).
+		Select("variants.*, COUNT(clicks.id) AS clicks").
+		Joins("LEFT JOIN clicks ON clicks.variant_id = variants.id").
+		Where("variants.url_id = ?", urlID).
+		Group("variants.id").
+		Order("variants.position asc").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// TransferUrls gives every url made by a user with 'fromUserID' to a user with 'toUserID'
+func (p *Postgres) TransferUrls(ctx context.Context, fromUserID, toUserID string) (int64, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	res := db.Model(&model.URL{}).Where("user_id = ?", fromUserID).Update("user_id", toUserID)
+	return res.RowsAffected, res.Error
+}
@@ -4,7 +4,9 @@ import (
 	"brief/internal/model"
 	"context"
 	"strings"
+	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -43,15 +45,15 @@ func (p *Postgres) GetAllUsers(ctx context.Context) ([]model.User, error) {
 	return users, err
 }
 
-// UpdateUser updates some specific user fields
+// UpdateUser updates the name of a user with 'id'
 func (p *Postgres) UpdateUser(ctx context.Context, id string, user *model.User) error {
 	db, cancel := p.DBWithTimeout(ctx)
 	defer cancel()
 
-	// Ensure 'is_verified', 'is_locked', 'password', 'email' and 'salt' cannot be updated using this function
+	// Only the name can be updated using this function, whatever else 'user' holds
+	user.ID = id
 	return db.Model(user).Clauses(clause.Returning{}).
-		Omit("is_locked", "password", "salt", "email", "role").
-		Where("id = ?", id).Updates(user).Error
+		Select("firstname", "lastname").Updates(user).Error
 }
 
 // ResetPassword resets the 'password' and 'salt' of a user with 'id'
@@ -86,3 +88,221 @@ func (p *Postgres) LockUnlock(ctx context.Context, idOrEmail string, isLocked bo
 
 	return &user, err
 }
+
+// AssignRole sets the role of a user to 'change.NewRole' and records 'change', 'change.OldRole'
+// is set to the role the user had
+func (p *Postgres) AssignRole(ctx context.Context, change *model.RoleChange) (*model.User, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var user model.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", change.UserID).First(&user).Error; err != nil {
+			return err
+		}
+		change.OldRole = user.Role
+
+		if err := tx.Model(&user).Clauses(clause.Returning{}).
+			Update("role", change.NewRole).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(change).Error
+	})
+
+	return &user, err
+}
+
+// GetRoleChanges fetches the role changes of a user with 'userID', latest first
+func (p *Postgres) GetRoleChanges(ctx context.Context, userID string) ([]model.RoleChange, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var changes []model.RoleChange
+	err := db.Where("user_id = ?", userID).Order("created_at desc").Find(&changes).Error
+
+	return changes, err
+}
+
+// DeleteUser moves a user with 'id' and their urls to the trash. The urls are given the same
+// deletion time as the user so that they can be restored together, urls of workspaces stay live
+// for the other members
+func (p *Postgres) DeleteUser(ctx context.Context, id string) (*model.User, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	user := model.User{ID: id}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&user).Clauses(clause.Returning{}).Update("deleted_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&model.URL{}).Where("user_id = ? AND workspace_id IS NULL", id).Update("deleted_at", now).Error
+	})
+
+	return &user, err
+}
+
+// GetDeletedUser fetches a user from the trash using its 'id' or 'email'
+func (p *Postgres) GetDeletedUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	// check if 'idOrEmail' is an email or an id
+	var cond string
+	if strings.Contains(idOrEmail, "@") {
+		cond = "email = ?"
+	} else {
+		cond = "id = ?"
+	}
+
+	var user model.User
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&user, cond, idOrEmail).Error
+	return &user, err
+}
+
+// GetDeletedUsers fetches all users in the trash, latest first
+func (p *Postgres) GetDeletedUsers(ctx context.Context) ([]model.User, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var users []model.User
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&users).Error
+	return users, err
+}
+
+// RestoreUser takes a user with 'id' out of the trash, along with the urls that were deleted
+// with them
+func (p *Postgres) RestoreUser(ctx context.Context, id string) (*model.User, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var user model.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&user, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&model.URL{}).Where("user_id = ? AND workspace_id IS NULL AND deleted_at = ?", id, user.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&user).Clauses(clause.Returning{}).Update("deleted_at", nil).Error
+	})
+
+	return &user, err
+}
+
+// PurgeUsers permanently deletes the users that were moved to the trash before 'deletedBefore'
+// along with their urls, campaigns, domains, webhooks, memberships and the workspaces they own.
+// Links of other members in those workspaces are given back to their creators, and their links
+// in workspaces of other users are given to the owners of the workspaces
+func (p *Postgres) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := tx.Unscoped().Model(&model.User{}).Where("deleted_at < ?", deletedBefore).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		var err error
+		purged, err = purgeUsers(tx, ids)
+		return err
+	})
+
+	return purged, err
+}
+
+// PurgeUser permanently deletes a user with 'id' right away, whether or not they are in the
+// trash, along with everything PurgeUsers deletes
+func (p *Postgres) PurgeUser(ctx context.Context, id string) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		purged, err := purgeUsers(tx, []string{id})
+		if err == nil && purged == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return err
+	})
+}
+
+// purgeUsers permanently deletes the users with 'ids' and their data in 'tx', and returns how
+// many users were deleted
+func purgeUsers(tx *gorm.DB, ids []string) (int64, error) {
+	urls := tx.Unscoped().Model(&model.URL{}).Select("id").Where("user_id IN ?", ids)
+	workspaces := tx.Model(&model.Workspace{}).Select("id").Where("owner_id IN ?", ids)
+	webhooks := tx.Model(&model.Webhook{}).Select("id").Where("user_id IN ?", ids)
+
+	for _, stmt := range []*gorm.DB{
+		// Links of a workspace belong to it rather than to the member who created them
+		tx.Unscoped().Model(&model.URL{}).
+			Where("user_id IN ? AND workspace_id IS NOT NULL AND workspace_id NOT IN (?)", ids, workspaces).
+			Update("user_id", gorm.Expr("(SELECT owner_id FROM workspaces WHERE workspaces.id = urls.workspace_id)")),
+		tx.Where("url_id IN (?)", urls).Delete(&model.Click{}),
+		tx.Where("url_id IN (?)", urls).Delete(&model.LinkCheck{}),
+		tx.Unscoped().Where("user_id IN ?", ids).Delete(&model.URL{}),
+		tx.Unscoped().Model(&model.URL{}).Where("workspace_id IN (?)", workspaces).Update("workspace_id", nil),
+		tx.Where("workspace_id IN (?)", workspaces).Delete(&model.Invitation{}),
+		tx.Where("owner_id IN ?", ids).Delete(&model.Workspace{}),
+		tx.Where("user_id IN ?", ids).Delete(&model.Membership{}),
+		tx.Where("user_id IN ?", ids).Delete(&model.Campaign{}),
+		tx.Where("user_id IN ?", ids).Delete(&model.Domain{}),
+		tx.Where("webhook_id IN (?)", webhooks).Delete(&model.WebhookDelivery{}),
+		tx.Where("user_id IN ?", ids).Delete(&model.Webhook{}),
+	} {
+		if stmt.Error != nil {
+			return 0, stmt.Error
+		}
+	}
+
+	res := tx.Unscoped().Where("id IN ?", ids).Delete(&model.User{})
+	return res.RowsAffected, res.Error
+}
+
+// AnonymizeUser replaces the personal details of a user with 'id' and locks them, their urls are
+// kept. Their memberships and the invitations sent to them are removed
+func (p *Postgres) AnonymizeUser(ctx context.Context, id, email string) (*model.User, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var user model.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("email = ?", user.Email).Delete(&model.Invitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.Membership{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&user).Clauses(clause.Returning{}).Updates(map[string]interface{}{
+			"firstname": "Deleted",
+			"lastname":  "User",
+			"email":     email,
+			"password":  "",
+			"salt":      "",
+			"is_locked": true,
+		}).Error
+	})
+
+	return &user, err
+}
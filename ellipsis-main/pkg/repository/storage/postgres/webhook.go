@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateWebhook stores 'webhook' in the database
+func (p *Postgres) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(webhook).Error
+}
+
+// GetWebhook fetches a webhook from the database using its 'id'
+func (p *Postgres) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var webhook model.Webhook
+	err := db.First(&webhook, "id = ?", id).Error
+	return &webhook, err
+}
+
+// GetWebhooks fetches all webhooks of a user with 'userID'
+func (p *Postgres) GetWebhooks(ctx context.Context, userID string) ([]model.Webhook, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var webhooks []model.Webhook
+	err := db.Where("user_id = ?", userID).Order("created_at desc").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetSubscribedWebhooks fetches the webhooks of a user with 'userID' subscribed to 'event', or
+// those of every user when 'userID' is empty
+func (p *Postgres) GetSubscribedWebhooks(ctx context.Context, userID, event string) ([]model.Webhook, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	events, _ := json.Marshal([]string{event})
+	db = db.Where("events @> ?", string(events))
+	if userID != "" {
+		db = db.Where("user_id = ?", userID)
+	}
+
+	var webhooks []model.Webhook
+	err := db.Find(&webhooks).Error
+	return webhooks, err
+}
+
+// DeleteWebhook deletes a webhook by its 'id', its deliveries are deleted with it
+func (p *Postgres) DeleteWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	webhook := model.Webhook{ID: id}
+	result := db.Model(&webhook).Clauses(clause.Returning{}).Delete(&webhook)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &webhook, nil
+}
+
+// CreateDeliveries stores 'deliveries' in the outbox
+func (p *Postgres) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(&deliveries).Error
+}
+
+// GetDelivery fetches a webhook delivery from the database using its 'id'
+func (p *Postgres) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var delivery model.WebhookDelivery
+	err := db.First(&delivery, "id = ?", id).Error
+	return &delivery, err
+}
+
+// GetDeliveries fetches the latest 'limit' deliveries of a webhook with 'webhookID', latest first
+func (p *Postgres) GetDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var deliveries []model.WebhookDelivery
+	err := db.Where("webhook_id = ?", webhookID).Order("created_at desc").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDeliveries fetches up to 'limit' pending deliveries due at 'now', oldest first, and
+// postpones their next attempt by 'lease' so that other instances skip them while they are sent
+func (p *Postgres) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var deliveries []model.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", constant.DeliveryPending, now).
+			Order("next_attempt_at asc").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+// UpdateDelivery stores the outcome of an attempt at 'delivery'
+func (p *Postgres) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Model(delivery).Select("status", "attempts", "next_attempt_at", "response_status", "error", "delivered_at").
+		Updates(delivery).Error
+}
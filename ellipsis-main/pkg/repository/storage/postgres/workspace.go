@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateWorkspace stores 'workspace' in the database and makes its owner a member
+func (p *Postgres) CreateWorkspace(ctx context.Context, workspace *model.Workspace) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members", "Urls").Create(workspace).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.Membership{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.OwnerID,
+			Role:        constant.WorkspaceOwner,
+			CreatedAt:   workspace.CreatedAt,
+		}).Error
+	})
+}
+
+// GetWorkspace fetches a workspace from the database using its 'id'
+func (p *Postgres) GetWorkspace(ctx context.Context, id string) (*model.Workspace, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var workspace model.Workspace
+	err := db.First(&workspace, "id = ?", id).Error
+	return &workspace, err
+}
+
+// GetWorkspaces fetches all workspaces a user with 'userID' is a member of
+func (p *Postgres) GetWorkspaces(ctx context.Context, userID string) ([]model.Workspace, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var workspaces []model.Workspace
+	err := db.Joins("JOIN memberships ON memberships.workspace_id = workspaces.id").
+		Where("memberships.user_id = ?", userID).
+		Order("workspaces.created_at desc").
+		Find(&workspaces).Error
+	return workspaces, err
+}
+
+// GetMembers fetches all members of a workspace with 'workspaceID'
+func (p *Postgres) GetMembers(ctx context.Context, workspaceID string) ([]model.Membership, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var members []model.Membership
+	err := db.Where("workspace_id = ?", workspaceID).Order("created_at asc").Find(&members).Error
+	return members, err
+}
+
+// GetMembership fetches the membership of a user with 'userID' in a workspace with 'workspaceID'
+func (p *Postgres) GetMembership(ctx context.Context, workspaceID, userID string) (*model.Membership, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var membership model.Membership
+	err := db.First(&membership, "workspace_id = ? AND user_id = ?", workspaceID, userID).Error
+	return &membership, err
+}
+
+// UpdateMember sets the 'role' of a user with 'userID' in a workspace with 'workspaceID'
+func (p *Postgres) UpdateMember(ctx context.Context, workspaceID, userID, role string) (*model.Membership, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var membership model.Membership
+	result := db.Model(&membership).Clauses(clause.Returning{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Update("role", role)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return &membership, gorm.ErrRecordNotFound
+	}
+	return &membership, result.Error
+}
+
+// RemoveMember removes a user with 'userID' from a workspace with 'workspaceID'.
+// The links of the workspace are kept
+func (p *Postgres) RemoveMember(ctx context.Context, workspaceID, userID string) (*model.Membership, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var membership model.Membership
+	result := db.Clauses(clause.Returning{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&membership)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return &membership, gorm.ErrRecordNotFound
+	}
+	return &membership, result.Error
+}
+
+// TransferWorkspace makes a member with 'toUserID' the owner of a workspace, the previous
+// owner with 'fromUserID' stays on as an editor
+func (p *Postgres) TransferWorkspace(ctx context.Context, workspaceID, fromUserID, toUserID string) (*model.Workspace, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	workspace := model.Workspace{ID: workspaceID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Membership{}).
+			Where("workspace_id = ? AND user_id = ?", workspaceID, fromUserID).
+			Update("role", constant.WorkspaceEditor).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Membership{}).
+			Where("workspace_id = ? AND user_id = ?", workspaceID, toUserID).
+			Update("role", constant.WorkspaceOwner)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&workspace).Clauses(clause.Returning{}).Update("owner_id", toUserID).Error
+	})
+	return &workspace, err
+}
+
+// CreateInvitation stores 'invitation' in the database
+func (p *Postgres) CreateInvitation(ctx context.Context, invitation *model.Invitation) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+	return db.Create(invitation).Error
+}
+
+// GetInvitation fetches an invitation from the database using its 'token'
+func (p *Postgres) GetInvitation(ctx context.Context, token string) (*model.Invitation, error) {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	var invitation model.Invitation
+	err := db.First(&invitation, "token = ?", token).Error
+	return &invitation, err
+}
+
+// AcceptInvitation marks 'invitation' as accepted and stores the resulting 'membership'. A user
+// who is already a member keeps their role if it ranks higher, 'membership' is then set to theirs
+func (p *Postgres) AcceptInvitation(ctx context.Context, invitation *model.Invitation, membership *model.Membership) error {
+	db, cancel := p.DBWithTimeout(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(invitation).Where("accepted_at IS NULL").Update("accepted_at", &now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var existing model.Membership
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("workspace_id = ? AND user_id = ?", membership.WorkspaceID, membership.UserID).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(membership).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if constant.WorkspaceRoles[existing.Role] >= constant.WorkspaceRoles[membership.Role] {
+			*membership = existing
+			return nil
+		}
+		membership.CreatedAt = existing.CreatedAt
+		return tx.Model(&existing).Update("role", membership.Role).Error
+	})
+}
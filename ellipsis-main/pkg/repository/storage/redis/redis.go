@@ -9,6 +9,7 @@ import (
 
 	"brief/internal/config"
 	"brief/internal/constant"
+	"brief/pkg/metrics"
 	"brief/utility"
 
 	"github.com/go-redis/redis/v8"
@@ -20,8 +21,7 @@ var (
 	Ctx = context.Background()
 )
 
-func SetupRedis() {
-	logger := log.New()
+func SetupRedis(logger *log.Logger) {
 	getConfig := config.GetConfig()
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%v:%v", getConfig.RedisHost, getConfig.RedisPort),
@@ -56,8 +56,7 @@ func SetupRedis() {
 }
 
 // StoreCounter stores the current value of the counter variable in redis
-func StoreCounter() {
-	logger := log.New()
+func StoreCounter(logger *log.Logger) {
 	rd := GetRedisDb()
 	err := rd.RedisSet(constant.CounterKey, utility.Counter)
 	if err != nil {
@@ -85,9 +84,17 @@ func (rdb *Redis) RedisSet(key string, value interface{}) error {
 
 func (rdb *Redis) RedisGet(key string) ([]byte, error) {
 	serialized, err := rdb.Rdb.Get(Ctx, key).Bytes()
+	if err == nil || errors.Is(err, redis.Nil) {
+		metrics.CacheRequests.WithLabelValues("redis", metrics.Result(err == nil)).Inc()
+	}
 	return serialized, err
 }
 
+// Ping checks that redis accepts connections
+func (rdb *Redis) Ping(ctx context.Context) error {
+	return rdb.Rdb.Ping(ctx).Err()
+}
+
 func (rdb *Redis) RedisDelete(key string) (int64, error) {
 	deleted, err := rdb.Rdb.Del(Ctx, key).Result()
 	if err != nil {
@@ -3,6 +3,7 @@ package storage
 import (
 	"brief/internal/model"
 	"context"
+	"time"
 )
 
 // repositories
@@ -14,20 +15,114 @@ type StorageRepository interface {
 	UpdateUser(ctx context.Context, id string, user *model.User) error
 	ResetPassword(ctx context.Context, id string, rp *model.ResetPassword) (*model.User, error)
 	LockUnlock(ctx context.Context, idOrEmail string, isLocked bool) (*model.User, error)
+	AssignRole(ctx context.Context, change *model.RoleChange) (*model.User, error)
+	GetRoleChanges(ctx context.Context, userID string) ([]model.RoleChange, error)
+	DeleteUser(ctx context.Context, id string) (*model.User, error)
+	GetDeletedUser(ctx context.Context, idOrEmail string) (*model.User, error)
+	GetDeletedUsers(ctx context.Context) ([]model.User, error)
+	RestoreUser(ctx context.Context, id string) (*model.User, error)
+	PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+	PurgeUser(ctx context.Context, id string) error
+	AnonymizeUser(ctx context.Context, id, email string) (*model.User, error)
 
 	// URL
 	CreateURL(ctx context.Context, url *model.URL) error
-	GetURL(ctx context.Context, hash string) (*model.URL, error)
+	GetURL(ctx context.Context, domainID, hash string) (*model.URL, error)
 	GetURLById(ctx context.Context, id string) (*model.URL, error)
 	GetUrls(ctx context.Context, userID string) ([]model.URL, error)
+	GetWorkspaceUrls(ctx context.Context, workspaceID string) ([]model.URL, error)
+	SetURLWorkspace(ctx context.Context, id, workspaceID string) (*model.URL, error)
 	GetAll(ctx context.Context) ([]model.URL, error)
 	DeleteUrl(ctx context.Context, id string) (*model.URL, error)
+	GetDeletedURL(ctx context.Context, id string) (*model.URL, error)
+	GetDeletedUrls(ctx context.Context, userID string) ([]model.URL, error)
+	RestoreUrl(ctx context.Context, id string) (*model.URL, error)
+	PurgeUrls(ctx context.Context, deletedBefore time.Time) (int64, error)
+	TransferUrls(ctx context.Context, fromUserID, toUserID string) (int64, error)
+	GetRules(ctx context.Context, urlID string) ([]model.RedirectRule, error)
+	ReplaceRules(ctx context.Context, urlID string, rules []model.RedirectRule) error
+	CreateClick(ctx context.Context, click *model.Click) error
+	GetUserClicks(ctx context.Context, userID string) ([]model.Click, error)
+	GetClicks(ctx context.Context, urlID string) ([]model.Click, error)
+	ReplaceVariants(ctx context.Context, urlID string, variants []model.Variant) error
+	GetVariantStats(ctx context.Context, urlID string) ([]model.VariantStats, error)
+	GetUrlsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error)
+	RecordLinkCheck(ctx context.Context, url *model.URL, check *model.LinkCheck, keep int) error
+	GetBrokenUrls(ctx context.Context, userID string) ([]model.URL, error)
+	GetLinkChecks(ctx context.Context, urlID string, limit int) ([]model.LinkCheck, error)
+	GetLatestLinkChecks(ctx context.Context, urlIDs []string) ([]model.LinkCheck, error)
+
+	// Campaign
+	CreateCampaign(ctx context.Context, campaign *model.Campaign) error
+	GetCampaign(ctx context.Context, id string) (*model.Campaign, error)
+	GetCampaigns(ctx context.Context, userID string) ([]model.Campaign, error)
+	UpdateCampaign(ctx context.Context, id string, campaign *model.Campaign) error
+	DeleteCampaign(ctx context.Context, id string) (*model.Campaign, error)
+	GetCampaignUrls(ctx context.Context, campaignID string) ([]model.URL, error)
+
+	// Domain
+	CreateDomain(ctx context.Context, domain *model.Domain) error
+	GetDomain(ctx context.Context, id string) (*model.Domain, error)
+	GetDomainByHost(ctx context.Context, host string) (*model.Domain, error)
+	GetDomains(ctx context.Context, userID string) ([]model.Domain, error)
+	VerifyDomain(ctx context.Context, id string) (*model.Domain, error)
+	DeleteDomain(ctx context.Context, id string) (*model.Domain, error)
+
+	// Workspace
+	CreateWorkspace(ctx context.Context, workspace *model.Workspace) error
+	GetWorkspace(ctx context.Context, id string) (*model.Workspace, error)
+	GetWorkspaces(ctx context.Context, userID string) ([]model.Workspace, error)
+	GetMembers(ctx context.Context, workspaceID string) ([]model.Membership, error)
+	GetMembership(ctx context.Context, workspaceID, userID string) (*model.Membership, error)
+	UpdateMember(ctx context.Context, workspaceID, userID, role string) (*model.Membership, error)
+	RemoveMember(ctx context.Context, workspaceID, userID string) (*model.Membership, error)
+	TransferWorkspace(ctx context.Context, workspaceID, fromUserID, toUserID string) (*model.Workspace, error)
+	CreateInvitation(ctx context.Context, invitation *model.Invitation) error
+	GetInvitation(ctx context.Context, token string) (*model.Invitation, error)
+	AcceptInvitation(ctx context.Context, invitation *model.Invitation, membership *model.Membership) error
+
+	// Audit
+	CreateAuditLog(ctx context.Context, entry *model.AuditLog) error
+	GetAuditLogs(ctx context.Context, filter *model.AuditFilter) ([]model.AuditLog, error)
+	StreamAuditLogs(ctx context.Context, filter *model.AuditFilter, fn func(entry *model.AuditLog) error) error
+
+	// Webhook
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) error
+	GetWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	GetWebhooks(ctx context.Context, userID string) ([]model.Webhook, error)
+	GetSubscribedWebhooks(ctx context.Context, userID, event string) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error)
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+
+	// Event outbox
+	CreateOutboxEvents(ctx context.Context, events []model.OutboxEvent) error
+	ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error)
+	UpdateOutboxEvent(ctx context.Context, event *model.OutboxEvent) error
+
+	// Job
+	CreateJob(ctx context.Context, job *model.Job) error
+	ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Job, error)
+	UpdateJob(ctx context.Context, job *model.Job) error
+	AcquireLease(ctx context.Context, name, holder string, now, expiresAt time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+
+	// Transaction runs 'fn' with a repository whose queries are part of one transaction, which is
+	// committed when 'fn' returns nil and rolled back otherwise
+	Transaction(ctx context.Context, fn func(tx StorageRepository) error) error
+
+	// Health
+	Ping(ctx context.Context) error
 }
 
 type RedisRepository interface {
 	RedisSet(key string, value interface{}) error
 	RedisGet(key string) ([]byte, error)
 	RedisDelete(key string) (int64, error)
+	Ping(ctx context.Context) error
 }
 
 // repositories
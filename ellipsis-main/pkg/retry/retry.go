@@ -0,0 +1,84 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Outcomes of an attempt
+const (
+	Succeeded = "succeeded"
+	Retried   = "retried"
+	Failed    = "failed"
+)
+
+// Policy retries failed attempts with a backoff doubling from 'Base' up to 'Max', until
+// 'MaxAttempts' attempts were made
+type Policy struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// Backoff returns how long to wait before the next attempt after 'attempts' failed ones
+func (p Policy) Backoff(attempts int) time.Duration {
+	backoff := p.Base
+	for i := 1; i < attempts && backoff < p.Max; i++ {
+		backoff *= 2
+	}
+	if backoff > p.Max {
+		backoff = p.Max
+	}
+	return backoff
+}
+
+// Next returns the outcome of the attempt number 'attempts' made at 'now' which ended with 'err',
+// and when to make the next attempt if it is retried
+func (p Policy) Next(attempts int, err error, now time.Time) (string, time.Time) {
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		return Succeeded, time.Time{}
+	case attempts >= p.MaxAttempts || errors.As(err, &permanent):
+		return Failed, time.Time{}
+	default:
+		return Retried, now.Add(p.Backoff(attempts))
+	}
+}
+
+// permanentError is an error that a later attempt would end with too
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks 'err' as one that a later attempt would end with too, so that it is not retried
+func Permanent(err error) error {
+	return &permanentError{err}
+}
+
+// Poll calls 'batch' every 'interval' until 'ctx' is done, a batch of 'size' items is followed by
+// the next one right away. 'onError' is given the errors of batches made before 'ctx' is done
+func Poll(ctx context.Context, interval time.Duration, size int, batch func(ctx context.Context, now time.Time) (int, error), onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := batch(ctx, time.Now())
+			if err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+			if size <= 0 || n < size || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
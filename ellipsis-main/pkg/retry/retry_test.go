@@ -0,0 +1,79 @@
+// build+ unit
+package retry_test
+
+import (
+	"brief/pkg/retry"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := retry.Policy{Base: 30 * time.Second, Max: 6 * time.Hour}
+
+	tests := []struct {
+		Attempts int
+		Expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{20, 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		if got := policy.Backoff(test.Attempts); got != test.Expected {
+			t.Errorf("Expected '%s', got '%s'", test.Expected, got)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	policy := retry.Policy{Base: time.Second, Max: time.Minute, MaxAttempts: 3}
+	now := time.Now()
+	failed := errors.New("failed")
+
+	tests := []struct {
+		Name     string
+		Attempts int
+		Err      error
+		Expected string
+		RetryAt  time.Time
+	}{
+		{"Succeeded", 1, nil, retry.Succeeded, time.Time{}},
+		{"Retried", 2, failed, retry.Retried, now.Add(2 * time.Second)},
+		{"Out_Of_Attempts", 3, failed, retry.Failed, time.Time{}},
+		{"Permanent", 1, fmt.Errorf("wrapped: %w", retry.Permanent(failed)), retry.Failed, time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			outcome, retryAt := policy.Next(test.Attempts, test.Err, now)
+			if outcome != test.Expected || !retryAt.Equal(test.RetryAt) {
+				t.Errorf("Expected '%s' at '%s', got '%s' at '%s'", test.Expected, test.RetryAt, outcome, retryAt)
+			}
+		})
+	}
+}
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var batches int32
+	// Full batches are followed by the next one right away, the third one is not full
+	retry.Poll(ctx, time.Hour, 10, func(ctx context.Context, now time.Time) (int, error) {
+		if atomic.AddInt32(&batches, 1) == 3 {
+			cancel()
+			return 3, nil
+		}
+		return 10, nil
+	}, func(err error) {
+		t.Errorf("Expected 'error' to be nil, got '%v'", err)
+	})
+
+	if n := atomic.LoadInt32(&batches); n != 3 {
+		t.Errorf("Expected '3' batches, got '%d'", n)
+	}
+}
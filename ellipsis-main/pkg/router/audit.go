@@ -0,0 +1,32 @@
+package router
+
+import (
+	"brief/internal/constant"
+	"brief/pkg/handler/audit"
+	mdw "brief/pkg/middleware"
+	"brief/pkg/repository/storage/postgres"
+	auditSrv "brief/service/audit"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// Audit registers audit log paths with router 'r'
+func Audit(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
+
+	// Use postgres database
+	pgDb := postgres.GetDB()
+	aService := auditSrv.NewAuditService(pgDb)
+	auditCtrl := audit.NewController(validate, logger, aService)
+
+	// Staff endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(mdw.RequirePermission(constant.PermAuditRead))
+
+		r.Get("/audit-logs", auditCtrl.GetAll)
+		r.Get("/audit-logs/export", auditCtrl.Export)
+	})
+
+	return r
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"brief/pkg/handler/campaign"
+	mdw "brief/pkg/middleware"
+	"brief/pkg/repository/storage/postgres"
+	campaignSrv "brief/service/campaign"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// Campaign registers campaign paths with router 'r'
+func Campaign(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
+
+	// Use postgres database
+	pgDb := postgres.GetDB()
+	cService := campaignSrv.NewCampaignService(pgDb)
+	campaignCtrl := campaign.NewController(validate, logger, cService)
+
+	// User endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(mdw.Me) // user middleware
+
+		r.Post("/campaigns", campaignCtrl.Create)
+		r.Get("/campaigns", campaignCtrl.GetAll)
+		r.Get("/campaigns/{id}", campaignCtrl.Get)
+		r.Patch("/campaigns/{id}", campaignCtrl.Update)
+		r.Delete("/campaigns/{id}", campaignCtrl.Delete)
+		r.Get("/campaigns/{id}/urls", campaignCtrl.GetUrls)
+	})
+
+	return r
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"brief/pkg/handler/domain"
+	mdw "brief/pkg/middleware"
+	"brief/pkg/repository/storage/postgres"
+	domainSrv "brief/service/domain"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// Domain registers custom domain paths with router 'r'
+func Domain(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
+
+	// Use postgres database
+	pgDb := postgres.GetDB()
+	dService := domainSrv.NewDomainService(pgDb, domainSrv.NewResolver())
+	domainCtrl := domain.NewController(validate, logger, dService)
+
+	// User endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(mdw.Me) // user middleware
+
+		r.Post("/domains", domainCtrl.Add)
+		r.Get("/domains", domainCtrl.GetAll)
+		r.Get("/domains/{id}/challenge", domainCtrl.Challenge)
+		r.Post("/domains/{id}/verify", domainCtrl.Verify)
+		r.Delete("/domains/{id}", domainCtrl.Delete)
+	})
+
+	return r
+}
@@ -2,20 +2,22 @@ package router
 
 import (
 	"brief/pkg/handler/health"
-	healthSrv "brief/service/ping"
+	healthSrv "brief/service/health"
+	"fmt"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	log "github.com/sirupsen/logrus"
 )
 
-func Health(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
+// Health registers the probes at the root of 'r', where orchestrators expect them
+func Health(r chi.Router, validate *validator.Validate, logger *log.Logger, hService healthSrv.HealthService, apiVersion string) chi.Router {
 
-	hService := healthSrv.NewHealthService()
 	health := health.NewController(validate, logger, hService)
 
-	r.Post("/health", health.Post)
-	r.Get("/health", health.Get)
+	r.Get("/livez", health.Live)
+	r.Get("/readyz", health.Ready)
+	r.Get(fmt.Sprintf("/api/%s/health", apiVersion), health.Ready)
 
 	return r
 }
@@ -1,7 +1,6 @@
 package router
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -17,13 +16,32 @@ import (
 	"github.com/go-chi/cors"
 
 	_ "brief/docs"
+	"brief/internal/apperror"
+	"brief/internal/config"
+	"brief/pkg/metrics"
+	mdw "brief/pkg/middleware"
+	"brief/pkg/repository/storage/postgres"
+	healthSrv "brief/service/health"
+	"brief/utility"
 )
 
-func Setup(validate *validator.Validate, logger *log.Logger) chi.Router {
+func Setup(validate *validator.Validate, logger *log.Logger, hService healthSrv.HealthService) chi.Router {
 	r := chi.NewRouter()
 
+	trustedProxies, err := utility.ParseTrustedProxies(config.GetConfig().TrustedProxies)
+	if err != nil {
+		logger.Fatalf("could not parse trusted proxies, got error: %s", err)
+	}
+
+	// Authenticated requests carry the role users have in the database
+	mdw.SetUsers(postgres.GetDB())
+
 	// Middlewares
-	r.Use(middleware.Logger)
+	r.Use(mdw.RealIP(trustedProxies))
+	r.Use(mdw.RequestID)
+	r.Use(mdw.Tracing)
+	r.Use(mdw.Metrics)
+	r.Use(mdw.Logger(logger))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(5))
 	r.Use(middleware.Timeout(60 * time.Second))
@@ -32,8 +50,8 @@ func Setup(validate *validator.Validate, logger *log.Logger) chi.Router {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Token", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Token", "Content-Type", "X-CSRF-Token", mdw.RequestIDHeader},
+		ExposedHeaders:   []string{"Link", mdw.RequestIDHeader},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -46,14 +64,26 @@ func Setup(validate *validator.Validate, logger *log.Logger) chi.Router {
 		w.Write([]byte("Server is running"))
 	})
 
+	// Liveness and readiness probes
+	Health(r, validate, logger, hService, ApiVersion)
+
+	// Metrics endpoint, unless it is served on its own address
+	if config.GetConfig().MetricsAddr == "" {
+		r.With(mdw.RequireToken(config.GetConfig().MetricsToken)).Handle("/metrics", metrics.Handler())
+	}
+
 	// Redirect Endpoint
 	Redirect(r, validate, logger)
 
 	// Endpoints starting with "/api/v1"
 	r.Route(fmt.Sprintf("/api/%s", ApiVersion), func(r chi.Router) {
-		Health(r, validate, logger)
 		User(r, validate, logger)
 		Url(r, validate, logger)
+		Campaign(r, validate, logger)
+		Domain(r, validate, logger)
+		Workspace(r, validate, logger)
+		Audit(r, validate, logger)
+		Webhook(r, validate, logger)
 	})
 
 	// Swagger endpoint
@@ -63,15 +93,7 @@ func Setup(validate *validator.Validate, logger *log.Logger) chi.Router {
 
 	// Not found
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		res := map[string]interface{}{
-			"name":    "Not Found",
-			"message": "Page not found.",
-			"code":    404,
-			"status":  http.StatusNotFound,
-		}
-		w.WriteHeader(http.StatusNotFound)
-		resV, _ := json.Marshal(res)
-		w.Write(resV)
+		utility.WriteError(w, r, apperror.NotFound(apperror.CodeNotFound, "page not found"))
 	})
 
 	return r
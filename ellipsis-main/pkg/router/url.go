@@ -1,6 +1,9 @@
 package router
 
 import (
+	"brief/internal/constant"
+	"brief/pkg/events"
+	"brief/pkg/geoip"
 	"brief/pkg/handler/url"
 	mdw "brief/pkg/middleware"
 	"brief/pkg/repository/storage/postgres"
@@ -15,11 +18,12 @@ import (
 func Redirect(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
 	// Use postgres database
 	pgDb := postgres.GetDB()
-	uService := urlSrv.NewUrlService(pgDb)
+	uService := urlSrv.NewUrlService(pgDb, geoip.GetLocator(), events.GetBus())
 	urlCtrl := url.NewController(validate, logger, uService)
 
 	r.Group(func(r chi.Router) {
 		r.Get("/{hash}", urlCtrl.Redirect)
+		r.Get("/{hash}/*", urlCtrl.Redirect)
 	})
 
 	return r
@@ -30,7 +34,7 @@ func Url(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Rou
 
 	// Use postgres database
 	pgDb := postgres.GetDB()
-	uService := urlSrv.NewUrlService(pgDb)
+	uService := urlSrv.NewUrlService(pgDb, geoip.GetLocator(), events.GetBus())
 	urlCtrl := url.NewController(validate, logger, uService)
 
 	// Shorten endpoint
@@ -45,11 +49,21 @@ func Url(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Rou
 
 		r.Get("/url", urlCtrl.GetUrls)
 		r.Delete("/url/{id}", urlCtrl.Delete)
+		r.Get("/url/trash", urlCtrl.Trash)
+		r.Get("/url/broken", urlCtrl.GetBrokenLinks)
+		r.Patch("/url/{id}/restore", urlCtrl.Restore)
+		r.Patch("/url/{id}/workspace", urlCtrl.MoveToWorkspace)
+		r.Get("/url/{id}/rules", urlCtrl.GetRules)
+		r.Put("/url/{id}/rules", urlCtrl.SetRules)
+		r.Get("/url/{id}/variants", urlCtrl.GetVariants)
+		r.Put("/url/{id}/variants", urlCtrl.SetVariants)
+		r.Get("/url/{id}/stats", urlCtrl.GetStats)
+		r.Get("/url/{id}/checks", urlCtrl.GetLinkChecks)
 	})
 
-	// Admin endpoints
+	// Staff endpoints
 	r.Group(func(r chi.Router) {
-		r.Use(mdw.Admin) // admin middleware
+		r.Use(mdw.RequirePermission(constant.PermURLReadAny))
 
 		r.Get("/url/get-all", urlCtrl.GetAll)
 		r.Get("/url/{user-id}", urlCtrl.GetUrlsByUserID)
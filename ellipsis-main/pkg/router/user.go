@@ -1,6 +1,8 @@
 package router
 
 import (
+	"brief/internal/constant"
+	"brief/pkg/events"
 	"brief/pkg/handler/user"
 	mdw "brief/pkg/middleware"
 	"brief/pkg/repository/storage/postgres"
@@ -16,12 +18,9 @@ func User(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Ro
 
 	// Use postgres database
 	pgDb := postgres.GetDB()
-	uService := userSrv.NewUserService(pgDb)
+	uService := userSrv.NewUserService(pgDb, events.GetBus())
 	userCtrl := user.NewController(validate, logger, uService)
 
-	// Create admin user
-	uService.CreateAdminUser(logger)
-
 	// Free endpoints
 	r.Group(func(r chi.Router) {
 		r.Post("/users", userCtrl.Register)
@@ -35,16 +34,21 @@ func User(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Ro
 		r.Get("/users", userCtrl.GetMe)
 		r.Patch("/users", userCtrl.UpdateMe)
 		r.Patch("/users/reset-password", userCtrl.ResetPassword)
+		r.Delete("/users", userCtrl.DeleteMe)
+		r.Get("/users/export", userCtrl.ExportMe)
 	})
 
-	// Admin endpoints
+	// Staff endpoints
 	r.Group(func(r chi.Router) {
-		r.Use(mdw.Admin) // admin middleware
-
-		r.Get("/users/get-all", userCtrl.GetAll)
-		r.Get("/users/{idOrEmail}", userCtrl.GetUserByIdOrEmail)
-		r.Patch("/users/lock/{idOrEmail}", userCtrl.LockUser)
-		r.Patch("/users/unlock/{idOrEmail}", userCtrl.UnlockUser)
+		r.With(mdw.RequirePermission(constant.PermUserReadAny)).Get("/users/get-all", userCtrl.GetAll)
+		r.With(mdw.RequirePermission(constant.PermUserReadAny)).Get("/users/{idOrEmail}", userCtrl.GetUserByIdOrEmail)
+		r.With(mdw.RequirePermission(constant.PermUserLock)).Patch("/users/lock/{idOrEmail}", userCtrl.LockUser)
+		r.With(mdw.RequirePermission(constant.PermUserLock)).Patch("/users/unlock/{idOrEmail}", userCtrl.UnlockUser)
+		r.With(mdw.RequirePermission(constant.PermRoleAssign)).Patch("/users/{idOrEmail}/role", userCtrl.AssignRole)
+		r.With(mdw.RequirePermission(constant.PermAuditRead)).Get("/users/{idOrEmail}/role-changes", userCtrl.GetRoleChanges)
+		r.With(mdw.RequirePermission(constant.PermUserDeleteAny)).Get("/users/trash", userCtrl.Trash)
+		r.With(mdw.RequirePermission(constant.PermUserDeleteAny)).Delete("/users/{idOrEmail}", userCtrl.DeleteUser)
+		r.With(mdw.RequirePermission(constant.PermUserDeleteAny)).Patch("/users/{idOrEmail}/restore", userCtrl.RestoreUser)
 	})
 
 	return r
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"brief/pkg/handler/webhook"
+	mdw "brief/pkg/middleware"
+	"brief/pkg/repository/storage/postgres"
+	webhookSrv "brief/service/webhook"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// Webhook registers webhook paths with router 'r'
+func Webhook(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
+
+	// Use postgres database
+	pgDb := postgres.GetDB()
+	wService := webhookSrv.NewWebhookService(pgDb)
+	webhookCtrl := webhook.NewController(validate, logger, wService)
+
+	// User endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(mdw.Me) // user middleware
+
+		r.Post("/webhooks", webhookCtrl.Create)
+		r.Get("/webhooks", webhookCtrl.GetAll)
+		r.Delete("/webhooks/{id}", webhookCtrl.Delete)
+		r.Get("/webhooks/{id}/deliveries", webhookCtrl.GetDeliveries)
+		r.Post("/webhooks/{id}/deliveries/{delivery-id}/replay", webhookCtrl.Replay)
+	})
+
+	return r
+}
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"brief/pkg/handler/workspace"
+	mdw "brief/pkg/middleware"
+	"brief/pkg/repository/storage/postgres"
+	workspaceSrv "brief/service/workspace"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// Workspace registers workspace paths with router 'r'
+func Workspace(r chi.Router, validate *validator.Validate, logger *log.Logger) chi.Router {
+
+	// Use postgres database
+	pgDb := postgres.GetDB()
+	wService := workspaceSrv.NewWorkspaceService(pgDb)
+	workspaceCtrl := workspace.NewController(validate, logger, wService)
+
+	// User endpoints
+	r.Group(func(r chi.Router) {
+		r.Use(mdw.Me) // user middleware
+
+		r.Post("/workspaces", workspaceCtrl.Create)
+		r.Get("/workspaces", workspaceCtrl.GetAll)
+		r.Get("/workspaces/{id}/members", workspaceCtrl.GetMembers)
+		r.Patch("/workspaces/{id}/members/{user-id}", workspaceCtrl.UpdateMember)
+		r.Delete("/workspaces/{id}/members/{user-id}", workspaceCtrl.RemoveMember)
+		r.Post("/workspaces/{id}/invitations", workspaceCtrl.Invite)
+		r.Post("/workspaces/invitations/{token}/accept", workspaceCtrl.Accept)
+		r.Post("/workspaces/{id}/transfer", workspaceCtrl.Transfer)
+	})
+
+	return r
+}
@@ -0,0 +1,91 @@
+// Package tracing sets up OpenTelemetry tracing, spans are exported with the exporter chosen in
+// 'TRACING_EXPORTER'
+package tracing
+
+import (
+	"brief/internal/config"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "brief"
+
+// Exporters of spans
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout" // pretty printed JSON, for development
+	ExporterOTLP   = "otlp"   // OTLP over HTTP, to 'OTLP_ENDPOINT' or OTEL_EXPORTER_OTLP_ENDPOINT
+)
+
+// Setup installs the global tracer provider and propagator, and returns a function flushing
+// the spans left on shutdown. Spans are dropped when no exporter is configured
+func Setup(ctx context.Context, logger *log.Logger) (func(context.Context) error, error) {
+	getConfig := config.GetConfig()
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch getConfig.TracingExporter {
+	case ExporterNone:
+		logger.Info("TRACING NOT CONFIGURED")
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if getConfig.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(getConfig.OTLPEndpoint))
+		}
+		if getConfig.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter '%s', expected stdout or otlp", getConfig.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s exporter, got error: %w", getConfig.TracingExporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(tracerName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	logger.Infof("TRACING WITH %s EXPORTER", getConfig.TracingExporter)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span called 'name' as a child of the span in 'ctx'
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records 'err' on 'span' if it is not nil, then ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject writes the trace context of 'ctx' into the headers of an outgoing request
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/utility"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type AuditService interface {
+	GetAll(ctx context.Context, filter *model.AuditFilter) ([]model.AuditLog, error)
+	Export(ctx context.Context, filter *model.AuditFilter, w io.Writer) error
+}
+
+type auditService struct {
+	dbRepo storage.StorageRepository
+}
+
+func NewAuditService(dbRepo storage.StorageRepository) AuditService {
+	return &auditService{dbRepo: dbRepo}
+}
+
+// Record stores an audit log of 'action' performed on a target by the user in 'ctxInfo'.
+// 'before' and 'after' are snapshots of the target and may be nil. Failures are logged and do
+// not fail the action being audited. The log is stored even if 'ctx' has been cancelled since the
+// action completed
+func Record(ctx context.Context, dbRepo storage.StorageRepository, ctxInfo *model.ContextInfo, action, targetType, targetID string, before, after interface{}) {
+	entry := &model.AuditLog{
+		ID:         uuid.NewString(),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+		CreatedAt:  time.Now(),
+	}
+
+	if ctxInfo != nil {
+		entry.ActorID = ctxInfo.ID
+		entry.IP = ctxInfo.IP
+		entry.UserAgent = ctxInfo.UserAgent
+	}
+
+	if err := dbRepo.CreateAuditLog(utility.Detach(ctx), entry); err != nil {
+		log.Errorf("could not record audit log of '%s' on %s '%s', got error: %s", action, targetType, targetID, err)
+	}
+}
+
+// RequestInfo returns the details of a request 'r' made by an unauthenticated client, with
+// 'actorID' as the user it acts for if known
+func RequestInfo(r *http.Request, actorID string) *model.ContextInfo {
+	return &model.ContextInfo{
+		ID:        actorID,
+		IP:        utility.RemoteIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// GetAll contains business logic to fetch the audit logs matching 'filter', latest first
+func (a *auditService) GetAll(ctx context.Context, filter *model.AuditFilter) ([]model.AuditLog, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	entries, err := a.dbRepo.GetAuditLogs(ctx, filter)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get audit logs")
+	}
+
+	return entries, nil
+}
+
+// Export contains business logic to write every audit log matching 'filter' to 'w' as JSON
+// lines, oldest first
+func (a *auditService) Export(ctx context.Context, filter *model.AuditFilter, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	err := a.dbRepo.StreamAuditLogs(ctx, filter, func(entry *model.AuditLog) error {
+		return enc.Encode(entry)
+	})
+	if err != nil {
+		return apperror.Internal(err, "could not export audit logs")
+	}
+
+	return nil
+}
+
+// snapshot encodes the state 'v' of a target, nil values have no snapshot
+func snapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+
+	return b
+}
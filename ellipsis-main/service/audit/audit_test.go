@@ -0,0 +1,102 @@
+// build+ unit
+package audit_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/service/audit"
+	"brief/service/mock"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var mockStorage storage.StorageRepository = &mock.Repo{}
+var auditService audit.AuditService = audit.NewAuditService(mockStorage)
+
+// recorder keeps the audit logs it is asked to store
+type recorder struct {
+	mock.Repo
+	entries []*model.AuditLog
+}
+
+func (r *recorder) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestRecord(t *testing.T) {
+	repo := &recorder{}
+	ctxInfo := &model.ContextInfo{ID: "admin-id", IP: "10.0.0.1", UserAgent: "curl/8.0"}
+
+	audit.Record(context.Background(), repo, ctxInfo, constant.AuditUserLock, constant.AuditTargetUser, "test-id",
+		map[string]bool{"is_locked": false}, map[string]bool{"is_locked": true})
+	audit.Record(context.Background(), repo, nil, constant.AuditURLCreate, constant.AuditTargetURL, "url-id", (*model.URL)(nil), nil)
+
+	if len(repo.entries) != 2 {
+		t.Fatalf("Expected '2' audit logs, got '%v'", len(repo.entries))
+	}
+
+	entry := repo.entries[0]
+	if entry.ActorID != "admin-id" || entry.IP != "10.0.0.1" || entry.UserAgent != "curl/8.0" {
+		t.Errorf("Expected actor details from context, got '%v', '%v', '%v'", entry.ActorID, entry.IP, entry.UserAgent)
+	}
+
+	if string(entry.Before) != `{"is_locked":false}` || string(entry.After) != `{"is_locked":true}` {
+		t.Errorf("Expected snapshots to be recorded, got '%s' and '%s'", entry.Before, entry.After)
+	}
+
+	if repo.entries[1].Before != nil || repo.entries[1].After != nil {
+		t.Errorf("Expected nil values to have no snapshot, got '%s' and '%s'", repo.entries[1].Before, repo.entries[1].After)
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Limit    int
+		Expected int
+	}{
+		{"Default_Limit", 0, 100},
+		{"Custom_Limit", 10, 10},
+		{"Max_Limit", 5000, 1000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			filter := &model.AuditFilter{Limit: test.Limit}
+			if _, err := auditService.GetAll(context.Background(), filter); err != nil {
+				t.Errorf("Expected 'error' to be nil, got '%v'", err)
+			}
+
+			if filter.Limit != test.Expected {
+				t.Errorf("Expected 'limit' to be '%v', got '%v'", test.Expected, filter.Limit)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := auditService.Export(context.Background(), &model.AuditFilter{ActorID: "admin-id"}, &buf); err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected '2' lines, got '%v'", len(lines))
+	}
+
+	for _, line := range lines {
+		var entry model.AuditLog
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Errorf("Expected every line to be a JSON audit log, got error '%v'", err)
+		}
+		if entry.ActorID != "admin-id" {
+			t.Errorf("Expected 'actor_id' to be 'admin-id', got '%v'", entry.ActorID)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package campaign
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/utility"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CampaignService interface {
+	Create(ctx context.Context, campaign *model.Campaign, ctxInfo *model.ContextInfo) error
+	Get(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Campaign, error)
+	GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Campaign, error)
+	Update(ctx context.Context, ctxInfo *model.ContextInfo, id string, campaign *model.Campaign) error
+	Delete(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Campaign, error)
+	GetUrls(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.CampaignSummary, error)
+}
+
+type campaignService struct {
+	dbRepo storage.StorageRepository
+}
+
+func NewCampaignService(dbRepo storage.StorageRepository) CampaignService {
+	return &campaignService{dbRepo: dbRepo}
+}
+
+// Create contains business logic to create a campaign owned by the requesting user
+func (c *campaignService) Create(ctx context.Context, campaign *model.Campaign, ctxInfo *model.ContextInfo) error {
+	campaign.ID = uuid.NewString()
+	campaign.UserID = ctxInfo.ID
+	campaign.CreatedAt = time.Now()
+
+	if err := c.dbRepo.CreateCampaign(ctx, campaign); err != nil {
+		return apperror.Internal(err, "could not create campaign")
+	}
+
+	return nil
+}
+
+// Get contains business logic to fetch a campaign by its 'id'
+func (c *campaignService) Get(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Campaign, error) {
+	return c.authorize(ctx, ctxInfo, id)
+}
+
+// GetAll contains business logic to fetch all campaigns owned by the requesting user
+func (c *campaignService) GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Campaign, error) {
+	campaigns, err := c.dbRepo.GetCampaigns(ctx, ctxInfo.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get campaigns")
+	}
+
+	return campaigns, nil
+}
+
+// Update contains business logic to update a campaign's name and utm fields
+func (c *campaignService) Update(ctx context.Context, ctxInfo *model.ContextInfo, id string, campaign *model.Campaign) error {
+	if _, err := c.authorize(ctx, ctxInfo, id); err != nil {
+		return err
+	}
+
+	if err := c.dbRepo.UpdateCampaign(ctx, id, campaign); err != nil {
+		return apperror.Internal(err, "could not update campaign")
+	}
+
+	return nil
+}
+
+// Delete contains business logic to delete a campaign, its url's are kept but detached
+func (c *campaignService) Delete(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Campaign, error) {
+	if _, err := c.authorize(ctx, ctxInfo, id); err != nil {
+		return nil, err
+	}
+
+	campaign, err := c.dbRepo.DeleteCampaign(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeCampaignNotFound, "campaign not found")
+		}
+		return nil, apperror.Internal(err, "could not delete campaign")
+	}
+
+	return campaign, nil
+}
+
+// GetUrls contains business logic to list all url's belonging to a campaign
+func (c *campaignService) GetUrls(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.CampaignSummary, error) {
+	campaign, err := c.authorize(ctx, ctxInfo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := c.dbRepo.GetCampaignUrls(ctx, id)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get campaign urls")
+	}
+
+	return &model.CampaignSummary{
+		Campaign:  campaign,
+		LinkCount: len(urls),
+		Urls:      urls,
+	}, nil
+}
+
+// authorize fetches a campaign and ensures it can be accessed by the requesting user
+func (c *campaignService) authorize(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Campaign, error) {
+	campaign, err := c.dbRepo.GetCampaign(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeCampaignNotFound, "campaign not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch campaign")
+	}
+
+	if !utility.HasPermission(ctxInfo.Role, constant.PermCampaignManageAny) && campaign.UserID != ctxInfo.ID {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action")
+	}
+
+	return campaign, nil
+}
@@ -0,0 +1,58 @@
+// build+ unit
+package campaign_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/service/campaign"
+	"brief/service/mock"
+	"context"
+	"testing"
+)
+
+var mockStorage storage.StorageRepository = &mock.Repo{}
+var campaignService campaign.CampaignService = campaign.NewCampaignService(mockStorage)
+
+func TestCreate(t *testing.T) {
+	c := &model.Campaign{Name: "launch"}
+	if err := campaignService.Create(context.Background(), c, &model.ContextInfo{ID: "test-id"}); err != nil {
+		t.Errorf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	if c.ID == "" {
+		t.Errorf("Expected 'campaign.ID' to be not empty")
+	}
+
+	if c.UserID != "test-id" {
+		t.Errorf("Expected 'campaign.UserID' to be 'test-id', got '%v'", c.UserID)
+	}
+}
+
+func TestGetUrls(t *testing.T) {
+	uniformID := "test-id"
+	t.Run("Owner", func(t *testing.T) {
+		summary, err := campaignService.GetUrls(context.Background(), &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.User]}, uniformID)
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if summary != nil && summary.LinkCount != len(summary.Urls) {
+			t.Errorf("Expected 'link_count' to be '%v', got '%v'", len(summary.Urls), summary.LinkCount)
+		}
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		_, err := campaignService.GetUrls(context.Background(), &model.ContextInfo{ID: "admin", Role: constant.Roles[constant.Admin]}, uniformID)
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := campaignService.GetUrls(context.Background(), &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.User]}, "test-id-2")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/utility"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	MethodDNS  = "dns"
+	MethodHTTP = "http"
+
+	challengeRecordPrefix = "_brief-challenge."
+	challengeValuePrefix  = "brief-verification="
+	challengeFilePath     = "/.well-known/brief-verification.txt"
+)
+
+type DomainService interface {
+	Add(ctx context.Context, domain *model.Domain, ctxInfo *model.ContextInfo) (*model.DomainChallenge, error)
+	Challenge(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.DomainChallenge, error)
+	Verify(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Domain, error)
+	GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Domain, error)
+	Delete(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Domain, error)
+}
+
+type domainService struct {
+	dbRepo   storage.StorageRepository
+	resolver Resolver
+}
+
+func NewDomainService(dbRepo storage.StorageRepository, resolver Resolver) DomainService {
+	return &domainService{dbRepo: dbRepo, resolver: resolver}
+}
+
+// Add contains business logic to register a custom domain for the requesting user.
+// The domain cannot be used until it is verified
+func (d *domainService) Add(ctx context.Context, domain *model.Domain, ctxInfo *model.ContextInfo) (*model.DomainChallenge, error) {
+	domain.ID = uuid.NewString()
+	domain.Host = strings.ToLower(strings.TrimSuffix(domain.Host, "."))
+	domain.UserID = ctxInfo.ID
+	domain.Token = strings.ReplaceAll(uuid.NewString(), "-", "")
+	domain.Verified = false
+	domain.VerifiedAt = nil
+	domain.CreatedAt = time.Now()
+
+	if err := d.dbRepo.CreateDomain(ctx, domain); err != nil {
+		if err == gorm.ErrDuplicatedKey {
+			return nil, apperror.Conflict(apperror.CodeDomainTaken, "oops, '%s' is already registered", domain.Host)
+		}
+		return nil, apperror.Internal(err, "could not create domain")
+	}
+
+	return challenge(domain), nil
+}
+
+// Challenge contains business logic to fetch what has to be published to verify a domain
+func (d *domainService) Challenge(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.DomainChallenge, error) {
+	domain, err := d.authorize(ctx, ctxInfo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge(domain), nil
+}
+
+// Verify contains business logic to check the challenge of a domain and mark it as verified
+func (d *domainService) Verify(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Domain, error) {
+	domain, err := d.authorize(ctx, ctxInfo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.Verified {
+		return domain, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	c := challenge(domain)
+	switch domain.Method {
+	case MethodDNS:
+		records, err := d.resolver.LookupTXT(ctx, c.Record)
+		if err != nil {
+			return nil, apperror.Validation(apperror.CodeDomainVerificationFailed, "could not look up '%s'", c.Record).Wrap(err)
+		}
+
+		found := false
+		for _, record := range records {
+			if strings.TrimSpace(record) == c.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, apperror.Validation(apperror.CodeDomainVerificationFailed, "TXT record '%s' does not contain '%s'", c.Record, c.Value)
+		}
+	case MethodHTTP:
+		content, err := d.resolver.FetchFile(ctx, c.URL)
+		if err != nil {
+			return nil, apperror.Validation(apperror.CodeDomainVerificationFailed, "could not fetch '%s'", c.URL).Wrap(err)
+		}
+
+		if content != c.Value {
+			return nil, apperror.Validation(apperror.CodeDomainVerificationFailed, "'%s' does not contain '%s'", c.URL, c.Value)
+		}
+	default:
+		return nil, apperror.Validation(apperror.CodeInvalidOption, "unknown verification method '%s'", domain.Method)
+	}
+
+	verified, err := d.dbRepo.VerifyDomain(ctx, id)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not verify domain")
+	}
+
+	return verified, nil
+}
+
+// GetAll contains business logic to fetch all domains of the requesting user
+func (d *domainService) GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Domain, error) {
+	domains, err := d.dbRepo.GetDomains(ctx, ctxInfo.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get domains")
+	}
+
+	return domains, nil
+}
+
+// Delete contains business logic to delete a domain and the links issued under it
+func (d *domainService) Delete(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Domain, error) {
+	if _, err := d.authorize(ctx, ctxInfo, id); err != nil {
+		return nil, err
+	}
+
+	domain, err := d.dbRepo.DeleteDomain(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeDomainNotFound, "domain not found")
+		}
+		return nil, apperror.Internal(err, "could not delete domain")
+	}
+
+	return domain, nil
+}
+
+// authorize fetches a domain and ensures it can be managed by the requesting user
+func (d *domainService) authorize(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Domain, error) {
+	domain, err := d.dbRepo.GetDomain(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeDomainNotFound, "domain not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch domain")
+	}
+
+	if !utility.HasPermission(ctxInfo.Role, constant.PermDomainManageAny) && domain.UserID != ctxInfo.ID {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action")
+	}
+
+	return domain, nil
+}
+
+// challenge describes the record or file proving control over 'domain'
+func challenge(domain *model.Domain) *model.DomainChallenge {
+	c := &model.DomainChallenge{Domain: domain}
+	switch domain.Method {
+	case MethodDNS:
+		c.Record = challengeRecordPrefix + domain.Host
+		c.Value = challengeValuePrefix + domain.Token
+	case MethodHTTP:
+		c.URL = "http://" + domain.Host + challengeFilePath
+		c.Value = domain.Token
+	}
+	return c
+}
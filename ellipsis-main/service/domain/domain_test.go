@@ -0,0 +1,93 @@
+// build+ unit
+package domain_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/service/domain"
+	"brief/service/mock"
+	"context"
+	"fmt"
+	"testing"
+)
+
+var mockStorage storage.StorageRepository = &mock.Repo{}
+
+// fakeResolver serves challenge records from memory
+type fakeResolver struct {
+	txt   map[string][]string
+	files map[string]string
+}
+
+func (f *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := f.txt[name]
+	if !ok {
+		return nil, fmt.Errorf("no such host")
+	}
+	return records, nil
+}
+
+func (f *fakeResolver) FetchFile(ctx context.Context, url string) (string, error) {
+	content, ok := f.files[url]
+	if !ok {
+		return "", fmt.Errorf("got status code 404")
+	}
+	return content, nil
+}
+
+func TestAdd(t *testing.T) {
+	dService := domain.NewDomainService(mockStorage, &fakeResolver{})
+	challenge, err := dService.Add(context.Background(), &model.Domain{Host: "Go.Example.com.", Method: domain.MethodDNS}, &model.ContextInfo{ID: "test-id"})
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	if challenge.Domain.Host != "go.example.com" {
+		t.Errorf("Expected 'host' to be 'go.example.com', got '%v'", challenge.Domain.Host)
+	}
+
+	if challenge.Domain.Verified {
+		t.Errorf("Expected 'verified' to be false")
+	}
+
+	if expVal := "_brief-challenge.go.example.com"; challenge.Record != expVal {
+		t.Errorf("Expected 'record' to be '%v', got '%v'", expVal, challenge.Record)
+	}
+
+	if expVal := "brief-verification=" + challenge.Domain.Token; challenge.Value != expVal {
+		t.Errorf("Expected 'value' to be '%v', got '%v'", expVal, challenge.Value)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// The mock repository returns domains owned by a user with the same id, using the 'dns' method and token 'token'
+	uniformID := "test-id"
+	ctxInfo := &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.User]}
+
+	tests := []struct {
+		Name     string
+		Resolver *fakeResolver
+		CtxInfo  *model.ContextInfo
+		ErrIsNil bool
+	}{
+		{"Valid_Record", &fakeResolver{txt: map[string][]string{"_brief-challenge.go.example.com": {"other", "brief-verification=token"}}}, ctxInfo, true},
+		{"Wrong_Record", &fakeResolver{txt: map[string][]string{"_brief-challenge.go.example.com": {"brief-verification=nope"}}}, ctxInfo, false},
+		{"Missing_Record", &fakeResolver{}, ctxInfo, false},
+		{"Not_Owner", &fakeResolver{txt: map[string][]string{"_brief-challenge.go.example.com": {"brief-verification=token"}}}, &model.ContextInfo{ID: "other-id", Role: constant.Roles[constant.User]}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			dService := domain.NewDomainService(mockStorage, test.Resolver)
+			d, err := dService.Verify(context.Background(), test.CtxInfo, uniformID)
+			if (err == nil) != test.ErrIsNil {
+				t.Errorf("Expected 'error' not to be '%v'", err)
+			}
+
+			if err == nil && !d.Verified {
+				t.Errorf("Expected 'verified' to be true")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Resolver looks up the records a domain owner publishes to prove control over a domain
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	FetchFile(ctx context.Context, url string) (string, error)
+}
+
+type netResolver struct {
+	client *http.Client
+}
+
+// NewResolver returns a Resolver backed by the system's DNS resolver and an HTTP client
+func NewResolver() Resolver {
+	return &netResolver{
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+			// A challenge file must be served by the domain itself
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (n *netResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return net.DefaultResolver.LookupTXT(ctx, name)
+}
+
+func (n *netResolver) FetchFile(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("got status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
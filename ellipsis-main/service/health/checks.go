@@ -0,0 +1,54 @@
+package health
+
+import (
+	"brief/pkg/migrate"
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// Pinger is a dependency that can be pinged, such as the postgres and redis repositories
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that 'pinger' accepts connections
+func Ping(pinger Pinger) Checker {
+	return pinger.Ping
+}
+
+// MigrationLister lists the migrations not yet applied to the database
+type MigrationLister interface {
+	Pending(ctx context.Context) ([]migrate.Migration, error)
+}
+
+// Migrations checks that the database schema is at the version this instance expects, an instance
+// should not serve requests against a schema that is still being migrated
+func Migrations(lister MigrationLister) Checker {
+	return func(ctx context.Context) error {
+		pending, err := lister.Pending(ctx)
+		if err != nil {
+			return fmt.Errorf("could not list migrations, got error: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migrations pending, next is %d_%s", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	}
+}
+
+// Disk checks that the disk holding the file at 'path', such as the log file, has at least
+// 'minFree' bytes available
+func Disk(path string, minFree uint64) Checker {
+	dir := filepath.Dir(path)
+	return func(ctx context.Context) error {
+		free, err := freeSpace(dir)
+		if err != nil {
+			return fmt.Errorf("could not stat '%s', got error: %w", dir, err)
+		}
+		if free < minFree {
+			return fmt.Errorf("%d MB free in '%s', need %d MB", free>>20, dir, minFree>>20)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package health
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds a check registered without a timeout
+const DefaultTimeout = 2 * time.Second
+
+// Checker reports whether a dependency of the instance is usable
+type Checker func(ctx context.Context) error
+
+type HealthService interface {
+	// Register adds a readiness check called 'name', failing if it does not answer within 'timeout'
+	Register(name string, checker Checker, timeout time.Duration)
+	Live() *model.Health
+	Ready(ctx context.Context) *model.Health
+	// Drain marks the instance as unready, for load balancers to stop routing to it before shutdown
+	Drain()
+}
+
+type check struct {
+	name    string
+	checker Checker
+	timeout time.Duration
+}
+
+type healthService struct {
+	mu       sync.RWMutex
+	checks   []check
+	draining atomic.Bool
+}
+
+func NewHealthService() HealthService {
+	return &healthService{}
+}
+
+func (h *healthService) Register(name string, checker Checker, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, checker: checker, timeout: timeout})
+}
+
+// Live reports that the process is running, it does not check dependencies so that an instance
+// is not restarted because a database is down
+func (h *healthService) Live() *model.Health {
+	return &model.Health{Status: constant.HealthOK}
+}
+
+// Ready runs every check concurrently and reports the instance as ready when all pass and it is
+// not draining
+func (h *healthService) Ready(ctx context.Context) *model.Health {
+	h.mu.RLock()
+	checks := append([]check(nil), h.checks...)
+	h.mu.RUnlock()
+
+	health := &model.Health{Status: constant.HealthOK, Checks: make([]model.HealthCheck, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			health.Checks[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, c := range health.Checks {
+		if c.Status != constant.HealthOK {
+			health.Status = constant.HealthUnavailable
+		}
+	}
+	if h.draining.Load() {
+		health.Status = constant.HealthUnavailable
+	}
+
+	return health
+}
+
+func (h *healthService) Drain() {
+	h.draining.Store(true)
+}
+
+// run runs a single check within its timeout
+func run(ctx context.Context, c check) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- c.checker(ctx)
+	}()
+
+	// A checker that ignores its context must not hold up the report
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	status := model.HealthCheck{
+		Name:      c.name,
+		Status:    constant.HealthOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = constant.HealthUnavailable
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,124 @@
+// build+ unit
+package health_test
+
+import (
+	"brief/internal/constant"
+	"brief/pkg/migrate"
+	"brief/service/health"
+	"brief/service/mock"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	// Hangs regardless of its context, the report must not wait for it
+	hanging := func(ctx context.Context) error { time.Sleep(time.Second); return nil }
+
+	tests := []struct {
+		Name     string
+		Checker  health.Checker
+		Drain    bool
+		Expected string
+		Error    string
+	}{
+		{"All_Passing", ok, false, constant.HealthOK, ""},
+		{"Failing_Check", failing, false, constant.HealthUnavailable, "connection refused"},
+		{"Timed_Out", hanging, false, constant.HealthUnavailable, "timed out"},
+		{"Draining", ok, true, constant.HealthUnavailable, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			hService := health.NewHealthService()
+			hService.Register("postgres", health.Ping(&mock.Repo{}), 0)
+			hService.Register("dependency", test.Checker, 50*time.Millisecond)
+			if test.Drain {
+				hService.Drain()
+			}
+
+			report := hService.Ready(context.Background())
+			if report.Status != test.Expected {
+				t.Errorf("Expected '%s', got '%s'", test.Expected, report.Status)
+			}
+			if len(report.Checks) != 2 {
+				t.Fatalf("Expected '2' checks, got '%d'", len(report.Checks))
+			}
+			if report.Checks[0].Name != "postgres" || report.Checks[0].Status != constant.HealthOK {
+				t.Errorf("Expected 'postgres' to be '%s', got '%+v'", constant.HealthOK, report.Checks[0])
+			}
+			if !strings.Contains(report.Checks[1].Error, test.Error) {
+				t.Errorf("Expected '%s', got '%s'", test.Error, report.Checks[1].Error)
+			}
+			if report.Checks[1].LatencyMs > 500 {
+				t.Errorf("Expected the check to be bounded by its timeout, got '%d' ms", report.Checks[1].LatencyMs)
+			}
+
+			// Liveness does not depend on checks or draining
+			if live := hService.Live(); live.Status != constant.HealthOK {
+				t.Errorf("Expected '%s', got '%s'", constant.HealthOK, live.Status)
+			}
+		})
+	}
+}
+
+type lister []migrate.Migration
+
+func (l lister) Pending(ctx context.Context) ([]migrate.Migration, error) {
+	return l, nil
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Pending lister
+		Error   string
+	}{
+		{"Up_To_Date", nil, ""},
+		{"Pending", lister{{Version: 12, Name: "add_webhooks"}}, "1 migrations pending, next is 12_add_webhooks"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := health.Migrations(test.Pending)(context.Background())
+			if got := errString(err); got != test.Error {
+				t.Errorf("Expected '%s', got '%s'", test.Error, got)
+			}
+		})
+	}
+}
+
+func TestDisk(t *testing.T) {
+	path := os.TempDir() + "/brief.log"
+
+	tests := []struct {
+		Name    string
+		MinFree uint64
+		IsError bool
+	}{
+		{"Enough_Space", 0, false},
+		{"Not_Enough_Space", math.MaxUint64, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := health.Disk(path, test.MinFree)(context.Background())
+			if (err != nil) != test.IsError {
+				t.Errorf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
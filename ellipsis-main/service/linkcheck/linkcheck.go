@@ -0,0 +1,251 @@
+package linkcheck
+
+import (
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/pkg/jobs"
+	"brief/pkg/logging"
+	"brief/pkg/metrics"
+	"brief/pkg/netguard"
+	"brief/pkg/repository/storage"
+	"brief/pkg/tracing"
+	"brief/utility"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	urlPkg "net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// History is how many checks are kept for each url
+const History = 30
+
+var (
+	defaultInterval    = 24 * time.Hour
+	defaultConcurrency = 8
+	defaultHostDelay   = time.Second
+	requestTimeout     = 10 * time.Second
+	batchSize          = 200
+	// failureThreshold is how many checks must fail in a row for a link to be broken, so that a
+	// destination that is briefly down is not reported
+	failureThreshold = 2
+)
+
+// allowPrivate reports whether the checker may request loopback, private and link-local addresses
+func allowPrivate() bool {
+	cfg := config.GetConfig()
+	return cfg != nil && cfg.LinkCheckAllowPrivate
+}
+
+type LinkCheckService interface {
+	// Check checks the destinations of the urls due at 'now' and returns how many were checked
+	Check(ctx context.Context, now time.Time) (int, error)
+}
+
+type linkCheckService struct {
+	dbRepo      storage.StorageRepository
+	bus         events.Bus
+	client      *http.Client
+	interval    time.Duration
+	concurrency int
+	hostDelay   time.Duration
+}
+
+// NewLinkCheckService returns a checker revisiting each destination every 'interval', requesting
+// up to 'concurrency' of them at once and waiting 'hostDelay' between two requests to the same
+// host. Defaults are used for values that are not positive
+func NewLinkCheckService(dbRepo storage.StorageRepository, bus events.Bus, interval time.Duration, concurrency int, hostDelay time.Duration) LinkCheckService {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if hostDelay <= 0 {
+		hostDelay = defaultHostDelay
+	}
+
+	// Links are created by users, the server must not request its own network on their behalf.
+	// Redirects are followed, each of them is dialed through the same check
+	client := netguard.NewClient(requestTimeout, allowPrivate)
+	client.CheckRedirect = nil
+
+	return &linkCheckService{
+		dbRepo:      dbRepo,
+		bus:         bus,
+		client:      client,
+		interval:    interval,
+		concurrency: concurrency,
+		hostDelay:   hostDelay,
+	}
+}
+
+// Check contains business logic to request the destinations of the urls that were not checked
+// for an interval, in batches until none is due or 'ctx' is done. A link is broken once enough
+// checks failed in a row, its owner is then notified through the event bus
+func (lc *linkCheckService) Check(ctx context.Context, now time.Time) (int, error) {
+	checked := 0
+	for ctx.Err() == nil {
+		urls, err := lc.dbRepo.GetUrlsToCheck(ctx, now.Add(-lc.interval), batchSize)
+		if err != nil {
+			return checked, fmt.Errorf("could not get urls to check, got error: %w", err)
+		}
+
+		n := lc.checkBatch(ctx, urls, now)
+		checked += n
+		// A batch none of which could be stored would be fetched again
+		if len(urls) < batchSize || n == 0 {
+			break
+		}
+	}
+	return checked, ctx.Err()
+}
+
+// Schedule checks the due destinations every 10 minutes with 'runner', on one replica at a time
+func Schedule(runner *jobs.Runner, lc LinkCheckService) error {
+	runner.Register(constant.JobLinkCheck, jobs.Options{MaxAttempts: 1, Singleton: true},
+		func(ctx context.Context, job *model.Job) error {
+			checked, err := lc.Check(ctx, time.Now())
+			if checked > 0 {
+				logging.FromContext(ctx).Infof("link checker checked %d urls", checked)
+			}
+			return err
+		})
+	return runner.Schedule(constant.JobLinkCheck, "@every 10m")
+}
+
+// checkBatch checks 'urls' and returns how many checks were stored. The urls of a host are
+// checked one after the other, hosts concurrently
+func (lc *linkCheckService) checkBatch(ctx context.Context, urls []model.URL, now time.Time) int {
+	byHost := map[string][]*model.URL{}
+	for i := range urls {
+		host := urls[i].LongURL
+		if parsed, err := urlPkg.Parse(urls[i].LongURL); err == nil {
+			host = parsed.Hostname()
+		}
+		byHost[host] = append(byHost[host], &urls[i])
+	}
+
+	slots := make(chan struct{}, lc.concurrency)
+	var recorded int64
+	var wg sync.WaitGroup
+	for _, hostURLs := range byHost {
+		wg.Add(1)
+		go func(hostURLs []*model.URL) {
+			defer wg.Done()
+			for i, url := range hostURLs {
+				if i > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(lc.hostDelay):
+					}
+				}
+
+				slots <- struct{}{}
+				check := lc.probe(ctx, url.LongURL)
+				<-slots
+				if ctx.Err() != nil {
+					// The check was cut short, it says nothing about the link
+					return
+				}
+				if lc.record(ctx, url, check, now) {
+					atomic.AddInt64(&recorded, 1)
+				}
+			}
+		}(hostURLs)
+	}
+	wg.Wait()
+
+	return int(recorded)
+}
+
+// probe requests 'destination' once. Servers that do not allow HEAD requests are sent a GET
+func (lc *linkCheckService) probe(ctx context.Context, destination string) *model.LinkCheck {
+	ctx, span := tracing.Start(ctx, "linkcheck.Probe", attribute.String("url.destination", destination))
+	start := time.Now()
+	check := &model.LinkCheck{}
+
+	status, err := lc.request(ctx, http.MethodHead, destination)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = lc.request(ctx, http.MethodGet, destination)
+	}
+	tracing.End(span, err)
+
+	check.LatencyMs = time.Since(start).Milliseconds()
+	check.StatusCode = status
+	switch {
+	case err != nil:
+		check.Error = err.Error()
+	case status == http.StatusTooManyRequests:
+		// The host is up but asks to slow down, this says nothing about the link
+		check.OK = true
+	case status >= 400:
+		check.Error = fmt.Sprintf("got status code %d", status)
+	default:
+		check.OK = true
+	}
+	return check
+}
+
+// request sends a 'method' request to 'destination', following redirects, and returns the status
+// code of the final response
+func (lc *linkCheckService) request(ctx context.Context, method, destination string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, destination, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not build request, got error: %w", err)
+	}
+	req.Header.Set("User-Agent", "Brief-LinkChecker/1.0")
+
+	resp, err := lc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a bounded part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// record stores 'check' of 'url' and the link health it leads to, and reports whether it was stored
+func (lc *linkCheckService) record(ctx context.Context, url *model.URL, check *model.LinkCheck, now time.Time) bool {
+	check.ID = uuid.NewString()
+	check.URLID = url.ID
+	check.CheckedAt = now
+
+	wasBroken := url.LinkStatus == constant.LinkBroken
+	result := "ok"
+	if check.OK {
+		url.LinkStatus = constant.LinkOK
+		url.LinkFailures = 0
+	} else {
+		result = "failed"
+		url.LinkFailures++
+		url.LinkStatus = constant.LinkFailing
+		if url.LinkFailures >= failureThreshold {
+			url.LinkStatus = constant.LinkBroken
+		}
+	}
+	url.CheckedAt = &now
+	metrics.LinkChecks.WithLabelValues(result).Inc()
+
+	// The check is stored even if the checker is stopping
+	if err := lc.dbRepo.RecordLinkCheck(utility.Detach(ctx), url, check, History); err != nil {
+		logging.FromContext(ctx).Errorf("could not record check of url '%s', got error: %s", url.ID, err)
+		return false
+	}
+
+	if !wasBroken && url.LinkStatus == constant.LinkBroken {
+		lc.bus.Publish(ctx, events.URLBroken{URL: *url, Check: *check})
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+// build+ unit
+package linkcheck_test
+
+import (
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/service/linkcheck"
+	"brief/service/mock"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// links keeps urls and the checks of their destination in memory
+type links struct {
+	mock.Repo
+	mu     sync.Mutex
+	urls   map[string]model.URL
+	checks []model.LinkCheck
+}
+
+func newLinks(urls ...model.URL) *links {
+	l := &links{urls: map[string]model.URL{}}
+	for _, url := range urls {
+		l.urls[url.ID] = url
+	}
+	return l
+}
+
+func (l *links) GetUrlsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var due []model.URL
+	for _, url := range l.urls {
+		if url.CheckedAt == nil || url.CheckedAt.Before(checkedBefore) {
+			due = append(due, url)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (l *links) RecordLinkCheck(ctx context.Context, url *model.URL, check *model.LinkCheck, keep int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.urls[url.ID] = *url
+	l.checks = append(l.checks, *check)
+	return nil
+}
+
+func (l *links) url(id string) model.URL {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.urls[id]
+}
+
+// destinations answers HEAD and GET requests according to their path
+func destinations() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	return httptest.NewServer(mux)
+}
+
+// allowPrivate lets the checker request the loopback address of test servers until 't' is done
+func allowPrivate(t *testing.T, allow bool) {
+	cfg := config.Config
+	config.Config = &config.Configuration{LinkCheckAllowPrivate: allow}
+	t.Cleanup(func() { config.Config = cfg })
+}
+
+func TestCheck(t *testing.T) {
+	allowPrivate(t, true)
+	srv := destinations()
+	defer srv.Close()
+
+	tests := []struct {
+		Name       string
+		Path       string
+		Status     string // link status before the check
+		Failures   int    // checks failed in a row before this one
+		Expected   string
+		StatusCode int
+		Notified   bool
+	}{
+		{"OK", "/ok", "", 0, constant.LinkOK, 200, false},
+		{"Redirected", "/moved", "", 0, constant.LinkOK, 200, false},
+		{"HEAD_Not_Allowed", "/get-only", "", 0, constant.LinkOK, 200, false},
+		{"Rate_Limited", "/busy", constant.LinkOK, 0, constant.LinkOK, 429, false},
+		{"First_Failure", "/gone", constant.LinkOK, 0, constant.LinkFailing, 404, false},
+		{"Broken", "/gone", constant.LinkFailing, 1, constant.LinkBroken, 404, true},
+		{"Still_Broken", "/gone", constant.LinkBroken, 4, constant.LinkBroken, 404, false},
+		{"Recovered", "/ok", constant.LinkBroken, 3, constant.LinkOK, 200, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			repo := newLinks(model.URL{ID: "url-id", UserID: "test-id", LongURL: srv.URL + test.Path,
+				LinkStatus: test.Status, LinkFailures: test.Failures})
+			bus := events.NewBus()
+			var notified []events.URLBroken
+			bus.Subscribe(constant.DomainURLBroken, "test", events.Sync, func(ctx context.Context, event events.Event) error {
+				notified = append(notified, event.(events.URLBroken))
+				return nil
+			})
+
+			now := time.Now()
+			checker := linkcheck.NewLinkCheckService(repo, bus, time.Hour, 2, time.Millisecond)
+			if n, err := checker.Check(context.Background(), now); n != 1 || err != nil {
+				t.Fatalf("Expected '1' url checked, got '%d' and '%v'", n, err)
+			}
+
+			url := repo.url("url-id")
+			if url.LinkStatus != test.Expected {
+				t.Errorf("Expected '%s', got '%s'", test.Expected, url.LinkStatus)
+			}
+			if url.CheckedAt == nil || !url.CheckedAt.Equal(now) {
+				t.Errorf("Expected 'checked_at' to be '%s', got '%v'", now, url.CheckedAt)
+			}
+			if len(repo.checks) != 1 || repo.checks[0].StatusCode != test.StatusCode || repo.checks[0].URLID != "url-id" {
+				t.Errorf("Expected a check with status code '%d', got '%+v'", test.StatusCode, repo.checks)
+			}
+			if (len(notified) == 1) != test.Notified {
+				t.Errorf("Expected owner to be notified: '%v', got '%+v'", test.Notified, notified)
+			}
+		})
+	}
+
+	t.Run("Unreachable", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		repo := newLinks(model.URL{ID: "url-id", LongURL: closed.URL})
+
+		checker := linkcheck.NewLinkCheckService(repo, events.NewBus(), time.Hour, 2, time.Millisecond)
+		checker.Check(context.Background(), time.Now())
+
+		if url := repo.url("url-id"); url.LinkStatus != constant.LinkFailing || url.LinkFailures != 1 {
+			t.Errorf("Expected '%s' after '1' failure, got '%s' after '%d'", constant.LinkFailing, url.LinkStatus, url.LinkFailures)
+		}
+		if len(repo.checks) != 1 || repo.checks[0].OK || repo.checks[0].Error == "" {
+			t.Errorf("Expected a failed check with an error, got '%+v'", repo.checks)
+		}
+	})
+
+	t.Run("Private_Address", func(t *testing.T) {
+		allowPrivate(t, false)
+		repo := newLinks(model.URL{ID: "url-id", LongURL: srv.URL + "/ok"})
+
+		checker := linkcheck.NewLinkCheckService(repo, events.NewBus(), time.Hour, 2, time.Millisecond)
+		checker.Check(context.Background(), time.Now())
+
+		if len(repo.checks) != 1 || repo.checks[0].OK || !strings.Contains(repo.checks[0].Error, "not a public address") {
+			t.Errorf("Expected the loopback address not to be requested, got '%+v'", repo.checks)
+		}
+	})
+
+	t.Run("Not_Due", func(t *testing.T) {
+		checkedAt := time.Now().Add(-time.Minute)
+		repo := newLinks(model.URL{ID: "url-id", LongURL: srv.URL + "/ok", CheckedAt: &checkedAt})
+
+		checker := linkcheck.NewLinkCheckService(repo, events.NewBus(), time.Hour, 2, time.Millisecond)
+		if n, _ := checker.Check(context.Background(), time.Now()); n != 0 {
+			t.Errorf("Expected '0' urls checked, got '%d'", n)
+		}
+	})
+}
+
+func TestPoliteness(t *testing.T) {
+	allowPrivate(t, true)
+	var mu sync.Mutex
+	inFlight, maxInFlight := map[string]int{}, map[string]int{}
+	var total, maxTotal int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.Split(r.Host, ":")[0]
+		mu.Lock()
+		inFlight[host]++
+		total++
+		if inFlight[host] > maxInFlight[host] {
+			maxInFlight[host] = inFlight[host]
+		}
+		if total > maxTotal {
+			maxTotal = total
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[host]--
+		total--
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	// Two hosts reaching the same server
+	var urls []model.URL
+	for i, host := range []string{"127.0.0.1", "localhost"} {
+		base := strings.Replace(srv.URL, "127.0.0.1", host, 1)
+		for j := 0; j < 3; j++ {
+			urls = append(urls, model.URL{ID: fmt.Sprintf("url-%d", i*3+j), LongURL: base + "/page"})
+		}
+	}
+
+	tests := []struct {
+		Name        string
+		Concurrency int
+		MaxTotal    int
+	}{
+		{"Per_Host", 4, 2},
+		{"Concurrency", 1, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mu.Lock()
+			maxInFlight, maxTotal = map[string]int{}, 0
+			mu.Unlock()
+			repo := newLinks(urls...)
+
+			checker := linkcheck.NewLinkCheckService(repo, events.NewBus(), time.Hour, test.Concurrency, 5*time.Millisecond)
+			if n, err := checker.Check(context.Background(), time.Now()); n != len(urls) || err != nil {
+				t.Fatalf("Expected '%d' urls checked, got '%d' and '%v'", len(urls), n, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(maxInFlight) != 2 {
+				t.Errorf("Expected requests to '2' hosts, got '%v'", maxInFlight)
+			}
+			for host, n := range maxInFlight {
+				if n != 1 {
+					t.Errorf("Expected one request at a time to '%s', got '%d'", host, n)
+				}
+			}
+			if maxTotal > test.MaxTotal {
+				t.Errorf("Expected at most '%d' requests at once, got '%d'", test.MaxTotal, maxTotal)
+			}
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package mock
 
 import (
+	"brief/internal/constant"
 	"brief/internal/model"
+	"brief/pkg/repository/storage"
 	"context"
-	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type Repo struct{}
@@ -11,63 +17,507 @@ type Repo struct{}
 // User
 
 func (r *Repo) CreateUser(ctx context.Context, user *model.User) error {
-	fmt.Println("Hit CreateUser repo function...")
+	log.Debug("Hit CreateUser repo function...")
 	return nil
 }
 
+// GetUser gives users the role named by the prefix of 'idOrEmail' (e.g. 'moderator-1'), users
+// whose id has no such prefix are plain users
 func (r *Repo) GetUser(ctx context.Context, idOrEmail string) (*model.User, error) {
-	fmt.Println("Hit GetUser repo function...")
-	return &model.User{ID: idOrEmail, Email: idOrEmail}, nil
+	log.Debug("Hit GetUser repo function...")
+	user := &model.User{ID: idOrEmail, Email: idOrEmail, Role: constant.Roles[constant.User]}
+	for name, role := range constant.Roles {
+		if strings.HasPrefix(idOrEmail, name) {
+			user.Role = role
+		}
+	}
+	return user, nil
 }
 
 func (r *Repo) GetAllUsers(ctx context.Context) ([]model.User, error) {
-	fmt.Println("Hit GetAllUsers repo function...")
+	log.Debug("Hit GetAllUsers repo function...")
 	return []model.User{}, nil
 }
 
 func (r *Repo) UpdateUser(ctx context.Context, id string, user *model.User) error {
-	fmt.Println("Hit UpdateUser repo function...")
+	log.Debug("Hit UpdateUser repo function...")
 	return nil
 }
 
 func (r *Repo) ResetPassword(ctx context.Context, id string, rp *model.ResetPassword) (*model.User, error) {
-	fmt.Println("Hit ResetPassword repo function...")
+	log.Debug("Hit ResetPassword repo function...")
 	return &model.User{ID: id, Password: rp.Password, Salt: rp.Salt}, nil
 }
 
 func (r *Repo) LockUnlock(ctx context.Context, idOrEmail string, isLocked bool) (*model.User, error) {
-	fmt.Println("Hit LockUnlock repo function...")
+	log.Debug("Hit LockUnlock repo function...")
 	return &model.User{ID: idOrEmail, Email: idOrEmail, IsLocked: isLocked}, nil
 }
 
+func (r *Repo) AssignRole(ctx context.Context, change *model.RoleChange) (*model.User, error) {
+	log.Debug("Hit AssignRole repo function...")
+	change.OldRole = constant.Roles[constant.User]
+	return &model.User{ID: change.UserID, Role: change.NewRole}, nil
+}
+
+func (r *Repo) GetRoleChanges(ctx context.Context, userID string) ([]model.RoleChange, error) {
+	log.Debug("Hit GetRoleChanges repo function...")
+	return []model.RoleChange{{UserID: userID}}, nil
+}
+
 // URL
 
 func (r *Repo) CreateURL(ctx context.Context, url *model.URL) error {
-	fmt.Println("Hit CreateURL repo function...")
+	log.Debug("Hit CreateURL repo function...")
 	return nil
 }
 
-func (r *Repo) GetURL(ctx context.Context, hash string) (*model.URL, error) {
-	fmt.Println("Hit GetURL repo function...")
-	return &model.URL{Hash: hash}, nil
+func (r *Repo) GetURL(ctx context.Context, domainID, hash string) (*model.URL, error) {
+	log.Debug("Hit GetURL repo function...")
+	return &model.URL{Hash: hash, DomainID: domainID}, nil
 }
 
+// GetURLById puts urls whose id starts with 'workspace' in the 'workspace-id' workspace, other
+// urls belong to the user with their id
 func (r *Repo) GetURLById(ctx context.Context, id string) (*model.URL, error) {
-	fmt.Println("Hit GetURLById repo function...")
+	log.Debug("Hit GetURLById repo function...")
+	if strings.HasPrefix(id, "workspace") {
+		return &model.URL{ID: id, UserID: "owner-id", WorkspaceID: "workspace-id"}, nil
+	}
 	return &model.URL{ID: id, UserID: id}, nil
 }
 
 func (r *Repo) GetUrls(ctx context.Context, userID string) ([]model.URL, error) {
-	fmt.Println("Hit GetUrls repo function...")
+	log.Debug("Hit GetUrls repo function...")
 	return []model.URL{{UserID: userID}}, nil
 }
 
+func (r *Repo) GetWorkspaceUrls(ctx context.Context, workspaceID string) ([]model.URL, error) {
+	log.Debug("Hit GetWorkspaceUrls repo function...")
+	return []model.URL{{WorkspaceID: workspaceID}}, nil
+}
+
+func (r *Repo) SetURLWorkspace(ctx context.Context, id, workspaceID string) (*model.URL, error) {
+	log.Debug("Hit SetURLWorkspace repo function...")
+	return &model.URL{ID: id, WorkspaceID: workspaceID}, nil
+}
+
 func (r *Repo) GetAll(ctx context.Context) ([]model.URL, error) {
-	fmt.Println("Hit GetAll repo function...")
+	log.Debug("Hit GetAll repo function...")
 	return []model.URL{}, nil
 }
 
 func (r *Repo) DeleteUrl(ctx context.Context, id string) (*model.URL, error) {
-	fmt.Println("Hit DeleteUrl repo function...")
+	log.Debug("Hit DeleteUrl repo function...")
 	return &model.URL{ID: id}, nil
 }
+
+func (r *Repo) GetRules(ctx context.Context, urlID string) ([]model.RedirectRule, error) {
+	log.Debug("Hit GetRules repo function...")
+	return []model.RedirectRule{{URLID: urlID}}, nil
+}
+
+func (r *Repo) ReplaceRules(ctx context.Context, urlID string, rules []model.RedirectRule) error {
+	log.Debug("Hit ReplaceRules repo function...")
+	return nil
+}
+
+func (r *Repo) CreateClick(ctx context.Context, click *model.Click) error {
+	log.Debug("Hit CreateClick repo function...")
+	return nil
+}
+
+func (r *Repo) ReplaceVariants(ctx context.Context, urlID string, variants []model.Variant) error {
+	log.Debug("Hit ReplaceVariants repo function...")
+	return nil
+}
+
+func (r *Repo) GetVariantStats(ctx context.Context, urlID string) ([]model.VariantStats, error) {
+	log.Debug("Hit GetVariantStats repo function...")
+	return []model.VariantStats{{Variant: model.Variant{URLID: urlID}, Clicks: 1}}, nil
+}
+
+func (r *Repo) GetUrlsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error) {
+	log.Debug("Hit GetUrlsToCheck repo function...")
+	return []model.URL{}, nil
+}
+
+func (r *Repo) RecordLinkCheck(ctx context.Context, url *model.URL, check *model.LinkCheck, keep int) error {
+	log.Debug("Hit RecordLinkCheck repo function...")
+	return nil
+}
+
+func (r *Repo) GetBrokenUrls(ctx context.Context, userID string) ([]model.URL, error) {
+	log.Debug("Hit GetBrokenUrls repo function...")
+	return []model.URL{{ID: "url-id", UserID: userID, LinkStatus: constant.LinkBroken}}, nil
+}
+
+func (r *Repo) GetLinkChecks(ctx context.Context, urlID string, limit int) ([]model.LinkCheck, error) {
+	log.Debug("Hit GetLinkChecks repo function...")
+	return []model.LinkCheck{{ID: "check-id", URLID: urlID, StatusCode: 404}}, nil
+}
+
+func (r *Repo) GetLatestLinkChecks(ctx context.Context, urlIDs []string) ([]model.LinkCheck, error) {
+	log.Debug("Hit GetLatestLinkChecks repo function...")
+	checks := make([]model.LinkCheck, len(urlIDs))
+	for i, id := range urlIDs {
+		checks[i] = model.LinkCheck{ID: "check-id", URLID: id, StatusCode: 404}
+	}
+	return checks, nil
+}
+
+// Campaign
+
+func (r *Repo) CreateCampaign(ctx context.Context, campaign *model.Campaign) error {
+	log.Debug("Hit CreateCampaign repo function...")
+	return nil
+}
+
+func (r *Repo) GetCampaign(ctx context.Context, id string) (*model.Campaign, error) {
+	log.Debug("Hit GetCampaign repo function...")
+	return &model.Campaign{
+		ID:     id,
+		UserID: id,
+		UTM:    model.UTM{Source: "newsletter", Medium: "email", Campaign: "launch"},
+	}, nil
+}
+
+func (r *Repo) GetCampaigns(ctx context.Context, userID string) ([]model.Campaign, error) {
+	log.Debug("Hit GetCampaigns repo function...")
+	return []model.Campaign{{UserID: userID}}, nil
+}
+
+func (r *Repo) UpdateCampaign(ctx context.Context, id string, campaign *model.Campaign) error {
+	log.Debug("Hit UpdateCampaign repo function...")
+	return nil
+}
+
+func (r *Repo) DeleteCampaign(ctx context.Context, id string) (*model.Campaign, error) {
+	log.Debug("Hit DeleteCampaign repo function...")
+	return &model.Campaign{ID: id}, nil
+}
+
+func (r *Repo) GetCampaignUrls(ctx context.Context, campaignID string) ([]model.URL, error) {
+	log.Debug("Hit GetCampaignUrls repo function...")
+	return []model.URL{{CampaignID: campaignID}}, nil
+}
+
+// Domain
+
+func (r *Repo) CreateDomain(ctx context.Context, domain *model.Domain) error {
+	log.Debug("Hit CreateDomain repo function...")
+	return nil
+}
+
+func (r *Repo) GetDomain(ctx context.Context, id string) (*model.Domain, error) {
+	log.Debug("Hit GetDomain repo function...")
+	return &model.Domain{ID: id, UserID: id, Host: "go.example.com", Method: "dns", Token: "token"}, nil
+}
+
+func (r *Repo) GetDomainByHost(ctx context.Context, host string) (*model.Domain, error) {
+	log.Debug("Hit GetDomainByHost repo function...")
+	return &model.Domain{ID: host, Host: host, Verified: true}, nil
+}
+
+func (r *Repo) GetDomains(ctx context.Context, userID string) ([]model.Domain, error) {
+	log.Debug("Hit GetDomains repo function...")
+	return []model.Domain{{UserID: userID}}, nil
+}
+
+func (r *Repo) VerifyDomain(ctx context.Context, id string) (*model.Domain, error) {
+	log.Debug("Hit VerifyDomain repo function...")
+	return &model.Domain{ID: id, Verified: true}, nil
+}
+
+func (r *Repo) DeleteDomain(ctx context.Context, id string) (*model.Domain, error) {
+	log.Debug("Hit DeleteDomain repo function...")
+	return &model.Domain{ID: id}, nil
+}
+
+// Workspace
+
+func (r *Repo) CreateWorkspace(ctx context.Context, workspace *model.Workspace) error {
+	log.Debug("Hit CreateWorkspace repo function...")
+	return nil
+}
+
+func (r *Repo) GetWorkspace(ctx context.Context, id string) (*model.Workspace, error) {
+	log.Debug("Hit GetWorkspace repo function...")
+	return &model.Workspace{ID: id, OwnerID: id}, nil
+}
+
+func (r *Repo) GetWorkspaces(ctx context.Context, userID string) ([]model.Workspace, error) {
+	log.Debug("Hit GetWorkspaces repo function...")
+	return []model.Workspace{{OwnerID: userID}}, nil
+}
+
+func (r *Repo) GetMembers(ctx context.Context, workspaceID string) ([]model.Membership, error) {
+	log.Debug("Hit GetMembers repo function...")
+	return []model.Membership{{WorkspaceID: workspaceID, UserID: workspaceID, Role: constant.WorkspaceOwner}}, nil
+}
+
+// GetMembership makes a user a member of every workspace, with the role given by the prefix of
+// 'userID' (e.g. 'editor-1'). Users whose id has no such prefix are not members
+func (r *Repo) GetMembership(ctx context.Context, workspaceID, userID string) (*model.Membership, error) {
+	log.Debug("Hit GetMembership repo function...")
+	for role := range constant.WorkspaceRoles {
+		if strings.HasPrefix(userID, role) {
+			return &model.Membership{WorkspaceID: workspaceID, UserID: userID, Role: role}, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *Repo) UpdateMember(ctx context.Context, workspaceID, userID, role string) (*model.Membership, error) {
+	log.Debug("Hit UpdateMember repo function...")
+	return &model.Membership{WorkspaceID: workspaceID, UserID: userID, Role: role}, nil
+}
+
+func (r *Repo) RemoveMember(ctx context.Context, workspaceID, userID string) (*model.Membership, error) {
+	log.Debug("Hit RemoveMember repo function...")
+	return &model.Membership{WorkspaceID: workspaceID, UserID: userID}, nil
+}
+
+func (r *Repo) TransferWorkspace(ctx context.Context, workspaceID, fromUserID, toUserID string) (*model.Workspace, error) {
+	log.Debug("Hit TransferWorkspace repo function...")
+	return &model.Workspace{ID: workspaceID, OwnerID: toUserID}, nil
+}
+
+func (r *Repo) CreateInvitation(ctx context.Context, invitation *model.Invitation) error {
+	log.Debug("Hit CreateInvitation repo function...")
+	return nil
+}
+
+func (r *Repo) GetInvitation(ctx context.Context, token string) (*model.Invitation, error) {
+	log.Debug("Hit GetInvitation repo function...")
+	return &model.Invitation{
+		Token:     token,
+		Email:     token,
+		Role:      constant.WorkspaceEditor,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil
+}
+
+func (r *Repo) AcceptInvitation(ctx context.Context, invitation *model.Invitation, membership *model.Membership) error {
+	log.Debug("Hit AcceptInvitation repo function...")
+	return nil
+}
+
+func (r *Repo) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	log.Debug("Hit CreateAuditLog repo function...")
+	return nil
+}
+
+func (r *Repo) GetAuditLogs(ctx context.Context, filter *model.AuditFilter) ([]model.AuditLog, error) {
+	log.Debug("Hit GetAuditLogs repo function...")
+	return []model.AuditLog{{ActorID: filter.ActorID, Action: filter.Action}}, nil
+}
+
+func (r *Repo) StreamAuditLogs(ctx context.Context, filter *model.AuditFilter, fn func(entry *model.AuditLog) error) error {
+	log.Debug("Hit StreamAuditLogs repo function...")
+	for _, action := range []string{"user.login", "user.lock"} {
+		if err := fn(&model.AuditLog{ActorID: filter.ActorID, Action: action}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repo) DeleteUser(ctx context.Context, id string) (*model.User, error) {
+	log.Debug("Hit DeleteUser repo function...")
+	return &model.User{ID: id, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}, nil
+}
+
+// GetDeletedUser returns a user deleted long ago when 'idOrEmail' starts with 'expired'
+func (r *Repo) GetDeletedUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	log.Debug("Hit GetDeletedUser repo function...")
+	return &model.User{ID: idOrEmail, Email: idOrEmail, DeletedAt: deletedAt(idOrEmail)}, nil
+}
+
+func (r *Repo) GetDeletedUsers(ctx context.Context) ([]model.User, error) {
+	log.Debug("Hit GetDeletedUsers repo function...")
+	return []model.User{}, nil
+}
+
+func (r *Repo) RestoreUser(ctx context.Context, id string) (*model.User, error) {
+	log.Debug("Hit RestoreUser repo function...")
+	return &model.User{ID: id}, nil
+}
+
+func (r *Repo) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	log.Debug("Hit PurgeUsers repo function...")
+	return 0, nil
+}
+
+func (r *Repo) PurgeUser(ctx context.Context, id string) error {
+	log.Debug("Hit PurgeUser repo function...")
+	return nil
+}
+
+// GetDeletedURL returns a url of a user with the same id, deleted long ago when 'id' starts
+// with 'expired'
+func (r *Repo) GetDeletedURL(ctx context.Context, id string) (*model.URL, error) {
+	log.Debug("Hit GetDeletedURL repo function...")
+	return &model.URL{ID: id, UserID: id, DeletedAt: deletedAt(id)}, nil
+}
+
+func (r *Repo) GetDeletedUrls(ctx context.Context, userID string) ([]model.URL, error) {
+	log.Debug("Hit GetDeletedUrls repo function...")
+	return []model.URL{}, nil
+}
+
+func (r *Repo) RestoreUrl(ctx context.Context, id string) (*model.URL, error) {
+	log.Debug("Hit RestoreUrl repo function...")
+	return &model.URL{ID: id, UserID: id}, nil
+}
+
+func (r *Repo) PurgeUrls(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	log.Debug("Hit PurgeUrls repo function...")
+	return 0, nil
+}
+
+// deletedAt returns a deletion time a year ago for ids starting with 'expired', and an hour
+// ago otherwise
+func deletedAt(id string) gorm.DeletedAt {
+	if strings.HasPrefix(id, "expired") {
+		return gorm.DeletedAt{Time: time.Now().AddDate(-1, 0, 0), Valid: true}
+	}
+	return gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true}
+}
+
+func (r *Repo) AnonymizeUser(ctx context.Context, id, email string) (*model.User, error) {
+	log.Debug("Hit AnonymizeUser repo function...")
+	return &model.User{ID: id, Email: email, IsLocked: true}, nil
+}
+
+func (r *Repo) TransferUrls(ctx context.Context, fromUserID, toUserID string) (int64, error) {
+	log.Debug("Hit TransferUrls repo function...")
+	return 0, nil
+}
+
+func (r *Repo) GetClicks(ctx context.Context, urlID string) ([]model.Click, error) {
+	log.Debug("Hit GetClicks repo function...")
+	return []model.Click{
+		{URLID: urlID, Country: "NG", CreatedAt: time.Now().Add(-time.Hour)},
+		{URLID: urlID, Country: "GH", CreatedAt: time.Now()},
+		{URLID: urlID, CreatedAt: time.Now()},
+	}, nil
+}
+
+func (r *Repo) GetUserClicks(ctx context.Context, userID string) ([]model.Click, error) {
+	log.Debug("Hit GetUserClicks repo function...")
+	return []model.Click{
+		{URLID: userID, Country: "NG", CreatedAt: time.Now().Add(-time.Hour)},
+		{URLID: userID, Country: "NG", CreatedAt: time.Now()},
+	}, nil
+}
+
+// Webhook
+
+func (r *Repo) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	log.Debug("Hit CreateWebhook repo function...")
+	return nil
+}
+
+func (r *Repo) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	log.Debug("Hit GetWebhook repo function...")
+	return &model.Webhook{ID: id, UserID: id, URL: "https://example.com/hooks", Events: []string{constant.EventURLCreated}}, nil
+}
+
+func (r *Repo) GetWebhooks(ctx context.Context, userID string) ([]model.Webhook, error) {
+	log.Debug("Hit GetWebhooks repo function...")
+	return []model.Webhook{{UserID: userID, Secret: "secret"}}, nil
+}
+
+func (r *Repo) GetSubscribedWebhooks(ctx context.Context, userID, event string) ([]model.Webhook, error) {
+	log.Debug("Hit GetSubscribedWebhooks repo function...")
+	return []model.Webhook{{ID: userID, UserID: userID, Events: []string{event}}}, nil
+}
+
+func (r *Repo) DeleteWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	log.Debug("Hit DeleteWebhook repo function...")
+	return &model.Webhook{ID: id}, nil
+}
+
+func (r *Repo) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	log.Debug("Hit CreateDeliveries repo function...")
+	return nil
+}
+
+func (r *Repo) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	log.Debug("Hit GetDelivery repo function...")
+	return &model.WebhookDelivery{ID: id, WebhookID: id, Event: constant.EventURLCreated, Payload: []byte("{}")}, nil
+}
+
+func (r *Repo) GetDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	log.Debug("Hit GetDeliveries repo function...")
+	return []model.WebhookDelivery{{WebhookID: webhookID}}, nil
+}
+
+func (r *Repo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	log.Debug("Hit ClaimDeliveries repo function...")
+	return nil, nil
+}
+
+func (r *Repo) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	log.Debug("Hit UpdateDelivery repo function...")
+	return nil
+}
+
+// Event outbox
+
+func (r *Repo) CreateOutboxEvents(ctx context.Context, events []model.OutboxEvent) error {
+	log.Debug("Hit CreateOutboxEvents repo function...")
+	return nil
+}
+
+func (r *Repo) ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error) {
+	log.Debug("Hit ClaimOutboxEvents repo function...")
+	return nil, nil
+}
+
+func (r *Repo) UpdateOutboxEvent(ctx context.Context, event *model.OutboxEvent) error {
+	log.Debug("Hit UpdateOutboxEvent repo function...")
+	return nil
+}
+
+// Job
+
+func (r *Repo) CreateJob(ctx context.Context, job *model.Job) error {
+	log.Debug("Hit CreateJob repo function...")
+	return nil
+}
+
+func (r *Repo) ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	log.Debug("Hit ClaimJobs repo function...")
+	return nil, nil
+}
+
+func (r *Repo) UpdateJob(ctx context.Context, job *model.Job) error {
+	log.Debug("Hit UpdateJob repo function...")
+	return nil
+}
+
+func (r *Repo) AcquireLease(ctx context.Context, name, holder string, now, expiresAt time.Time) (bool, error) {
+	log.Debug("Hit AcquireLease repo function...")
+	return true, nil
+}
+
+func (r *Repo) ReleaseLease(ctx context.Context, name, holder string) error {
+	log.Debug("Hit ReleaseLease repo function...")
+	return nil
+}
+
+// Transaction
+
+func (r *Repo) Transaction(ctx context.Context, fn func(tx storage.StorageRepository) error) error {
+	log.Debug("Hit Transaction repo function...")
+	return fn(r)
+}
+
+// Health
+
+func (r *Repo) Ping(ctx context.Context) error {
+	log.Debug("Hit Ping repo function...")
+	return nil
+}
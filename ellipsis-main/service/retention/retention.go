@@ -0,0 +1,79 @@
+package retention
+
+import (
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/jobs"
+	"brief/pkg/logging"
+	"brief/pkg/repository/storage"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var defaultRetentionDays = 30
+
+type RetentionService interface {
+	Purge(ctx context.Context, now time.Time) (urls int64, users int64, err error)
+}
+
+type retentionService struct {
+	dbRepo storage.StorageRepository
+}
+
+func NewRetentionService(dbRepo storage.StorageRepository) RetentionService {
+	return &retentionService{dbRepo: dbRepo}
+}
+
+// Period returns how long deleted urls and users can be restored before they are purged
+func Period() time.Duration {
+	days := defaultRetentionDays
+	if cfg := config.GetConfig(); cfg != nil && cfg.RetentionDays > 0 {
+		days = cfg.RetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Expired reports whether an item deleted at 'deletedAt' is past the retention period
+func Expired(deletedAt gorm.DeletedAt) bool {
+	return deletedAt.Valid && time.Since(deletedAt.Time) > Period()
+}
+
+// Purge contains business logic to permanently delete the urls and users that have been in
+// the trash for longer than the retention period at 'now'
+func (rs *retentionService) Purge(ctx context.Context, now time.Time) (int64, int64, error) {
+	cutoff := now.Add(-Period())
+
+	// Users first, their urls are purged with them
+	users, err := rs.dbRepo.PurgeUsers(ctx, cutoff)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not purge users, got error: %w", err)
+	}
+
+	urls, err := rs.dbRepo.PurgeUrls(ctx, cutoff)
+	if err != nil {
+		return 0, users, fmt.Errorf("could not purge urls, got error: %w", err)
+	}
+
+	return urls, users, nil
+}
+
+// Schedule purges expired items every hour with 'runner', on one replica at a time
+func Schedule(runner *jobs.Runner, dbRepo storage.StorageRepository) error {
+	rs := NewRetentionService(dbRepo)
+	runner.Register(constant.JobRetentionPurge, jobs.Options{MaxAttempts: 1, Singleton: true},
+		func(ctx context.Context, job *model.Job) error {
+			urls, users, err := rs.Purge(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if urls > 0 || users > 0 {
+				logging.FromContext(ctx).Infof("retention purge removed %d urls and %d users", urls, users)
+			}
+			return nil
+		})
+	return runner.Schedule(constant.JobRetentionPurge, "@hourly")
+}
@@ -0,0 +1,62 @@
+// build+ unit
+package retention_test
+
+import (
+	"brief/internal/constant"
+	"brief/pkg/jobs"
+	"brief/pkg/repository/storage"
+	"brief/service/mock"
+	"brief/service/retention"
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var mockStorage storage.StorageRepository = &mock.Repo{}
+var retentionService retention.RetentionService = retention.NewRetentionService(mockStorage)
+
+func TestExpired(t *testing.T) {
+	tests := []struct {
+		Name      string
+		DeletedAt gorm.DeletedAt
+		Expected  bool
+	}{
+		{"Not_Deleted", gorm.DeletedAt{}, false},
+		{"Recently_Deleted", gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true}, false},
+		{"Past_Retention", gorm.DeletedAt{Time: time.Now().Add(-retention.Period() - time.Hour), Valid: true}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if got := retention.Expired(test.DeletedAt); got != test.Expected {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, got)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	if _, _, err := retentionService.Purge(context.Background(), time.Now()); err != nil {
+		t.Errorf("Expected 'error' to be nil, got '%v'", err)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	queue := jobs.NewMemoryQueue()
+	runner := jobs.NewRunner(queue, 1, time.Second)
+	if err := retention.Schedule(runner, mockStorage); err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	now := time.Date(2024, time.January, 31, 10, 17, 0, 0, time.UTC)
+	runner.Tick(context.Background(), now)
+	runner.Tick(context.Background(), now.Add(time.Hour))
+	runner.Drain(context.Background())
+
+	stored := queue.Jobs()
+	if len(stored) != 1 || stored[0].Name != constant.JobRetentionPurge || stored[0].Status != constant.JobSucceeded {
+		t.Errorf("Expected one '%s' job at 11:00, got '%+v'", constant.JobRetentionPurge, stored)
+	}
+}
@@ -1,10 +1,20 @@
 package url
 
 import (
+	"brief/internal/apperror"
 	"brief/internal/config"
 	"brief/internal/constant"
 	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/pkg/geoip"
+	"brief/pkg/metrics"
 	"brief/pkg/repository/storage"
+	"brief/pkg/tracing"
+	"brief/service/audit"
+	"brief/service/linkcheck"
+	"brief/service/retention"
+	"brief/service/user"
+	"brief/service/workspace"
 	"brief/utility"
 	"context"
 	"errors"
@@ -13,61 +23,301 @@ import (
 	"net/http"
 	urlPkg "net/url"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"gorm.io/gorm"
 )
 
 type UrlService interface {
-	Redirect(hash string) (*model.URL, error)
-	Shorten(url *model.URL, ctxInfo *model.ContextInfo, r *http.Request) error
-	Delete(ctxInfo *model.ContextInfo, urlId string) (*model.URL, error)
-	GetURLs(userID string) ([]model.URL, error)
-	GetAll() ([]model.URL, error)
+	Redirect(ctx context.Context, hash, rest string, r *http.Request) (*model.Redirection, error)
+	Shorten(ctx context.Context, url *model.URL, ctxInfo *model.ContextInfo, r *http.Request) error
+	Delete(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) (*model.URL, error)
+	Trash(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.URL, error)
+	Restore(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) (*model.URL, error)
+	GetURLs(ctx context.Context, ctxInfo *model.ContextInfo, userID, workspaceID string) ([]model.URL, error)
+	MoveToWorkspace(ctx context.Context, ctxInfo *model.ContextInfo, urlId, workspaceID string) (*model.URL, error)
+	GetAll(ctx context.Context) ([]model.URL, error)
+	GetRules(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) ([]model.RedirectRule, error)
+	SetRules(ctx context.Context, ctxInfo *model.ContextInfo, urlId string, rules []model.RedirectRule) ([]model.RedirectRule, error)
+	GetVariants(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) ([]model.VariantStats, error)
+	GetStats(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) (*model.LinkStats, error)
+	GetBrokenLinks(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.BrokenLink, error)
+	GetLinkChecks(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) ([]model.LinkCheck, error)
+	SetVariants(ctx context.Context, ctxInfo *model.ContextInfo, urlId string, variants []model.Variant) ([]model.Variant, error)
 }
 
 type urlService struct {
-	dbRepo storage.StorageRepository
+	dbRepo  storage.StorageRepository
+	locator geoip.Locator
+	bus     events.Bus
 }
 
-func NewUrlService(dbRepo storage.StorageRepository) UrlService {
-	return &urlService{dbRepo: dbRepo}
+func NewUrlService(dbRepo storage.StorageRepository, locator geoip.Locator, bus events.Bus) UrlService {
+	return &urlService{dbRepo: dbRepo, locator: locator, bus: bus}
 }
 
-// Redirect contains business logic to redirect a shortened url to the original url
-func (u *urlService) Redirect(hash string) (*model.URL, error) {
+// Redirect contains business logic to redirect a shortened url to the original url.
+// Matching redirect rules take precedence over split test variants, which take precedence
+// over the url's own destination
+func (u *urlService) Redirect(ctx context.Context, hash, rest string, r *http.Request) (*model.Redirection, error) {
+	ctx, span := tracing.Start(ctx, "url.Redirect", attribute.String("url.hash", hash))
+	defer span.End()
 
-	url, err := u.dbRepo.GetURL(context.TODO(), hash)
+	url, err := u.dbRepo.GetURL(ctx, u.domainID(ctx, r.Host), hash)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("url not found")
+			metrics.Redirects.WithLabelValues(metrics.Result(false)).Inc()
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
 		}
-		return nil, fmt.Errorf("could not fetch url, got error %w", err)
+		return nil, apperror.Internal(err, "could not fetch url")
 	}
 
-	return url, nil
+	if rest != "" && !url.ForwardPath {
+		metrics.Redirects.WithLabelValues(metrics.Result(false)).Inc()
+		return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+	}
+
+	visitor := u.newVisitor(r)
+	redirection := &model.Redirection{URL: url}
+	target := *url
+	if rule := MatchRule(url.Rules, visitor); rule != nil {
+		target.LongURL = rule.Destination
+	} else if variant := stickyVariant(url, r); variant != nil {
+		redirection.Variant = variant
+		target.LongURL = variant.Destination
+	}
+
+	// Only redirects that can be followed are hits and clicks
+	redirection.Destination, err = BuildDestination(&target, rest, r.URL.Query())
+	if errors.Is(err, ErrDotSegment) {
+		metrics.Redirects.WithLabelValues(metrics.Result(false)).Inc()
+		return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+	}
+	if err != nil {
+		return nil, apperror.Internal(err, "could not build destination")
+	}
+	metrics.Redirects.WithLabelValues(metrics.Result(true)).Inc()
+
+	click := &model.Click{
+		ID:        uuid.NewString(),
+		URLID:     url.ID,
+		Country:   visitor.Country,
+		CreatedAt: visitor.Time,
+	}
+	if redirection.Variant != nil {
+		click.VariantID = redirection.Variant.ID
+	}
+
+	// A failure to record analytics should not prevent the redirect
+	_ = u.dbRepo.CreateClick(ctx, click)
+	u.bus.Publish(ctx, events.Redirected{URL: *url, Click: *click})
+
+	return redirection, nil
+}
+
+// domainID returns the id of the verified custom domain serving 'host', or an
+// empty id for the shared domain
+func (u *urlService) domainID(ctx context.Context, host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	domain, err := u.dbRepo.GetDomainByHost(ctx, strings.ToLower(host))
+	if err != nil || !domain.Verified {
+		return ""
+	}
+	return domain.ID
+}
+
+// newVisitor collects the details of a request that redirect rules are matched against.
+// The client's address is expected to have been resolved by the RealIP middleware
+func (u *urlService) newVisitor(r *http.Request) *model.Visitor {
+	ua := utility.ParseUserAgent(r.UserAgent())
+	visitor := &model.Visitor{
+		OS:        ua.OS,
+		Device:    ua.Device,
+		Browser:   ua.Browser,
+		Languages: utility.ParseAcceptLanguage(r.Header.Get("Accept-Language")),
+		Time:      time.Now(),
+	}
+
+	if u.locator != nil {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if country, err := u.locator.Country(net.ParseIP(host)); err == nil {
+			visitor.Country = country
+		}
+	}
+
+	return visitor
+}
+
+// MatchRule returns the first rule in 'rules' whose conditions are all met by 'visitor',
+// or nil if the url's default destination should be used
+func MatchRule(rules []model.RedirectRule, visitor *model.Visitor) *model.RedirectRule {
+	for i := range rules {
+		rule := &rules[i]
+
+		if rule.OS != "" && rule.OS != visitor.OS {
+			continue
+		}
+		if rule.Device != "" && rule.Device != visitor.Device {
+			continue
+		}
+		if rule.Browser != "" && rule.Browser != visitor.Browser {
+			continue
+		}
+		if rule.Language != "" && !matchLanguage(rule.Language, visitor.Languages) {
+			continue
+		}
+		if rule.Country != "" && !strings.EqualFold(rule.Country, visitor.Country) {
+			continue
+		}
+		if rule.StartsAt != nil && visitor.Time.Before(*rule.StartsAt) {
+			continue
+		}
+		if rule.EndsAt != nil && !visitor.Time.Before(*rule.EndsAt) {
+			continue
+		}
+
+		return rule
+	}
+
+	return nil
+}
+
+// matchLanguage reports whether 'language' matches any of the visitor's languages.
+// A primary tag such as 'fr' matches regional variants like 'fr-ca'
+func matchLanguage(language string, languages []string) bool {
+	language = strings.ToLower(language)
+	for _, l := range languages {
+		if l == language || strings.HasPrefix(l, language+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrDotSegment is returned for a trailing path with '.' or '..' segments, which could lead out
+// of the path of the destination
+var ErrDotSegment = errors.New("trailing path must not contain '.' or '..' segments")
+
+// BuildDestination applies the pass-through settings of 'url' to its LongURL,
+// appending the trailing path 'rest' and merging the incoming 'query'
+func BuildDestination(url *model.URL, rest string, query urlPkg.Values) (string, error) {
+	if !url.ForwardPath && !url.ForwardQuery {
+		return url.LongURL, nil
+	}
+
+	dest, err := urlPkg.Parse(url.LongURL)
+	if err != nil {
+		return "", err
+	}
+
+	if url.ForwardPath && rest != "" {
+		if hasDotSegment(rest) {
+			return "", ErrDotSegment
+		}
+		dest.Path = strings.TrimSuffix(dest.Path, "/") + "/" + strings.TrimPrefix(rest, "/")
+		dest.RawPath = ""
+	}
+
+	if url.ForwardQuery && len(query) > 0 {
+		destQuery := dest.Query()
+		for key, values := range query {
+			switch url.QueryMode {
+			case constant.QueryOverride:
+				destQuery[key] = values
+			case constant.QueryAppend:
+				destQuery[key] = append(destQuery[key], values...)
+			default:
+				if _, ok := destQuery[key]; !ok {
+					destQuery[key] = values
+				}
+			}
+		}
+		dest.RawQuery = destQuery.Encode()
+	}
+
+	return dest.String(), nil
+}
+
+// hasDotSegment reports whether 'rest', which may be percent-encoded, has '.' or '..' segments
+func hasDotSegment(rest string) bool {
+	if decoded, err := urlPkg.PathUnescape(rest); err == nil {
+		rest = decoded
+	}
+	for _, segment := range strings.Split(rest, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
 }
 
 // ADMIN & USER
 
 // Link contains business logic to shorten and store a URL
-func (u *urlService) Shorten(url *model.URL, ctxInfo *model.ContextInfo, r *http.Request) error {
+func (u *urlService) Shorten(ctx context.Context, url *model.URL, ctxInfo *model.ContextInfo, r *http.Request) error {
+	ctx, span := tracing.Start(ctx, "url.Shorten")
+	defer span.End()
+
+	// Merge utm fields from the request or its campaign into the url
+	if url.CampaignID != "" || url.UTM != nil {
+		utm, err := u.resolveUTM(ctx, url, ctxInfo)
+		if err != nil {
+			return err
+		}
+
+		longURL, err := ApplyUTM(url.LongURL, utm)
+		if err != nil {
+			return apperror.Validation(apperror.CodeInvalidURL, "invalid url specified: '%v'", url.LongURL)
+		}
+		url.LongURL = longURL
+	}
 
 	{
 		// Check that URL is valid
 		_, err := urlPkg.Parse(url.LongURL)
 		if err != nil {
-			return fmt.Errorf("invalid url specified: '%v'", url.LongURL)
+			return apperror.Validation(apperror.CodeInvalidURL, "invalid url specified: '%v'", url.LongURL)
+		}
+
+		if err := ping(ctx, url.LongURL); err != nil {
+			return apperror.Validation(apperror.CodeInvalidURL, "invalid url specified: '%v'", url.LongURL).Wrap(err)
+		}
+	}
+
+	// Links on a custom domain are served from that domain instead of the request's host
+	host := r.Host
+	if url.DomainID != "" {
+		domain, err := u.useDomain(ctx, url.DomainID, ctxInfo)
+		if err != nil {
+			return err
 		}
+		host = domain.Host
+	}
 
-		if err := ping(url.LongURL); err != nil {
-			return fmt.Errorf("invalid url specified: '%v', got error: '%v'", url.LongURL, err)
+	// Links created in a workspace require at least the editor role
+	if url.WorkspaceID != "" {
+		if err := workspace.Authorize(ctx, u.dbRepo, ctxInfo, url.WorkspaceID, constant.WorkspaceEditor); err != nil {
+			return err
 		}
 	}
 
 	// URL shortening logic
 	url.ID = uuid.NewString()
+	if err := prepareRules(url.ID, url.Rules); err != nil {
+		return err
+	}
+	prepareVariants(url.ID, url.Variants)
+
 	if ctxInfo != nil && ctxInfo.ID != "" {
 		url.UserID = ctxInfo.ID
 	} else {
@@ -75,111 +325,524 @@ func (u *urlService) Shorten(url *model.URL, ctxInfo *model.ContextInfo, r *http
 	}
 
 	if url.Hash == "" {
-		// Run indefinite loop to prevent possible collision
+		// Run indefinite loop to prevent possible collision, until the request is cancelled
 		for {
+			if err := ctx.Err(); err != nil {
+				return apperror.Internal(err, "could not store url")
+			}
+
 			hash, err := utility.GetURLHash(url.ID, url.LongURL)
 			if err != nil {
-				return fmt.Errorf("could not generate hash, got error %w", err)
+				return apperror.Internal(err, "could not generate hash")
 			}
 			url.Hash = hash
 
-			if err := u.dbRepo.CreateURL(context.TODO(), url); err != nil {
+			if err := u.create(ctx, url, r, host); err != nil {
 				if !errors.Is(err, gorm.ErrDuplicatedKey) {
-					return fmt.Errorf("could not store url, got error %w", err)
+					return apperror.Internal(err, "could not store url")
 				}
+				metrics.HashCollisions.Inc()
 			} else {
 				break
 			}
 		}
 	} else {
-		if err := u.dbRepo.CreateURL(context.TODO(), url); err != nil {
+		if err := u.create(ctx, url, r, host); err != nil {
 			if !errors.Is(err, gorm.ErrDuplicatedKey) {
-				return fmt.Errorf("could not store url, got error %w", err)
+				return apperror.Internal(err, "could not store url")
 			}
-			return fmt.Errorf("oops, '%s' already exists", url.Hash)
+			return apperror.Conflict(apperror.CodeHashTaken, "oops, '%s' already exists", url.Hash)
 		}
 	}
 
+	actor := ctxInfo
+	if actor == nil {
+		actor = audit.RequestInfo(r, "")
+	}
+	audit.Record(ctx, u.dbRepo, actor, constant.AuditURLCreate, constant.AuditTargetURL, url.ID, nil, url)
+
+	url.Hash = shortURL(url, r, host)
+	return nil
+}
+
+// create stores 'url' along with the event announcing it, whose hash is the full short url. Each
+// attempt has a transaction of its own, so that a hash collision can be retried
+func (u *urlService) create(ctx context.Context, url *model.URL, r *http.Request, host string) error {
+	return events.Transact(ctx, u.bus, u.dbRepo, func(tx storage.StorageRepository) ([]events.Event, error) {
+		if err := tx.CreateURL(ctx, url); err != nil {
+			return nil, err
+		}
+
+		created := *url
+		created.Hash = shortURL(url, r, host)
+		return []events.Event{events.URLCreated{URL: created}}, nil
+	})
+}
+
+// shortURL returns the short url of 'url' on 'host', https unless the request was made over http
+// to the default domain
+func shortURL(url *model.URL, r *http.Request, host string) string {
 	hashUrl := urlPkg.URL{
-		Host:   r.Host,
+		Host:   host,
 		Scheme: r.URL.Scheme,
 		Path:   url.Hash,
 	}
-	if hashUrl.Scheme == "" {
+	if hashUrl.Scheme == "" || url.DomainID != "" {
 		hashUrl.Scheme = "https"
 	}
-	url.Hash = hashUrl.String()
-	return nil
+	return hashUrl.String()
 }
 
 // Delete contains business logic to delete a user's saved URL or a random url by its 'id'
-func (u *urlService) Delete(ctxInfo *model.ContextInfo, urlId string) (*model.URL, error) {
+func (u *urlService) Delete(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) (*model.URL, error) {
 
-	if ctxInfo.Role != constant.Roles[constant.Admin] {
-		url, err := u.dbRepo.GetURLById(context.TODO(), urlId)
-		if err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return nil, fmt.Errorf("url not found")
-			}
-			return nil, fmt.Errorf("could not fetch url, got error %w", err)
+	if err := u.authorize(ctx, ctxInfo, urlId, constant.PermURLDeleteAny, constant.WorkspaceEditor); err != nil {
+		return nil, err
+	}
+
+	// The url is moved to the trash along with the event announcing it
+	var url *model.URL
+	err := events.Transact(ctx, u.bus, u.dbRepo, func(tx storage.StorageRepository) ([]events.Event, error) {
+		var err error
+		if url, err = tx.DeleteUrl(ctx, urlId); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.URLDeleted{URL: *url}}, nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+		}
+		return nil, apperror.Internal(err, "could not delete url")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditURLDelete, constant.AuditTargetURL, urlId, url, nil)
+
+	return url, nil
+}
+
+// Trash contains business logic to fetch the deleted urls of the requesting user that can
+// still be restored
+func (u *urlService) Trash(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.URL, error) {
+
+	urls, err := u.dbRepo.GetDeletedUrls(ctx, ctxInfo.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get deleted urls")
+	}
+
+	restorable := []model.URL{}
+	for _, url := range urls {
+		if !retention.Expired(url.DeletedAt) {
+			restorable = append(restorable, url)
 		}
+	}
 
-		if url.UserID != ctxInfo.ID {
-			return nil, fmt.Errorf("unauthorized to perform this action")
+	return restorable, nil
+}
+
+// Restore contains business logic to take a deleted url out of the trash, within the
+// retention period
+func (u *urlService) Restore(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) (*model.URL, error) {
+
+	deleted, err := u.dbRepo.GetDeletedURL(ctx, urlId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found in trash")
 		}
+		return nil, apperror.Internal(err, "could not fetch url")
+	}
+
+	if err := u.authorizeURL(ctx, ctxInfo, deleted, constant.PermURLDeleteAny, constant.WorkspaceEditor); err != nil {
+		return nil, err
+	}
+
+	if retention.Expired(deleted.DeletedAt) {
+		return nil, apperror.Conflict(apperror.CodeRestoreExpired, "url was deleted more than %d days ago and can no longer be restored", int(retention.Period().Hours()/24))
 	}
 
-	url, err := u.dbRepo.DeleteUrl(context.TODO(), urlId)
+	url, err := u.dbRepo.RestoreUrl(ctx, urlId)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("url not found")
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found in trash")
 		}
-		return nil, fmt.Errorf("could not delete url, got error %w", err)
+		return nil, apperror.Internal(err, "could not restore url")
 	}
 
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditURLRestore, constant.AuditTargetURL, urlId, nil, url)
+
 	return url, nil
 }
 
-// GetURLs contains business logic to fetch all URL's created by a user with 'userID'
-func (u *urlService) GetURLs(userID string) ([]model.URL, error) {
+// GetURLs contains business logic to fetch all URL's created by a user with 'userID', or all
+// URL's of a workspace with 'workspaceID' when the requesting user is one of its members
+func (u *urlService) GetURLs(ctx context.Context, ctxInfo *model.ContextInfo, userID, workspaceID string) ([]model.URL, error) {
+
+	if workspaceID != "" {
+		if err := workspace.Authorize(ctx, u.dbRepo, ctxInfo, workspaceID, constant.WorkspaceViewer); err != nil {
+			return nil, err
+		}
 
-	urls, err := u.dbRepo.GetUrls(context.TODO(), userID)
+		urls, err := u.dbRepo.GetWorkspaceUrls(ctx, workspaceID)
+		if err != nil {
+			return nil, apperror.Internal(err, "could not get urls")
+		}
+		return urls, nil
+	}
+
+	urls, err := u.dbRepo.GetUrls(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("could not get urls, got error : %w", err)
+		return nil, apperror.Internal(err, "could not get urls")
 	}
 
 	return urls, nil
 }
 
+// MoveToWorkspace contains business logic to move a url into a workspace the requesting user can
+// edit, an empty 'workspaceID' gives the url back to its creator
+func (u *urlService) MoveToWorkspace(ctx context.Context, ctxInfo *model.ContextInfo, urlId, workspaceID string) (*model.URL, error) {
+
+	if err := u.authorize(ctx, ctxInfo, urlId, constant.PermURLUpdateAny, constant.WorkspaceEditor); err != nil {
+		return nil, err
+	}
+
+	if workspaceID != "" {
+		if err := workspace.Authorize(ctx, u.dbRepo, ctxInfo, workspaceID, constant.WorkspaceEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := u.dbRepo.GetURLById(ctx, urlId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch url")
+	}
+
+	url, err := u.dbRepo.SetURLWorkspace(ctx, urlId, workspaceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+		}
+		return nil, apperror.Internal(err, "could not move url")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditURLUpdate, constant.AuditTargetURL, urlId,
+		map[string]string{"workspace_id": before.WorkspaceID}, map[string]string{"workspace_id": url.WorkspaceID})
+
+	return url, nil
+}
+
+// GetRules contains business logic to fetch the redirect rules of a url by its 'id'
+func (u *urlService) GetRules(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) ([]model.RedirectRule, error) {
+
+	if err := u.authorize(ctx, ctxInfo, urlId, constant.PermURLReadAny, constant.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	rules, err := u.dbRepo.GetRules(ctx, urlId)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get rules")
+	}
+
+	return rules, nil
+}
+
+// SetRules contains business logic to replace the redirect rules of a url by its 'id'.
+// Rules are evaluated in the order they are specified
+func (u *urlService) SetRules(ctx context.Context, ctxInfo *model.ContextInfo, urlId string, rules []model.RedirectRule) ([]model.RedirectRule, error) {
+
+	if err := u.authorize(ctx, ctxInfo, urlId, constant.PermURLUpdateAny, constant.WorkspaceEditor); err != nil {
+		return nil, err
+	}
+
+	if err := prepareRules(urlId, rules); err != nil {
+		return nil, err
+	}
+
+	before, err := u.dbRepo.GetRules(ctx, urlId)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get rules")
+	}
+
+	if err := u.dbRepo.ReplaceRules(ctx, urlId, rules); err != nil {
+		return nil, apperror.Internal(err, "could not store rules")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditURLUpdate, constant.AuditTargetURL, urlId,
+		model.RuleSet{Rules: before}, model.RuleSet{Rules: rules})
+
+	return rules, nil
+}
+
+// GetStats contains business logic to summarise the clicks received by a url
+func (u *urlService) GetStats(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) (*model.LinkStats, error) {
+
+	url, err := u.dbRepo.GetURLById(ctx, urlId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch url")
+	}
+
+	if err := u.authorizeURL(ctx, ctxInfo, url, constant.PermStatsRead, constant.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	clicks, err := u.dbRepo.GetClicks(ctx, urlId)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get clicks")
+	}
+
+	return &user.LinkStats([]model.URL{*url}, clicks)[0], nil
+}
+
+// GetBrokenLinks contains business logic to fetch the urls of the requesting user, and of the
+// workspaces they are a member of, whose destination is broken, with the latest check of each
+func (u *urlService) GetBrokenLinks(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.BrokenLink, error) {
+
+	urls, err := u.dbRepo.GetBrokenUrls(ctx, ctxInfo.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get broken urls")
+	}
+
+	ids := make([]string, len(urls))
+	for i := range urls {
+		ids[i] = urls[i].ID
+	}
+
+	checks, err := u.dbRepo.GetLatestLinkChecks(ctx, ids)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get link checks")
+	}
+	latest := make(map[string]*model.LinkCheck, len(checks))
+	for i := range checks {
+		latest[checks[i].URLID] = &checks[i]
+	}
+
+	broken := make([]model.BrokenLink, len(urls))
+	for i := range urls {
+		broken[i].URL = urls[i]
+		broken[i].LastCheck = latest[urls[i].ID]
+	}
+
+	return broken, nil
+}
+
+// GetLinkChecks contains business logic to fetch the latest checks of the destination of a url
+func (u *urlService) GetLinkChecks(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) ([]model.LinkCheck, error) {
+
+	url, err := u.dbRepo.GetURLById(ctx, urlId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch url")
+	}
+
+	if err := u.authorizeURL(ctx, ctxInfo, url, constant.PermStatsRead, constant.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	checks, err := u.dbRepo.GetLinkChecks(ctx, urlId, linkcheck.History)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get link checks")
+	}
+
+	return checks, nil
+}
+
+// authorize ensures that a url with 'urlId' can be managed by the requesting user
+func (u *urlService) authorize(ctx context.Context, ctxInfo *model.ContextInfo, urlId, permission, minRole string) error {
+	if utility.HasPermission(ctxInfo.Role, permission) {
+		return nil
+	}
+
+	url, err := u.dbRepo.GetURLById(ctx, urlId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apperror.NotFound(apperror.CodeURLNotFound, "url not found")
+		}
+		return apperror.Internal(err, "could not fetch url")
+	}
+
+	return u.authorizeURL(ctx, ctxInfo, url, permission, minRole)
+}
+
+// authorizeURL ensures that 'url' can be managed by the requesting user. Links of a workspace
+// can be managed by its members of at least 'minRole', other links only by their creator. Roles
+// granted 'permission' can manage every link
+func (u *urlService) authorizeURL(ctx context.Context, ctxInfo *model.ContextInfo, url *model.URL, permission, minRole string) error {
+	if utility.HasPermission(ctxInfo.Role, permission) {
+		return nil
+	}
+
+	if url.WorkspaceID != "" {
+		return workspace.Authorize(ctx, u.dbRepo, ctxInfo, url.WorkspaceID, minRole)
+	}
+
+	if url.UserID != ctxInfo.ID {
+		return apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action")
+	}
+
+	return nil
+}
+
+// prepareRules assigns ids and evaluation order to the rules of a url with 'urlId'
+func prepareRules(urlId string, rules []model.RedirectRule) error {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.StartsAt != nil && rule.EndsAt != nil && !rule.EndsAt.After(*rule.StartsAt) {
+			return apperror.Validation(apperror.CodeInvalidRule, "rule %d: 'ends_at' must be after 'starts_at'", i+1)
+		}
+
+		rule.ID = uuid.NewString()
+		rule.URLID = urlId
+		rule.Position = i
+	}
+
+	return nil
+}
+
 // ADMIN
 
 // GetAll contains business logic to fetch all URL's
-func (u *urlService) GetAll() ([]model.URL, error) {
+func (u *urlService) GetAll(ctx context.Context) ([]model.URL, error) {
 
-	urls, err := u.dbRepo.GetAll(context.TODO())
+	urls, err := u.dbRepo.GetAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not get urls, got error : %w", err)
+		return nil, apperror.Internal(err, "could not get urls")
 	}
 
 	return urls, nil
 }
 
-func ping(url string) error {
+// useDomain ensures a custom domain is verified and can be used by the requesting user
+func (u *urlService) useDomain(ctx context.Context, domainID string, ctxInfo *model.ContextInfo) (*model.Domain, error) {
+	if ctxInfo == nil || ctxInfo.ID == "" {
+		return nil, apperror.Unauthorized(apperror.CodeSignInRequired, "custom domains can only be used by signed in users")
+	}
+
+	domain, err := u.dbRepo.GetDomain(ctx, domainID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound(apperror.CodeDomainNotFound, "domain not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch domain")
+	}
+
+	if !utility.HasPermission(ctxInfo.Role, constant.PermDomainManageAny) && domain.UserID != ctxInfo.ID {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "unauthorized to use this domain")
+	}
+
+	if !domain.Verified {
+		return nil, apperror.Conflict(apperror.CodeDomainNotVerified, "domain '%s' has not been verified", domain.Host)
+	}
+
+	return domain, nil
+}
+
+// resolveUTM combines the utm fields of a url's campaign with the ones specified
+// on the url itself, the latter taking precedence
+func (u *urlService) resolveUTM(ctx context.Context, url *model.URL, ctxInfo *model.ContextInfo) (*model.UTM, error) {
+	utm := model.UTM{}
+
+	if url.CampaignID != "" {
+		if ctxInfo == nil || ctxInfo.ID == "" {
+			return nil, apperror.Unauthorized(apperror.CodeSignInRequired, "campaigns can only be used by signed in users")
+		}
+
+		campaign, err := u.dbRepo.GetCampaign(ctx, url.CampaignID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperror.NotFound(apperror.CodeCampaignNotFound, "campaign not found")
+			}
+			return nil, apperror.Internal(err, "could not fetch campaign")
+		}
+
+		if !utility.HasPermission(ctxInfo.Role, constant.PermCampaignManageAny) && campaign.UserID != ctxInfo.ID {
+			return nil, apperror.Forbidden(apperror.CodeForbidden, "unauthorized to use this campaign")
+		}
+		utm = campaign.UTM
+	}
+
+	if url.UTM != nil {
+		for _, field := range []struct {
+			dst *string
+			src string
+		}{
+			{&utm.Source, url.UTM.Source},
+			{&utm.Medium, url.UTM.Medium},
+			{&utm.Campaign, url.UTM.Campaign},
+			{&utm.Term, url.UTM.Term},
+			{&utm.Content, url.UTM.Content},
+		} {
+			if field.src != "" {
+				*field.dst = field.src
+			}
+		}
+	}
+
+	if utm.Source == "" || utm.Medium == "" || utm.Campaign == "" {
+		return nil, apperror.Validation(apperror.CodeInvalidUTM, "utm source, medium and campaign are required")
+	}
+
+	for _, value := range []string{utm.Source, utm.Medium, utm.Campaign, utm.Term, utm.Content} {
+		if len(value) > 100 {
+			return nil, apperror.Validation(apperror.CodeInvalidUTM, "utm fields cannot be longer than 100 characters")
+		}
+	}
+
+	return &utm, nil
+}
+
+// ApplyUTM sets the utm query parameters of 'longURL', replacing any existing ones
+func ApplyUTM(longURL string, utm *model.UTM) (string, error) {
+	dest, err := urlPkg.Parse(longURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := dest.Query()
+	for key, value := range map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	dest.RawQuery = query.Encode()
+
+	return dest.String(), nil
+}
+
+// ping checks that 'url' answers a HEAD request successfully
+func ping(ctx context.Context, url string) (err error) {
+	ctx, span := tracing.Start(ctx, "ping", semconv.HTTPMethod(http.MethodHead), semconv.HTTPURL(url))
+	defer func() { tracing.End(span, err) }()
+
 	client := http.Client{
 		Transport: &http.Transport{
 			DialContext: (&net.Dialer{Timeout: 2 * time.Second}).DialContext,
 		},
 	}
 
-	req, err := http.NewRequest("HEAD", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 	if err != nil {
 		return err
 	}
+	tracing.Inject(ctx, req.Header)
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 	resp.Body.Close()
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
 
 	regx, _ := regexp.Compile("^20")
 	ok := regx.Match([]byte(fmt.Sprint(resp.StatusCode)))
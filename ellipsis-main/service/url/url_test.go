@@ -2,29 +2,151 @@
 package url_test
 
 import (
+	"brief/internal/apperror"
 	"brief/internal/constant"
 	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/pkg/metrics"
 	"brief/pkg/repository/storage"
 	"brief/service/mock"
 	"brief/service/url"
+	"context"
+	"errors"
 	"net/http"
+	urlPkg "net/url"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 var mockStorage storage.StorageRepository = &mock.Repo{}
-var storageService url.UrlService = url.NewUrlService(mockStorage)
+var storageService url.UrlService = url.NewUrlService(mockStorage, nil, events.NewBus())
 
 func TestRedirect(t *testing.T) {
 	hashString := "hashString"
-	url, err := storageService.Redirect(hashString)
+	req, err := http.NewRequest("GET", "http://my-url.com/"+hashString, nil)
+	if err != nil {
+		t.Errorf("Expected 'error' to be nil when creating request, got '%v'", err)
+	}
+
+	redirection, err := storageService.Redirect(context.Background(), hashString, "", req)
 	if err != nil {
 		t.Errorf("Expected 'error' to be nil, got '%v'", err)
 	}
 
-	if url == nil {
-		t.Errorf("Expected 'url' to be not nil, got '%v'", url)
+	if redirection == nil || redirection.URL == nil {
+		t.Fatalf("Expected 'url' to be not nil, got '%v'", redirection)
+	}
+
+	// The mock repository treats every host as a verified custom domain whose id is the host
+	if expVal := "my-url.com"; redirection.URL.DomainID != expVal {
+		t.Errorf("Expected 'url.DomainID' to be '%v', got '%v'", expVal, redirection.URL.DomainID)
+	}
+
+	t.Run("Trailing Path Without Forwarding", func(t *testing.T) {
+		if _, err := storageService.Redirect(context.Background(), hashString, "docs/intro", req); err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+
+	t.Run("Invalid Destination", func(t *testing.T) {
+		hits := metrics.Redirects.WithLabelValues(metrics.Result(true))
+		before := testutil.ToFloat64(hits)
+		repo := &badDestination{}
+
+		req, _ := http.NewRequest("GET", "http://my-url.com/"+hashString+"?utm_source=mail", nil)
+		if _, err := url.NewUrlService(repo, nil, events.NewBus()).Redirect(context.Background(), hashString, "", req); err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+		if got := testutil.ToFloat64(hits) - before; got != 0 {
+			t.Errorf("Expected '0' hits counted, got '%v'", got)
+		}
+		if repo.clicks != 0 {
+			t.Errorf("Expected '0' clicks recorded, got '%d'", repo.clicks)
+		}
+	})
+}
+
+// badDestination has a url whose destination cannot be built, and counts the clicks recorded
+type badDestination struct {
+	mock.Repo
+	clicks int
+}
+
+func (b *badDestination) GetURL(ctx context.Context, domainID, hash string) (*model.URL, error) {
+	return &model.URL{ID: "url-id", Hash: hash, LongURL: "http://[::1", ForwardQuery: true}, nil
+}
+
+func (b *badDestination) CreateClick(ctx context.Context, click *model.Click) error {
+	b.clicks++
+	return nil
+}
+
+func TestBuildDestination(t *testing.T) {
+	tests := []struct {
+		Name     string
+		URL      model.URL
+		Rest     string
+		Query    string
+		Expected string
+	}{
+		{"No_Forwarding", model.URL{LongURL: "https://docs.com/base?a=1"}, "intro", "b=2", "https://docs.com/base?a=1"},
+		{"Forward_Path", model.URL{LongURL: "https://docs.com/base/", ForwardPath: true}, "guide/intro", "", "https://docs.com/base/guide/intro"},
+		{"Forward_Path_Keeps_Query", model.URL{LongURL: "https://docs.com/base?a=1", ForwardPath: true}, "intro", "b=2", "https://docs.com/base/intro?a=1"},
+		{"Merge_Query", model.URL{LongURL: "https://docs.com?a=1", ForwardQuery: true}, "", "a=2&b=3", "https://docs.com?a=1&b=3"},
+		{"Override_Query", model.URL{LongURL: "https://docs.com?a=1", ForwardQuery: true, QueryMode: constant.QueryOverride}, "", "a=2&b=3", "https://docs.com?a=2&b=3"},
+		{"Append_Query", model.URL{LongURL: "https://docs.com?a=1", ForwardQuery: true, QueryMode: constant.QueryAppend}, "", "a=2", "https://docs.com?a=1&a=2"},
+		{"Path_And_Query", model.URL{LongURL: "https://docs.com/v1", ForwardPath: true, ForwardQuery: true}, "api", "q=go", "https://docs.com/v1/api?q=go"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			query, _ := urlPkg.ParseQuery(test.Query)
+			dest, err := url.BuildDestination(&test.URL, test.Rest, query)
+			if err != nil {
+				t.Errorf("Expected 'error' to be nil, got '%v'", err)
+			}
+
+			if dest != test.Expected {
+				t.Errorf("Expected 'destination' to be '%v', got '%v'", test.Expected, dest)
+			}
+		})
+	}
+
+	for name, rest := range map[string]string{
+		"Parent":         "../../admin",
+		"Nested_Parent":  "docs/../../admin",
+		"Current":        "./intro",
+		"Encoded_Parent": "%2e%2e/admin",
+		"Encoded_Slash":  "..%2Fadmin",
+	} {
+		t.Run("Dot_Segments_"+name, func(t *testing.T) {
+			dest, err := url.BuildDestination(&model.URL{LongURL: "https://docs.com/base", ForwardPath: true}, rest, nil)
+			if !errors.Is(err, url.ErrDotSegment) {
+				t.Errorf("Expected '%v', got '%v' and '%s'", url.ErrDotSegment, err, dest)
+			}
+		})
+	}
+}
+
+func TestCancelledShorten(t *testing.T) {
+	req, err := http.NewRequest("POST", "http://my-url.com", nil)
+	if err != nil {
+		t.Errorf("Expected 'error' to be nil when creating request, got '%v'", err)
+	}
+
+	// A client that has gone away should not have its url pinged or stored
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	url := &model.URL{LongURL: "https://google.com", UserID: "test-id"}
+	if err := storageService.Shorten(ctx, url, &model.ContextInfo{ID: "test-id"}, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected '%v', got '%v'", context.Canceled, err)
+	}
+	if url.ID != "" {
+		t.Errorf("Expected 'url.ID' to be empty, got '%v'", url.ID)
 	}
 }
 
@@ -38,7 +160,7 @@ func TestShorten(t *testing.T) {
 	t.Run("Specified Hash", func(t *testing.T) {
 		hash := "specific"
 		url := &model.URL{LongURL: "https://google.com", UserID: "test-id", Hash: hash}
-		if err = storageService.Shorten(url, &model.ContextInfo{ID: "test-id"}, req); err != nil {
+		if err = storageService.Shorten(context.Background(), url, &model.ContextInfo{ID: "test-id"}, req); err != nil {
 			t.Errorf("Expected 'error' to be nil when creating request, got '%v'", err)
 		}
 
@@ -62,7 +184,7 @@ func TestShorten(t *testing.T) {
 
 	t.Run("Random Hash", func(t *testing.T) {
 		url := &model.URL{LongURL: "https://google.com", UserID: "test-id"}
-		if err = storageService.Shorten(url, &model.ContextInfo{ID: "test-id"}, req); err != nil {
+		if err = storageService.Shorten(context.Background(), url, &model.ContextInfo{ID: "test-id"}, req); err != nil {
 			t.Errorf("Expected 'error' to be nil when creating request, got '%v'", err)
 		}
 
@@ -84,7 +206,7 @@ func TestShorten(t *testing.T) {
 func TestDelete(t *testing.T) {
 	uniformID := "test-id"
 	t.Run("Authorized", func(t *testing.T) {
-		_, err := storageService.Delete(&model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.Admin]}, uniformID)
+		_, err := storageService.Delete(context.Background(), &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.Admin]}, uniformID)
 		if err != nil {
 			t.Errorf("Expected 'error' to be nil, got '%v'", err)
 		}
@@ -92,23 +214,339 @@ func TestDelete(t *testing.T) {
 	})
 
 	t.Run("Unauthorized", func(t *testing.T) {
-		_, err := storageService.Delete(&model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.User]}, "test-id-2")
-		if err == nil {
-			t.Errorf("Expected 'error' to be not nil")
+		_, err := storageService.Delete(context.Background(), &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.User]}, "test-id-2")
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
+		}
+	})
+
+	t.Run("Moderator", func(t *testing.T) {
+		_, err := storageService.Delete(context.Background(), &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.Moderator]}, "test-id-2")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Analyst", func(t *testing.T) {
+		_, err := storageService.Delete(context.Background(), &model.ContextInfo{ID: uniformID, Role: constant.Roles[constant.Analyst]}, "test-id-2")
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
 		}
 	})
 }
 
 func TestGetUrls(t *testing.T) {
-	_, err := storageService.GetURLs("test-id")
+	t.Run("Personal", func(t *testing.T) {
+		_, err := storageService.GetURLs(context.Background(), &model.ContextInfo{ID: "test-id"}, "test-id", "")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Workspace_Member", func(t *testing.T) {
+		_, err := storageService.GetURLs(context.Background(), &model.ContextInfo{ID: "viewer-1"}, "viewer-1", "workspace-id")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Workspace_Non_Member", func(t *testing.T) {
+		_, err := storageService.GetURLs(context.Background(), &model.ContextInfo{ID: "test-id"}, "test-id", "workspace-id")
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
+		}
+	})
+}
+
+func TestMoveToWorkspace(t *testing.T) {
+	t.Run("Editor", func(t *testing.T) {
+		u, err := storageService.MoveToWorkspace(context.Background(), &model.ContextInfo{ID: "editor-1"}, "editor-1", "workspace-id")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if u != nil && u.WorkspaceID != "workspace-id" {
+			t.Errorf("Expected 'url.WorkspaceID' to be 'workspace-id', got '%v'", u.WorkspaceID)
+		}
+	})
+
+	t.Run("Viewer", func(t *testing.T) {
+		_, err := storageService.MoveToWorkspace(context.Background(), &model.ContextInfo{ID: "viewer-1"}, "viewer-1", "workspace-id")
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
+		}
+	})
+}
+
+func TestGetStats(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		stats, err := storageService.GetStats(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]}, "test-id")
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if stats.Clicks != 3 {
+			t.Errorf("Expected '3' clicks, got '%d'", stats.Clicks)
+		}
+		if len(stats.Countries) != 2 {
+			t.Errorf("Expected '2' countries, got '%d'", len(stats.Countries))
+		}
+	})
+
+	t.Run("Analyst", func(t *testing.T) {
+		_, err := storageService.GetStats(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.Analyst]}, "test-id-2")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := storageService.GetStats(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]}, "test-id-2")
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
+		}
+	})
+}
+
+// brokenLinks has broken urls and counts the queries fetching their checks
+type brokenLinks struct {
+	mock.Repo
+	queries int
+}
+
+func (b *brokenLinks) GetBrokenUrls(ctx context.Context, userID string) ([]model.URL, error) {
+	return []model.URL{{ID: "url-1", UserID: userID}, {ID: "url-2", UserID: userID}, {ID: "url-3", UserID: userID}}, nil
+}
+
+func (b *brokenLinks) GetLinkChecks(ctx context.Context, urlID string, limit int) ([]model.LinkCheck, error) {
+	b.queries++
+	return b.Repo.GetLinkChecks(ctx, urlID, limit)
+}
+
+func (b *brokenLinks) GetLatestLinkChecks(ctx context.Context, urlIDs []string) ([]model.LinkCheck, error) {
+	b.queries++
+	// 'url-2' was never checked
+	return []model.LinkCheck{{URLID: "url-3", StatusCode: 500}, {URLID: "url-1", StatusCode: 404}}, nil
+}
+
+func TestGetBrokenLinks(t *testing.T) {
+	broken, err := storageService.GetBrokenLinks(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]})
 	if err != nil {
-		t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	if len(broken) != 1 || broken[0].URL.UserID != "test-id" {
+		t.Fatalf("Expected '1' broken url of 'test-id', got '%+v'", broken)
+	}
+	if broken[0].LastCheck == nil || broken[0].LastCheck.StatusCode != 404 {
+		t.Errorf("Expected the latest check, got '%+v'", broken[0].LastCheck)
+	}
+
+	t.Run("One_Query", func(t *testing.T) {
+		repo := &brokenLinks{}
+		broken, err := url.NewUrlService(repo, nil, events.NewBus()).GetBrokenLinks(context.Background(), &model.ContextInfo{ID: "test-id"})
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		if len(broken) != 3 {
+			t.Fatalf("Expected '3' broken urls, got '%+v'", broken)
+		}
+		if repo.queries != 1 {
+			t.Errorf("Expected '1' query for the checks, got '%d'", repo.queries)
+		}
+
+		expected := map[string]int{"url-1": 404, "url-2": 0, "url-3": 500}
+		for _, link := range broken {
+			status := 0
+			if link.LastCheck != nil {
+				status = link.LastCheck.StatusCode
+			}
+			if status != expected[link.URL.ID] {
+				t.Errorf("Expected '%s' to have status code '%d', got '%d'", link.URL.ID, expected[link.URL.ID], status)
+			}
+		}
+	})
+}
+
+func TestGetLinkChecks(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		checks, err := storageService.GetLinkChecks(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]}, "test-id")
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		if len(checks) != 1 || checks[0].URLID != "test-id" {
+			t.Errorf("Expected the checks of 'test-id', got '%+v'", checks)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := storageService.GetLinkChecks(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]}, "test-id-2")
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
+		}
+	})
+}
+
+func TestWorkspaceLink(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Member  string
+		Read    bool // reads the stats of the link
+		IsError bool
+	}{
+		{"Viewer_Reads", "viewer-1", true, false},
+		{"Viewer_Edits", "viewer-1", false, true},
+		{"Editor_Edits", "editor-1", false, false},
+		{"Non_Member_Reads", "test-id", true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctxInfo := &model.ContextInfo{ID: test.Member, Role: constant.Roles[constant.User]}
+			var err error
+			if test.Read {
+				_, err = storageService.GetStats(context.Background(), ctxInfo, "workspace-url")
+			} else {
+				_, err = storageService.SetRules(context.Background(), ctxInfo, "workspace-url", nil)
+			}
+			if (err != nil) != test.IsError {
+				t.Errorf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+		})
 	}
 }
 
 func TestGetAll(t *testing.T) {
-	_, err := storageService.GetAll()
+	_, err := storageService.GetAll(context.Background())
 	if err != nil {
 		t.Errorf("Expected 'error' to be nil, got '%v'", err)
 	}
 }
+
+func TestApplyUTM(t *testing.T) {
+	tests := []struct {
+		Name     string
+		LongURL  string
+		UTM      model.UTM
+		Expected string
+	}{
+		{"Required_Fields", "https://shop.com", model.UTM{Source: "news", Medium: "email", Campaign: "launch"}, "https://shop.com?utm_campaign=launch&utm_medium=email&utm_source=news"},
+		{"Keeps_Existing_Query", "https://shop.com/p?id=1", model.UTM{Source: "news", Medium: "email", Campaign: "launch", Term: "shoes"}, "https://shop.com/p?id=1&utm_campaign=launch&utm_medium=email&utm_source=news&utm_term=shoes"},
+		{"Replaces_Existing_UTM", "https://shop.com?utm_source=old", model.UTM{Source: "news", Medium: "email", Campaign: "launch"}, "https://shop.com?utm_campaign=launch&utm_medium=email&utm_source=news"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			longURL, err := url.ApplyUTM(test.LongURL, &test.UTM)
+			if err != nil {
+				t.Errorf("Expected 'error' to be nil, got '%v'", err)
+			}
+
+			if longURL != test.Expected {
+				t.Errorf("Expected 'long_url' to be '%v', got '%v'", test.Expected, longURL)
+			}
+		})
+	}
+}
+
+func TestMatchRule(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	rules := []model.RedirectRule{
+		{OS: "ios", Destination: "https://apps.apple.com/app"},
+		{OS: "android", Device: "mobile", Destination: "https://play.google.com/store/apps"},
+		{Language: "fr", Destination: "https://site.com/fr"},
+		{Browser: "firefox", StartsAt: &past, EndsAt: &future, Destination: "https://site.com/promo"},
+		{Browser: "edge", StartsAt: &future, Destination: "https://site.com/later"},
+		{Country: "DE", Destination: "https://shop.de"},
+	}
+
+	tests := []struct {
+		Name     string
+		Visitor  model.Visitor
+		Expected string
+	}{
+		{"iOS", model.Visitor{OS: "ios", Device: "mobile", Languages: []string{"fr"}}, "https://apps.apple.com/app"},
+		{"Android_Mobile", model.Visitor{OS: "android", Device: "mobile"}, "https://play.google.com/store/apps"},
+		{"Android_Tablet_Falls_Through", model.Visitor{OS: "android", Device: "tablet"}, ""},
+		{"Regional_Language", model.Visitor{OS: "windows", Languages: []string{"en-us", "fr-ca"}}, "https://site.com/fr"},
+		{"Inside_Time_Window", model.Visitor{Browser: "firefox"}, "https://site.com/promo"},
+		{"Before_Time_Window", model.Visitor{Browser: "edge"}, ""},
+		{"Country", model.Visitor{OS: "linux", Country: "DE"}, "https://shop.de"},
+		{"Other_Country", model.Visitor{OS: "linux", Country: "FR"}, ""},
+		{"Default", model.Visitor{OS: "linux", Device: "desktop", Browser: "chrome"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Visitor.Time = now
+			rule := url.MatchRule(rules, &test.Visitor)
+
+			var dest string
+			if rule != nil {
+				dest = rule.Destination
+			}
+
+			if dest != test.Expected {
+				t.Errorf("Expected 'destination' to be '%v', got '%v'", test.Expected, dest)
+			}
+		})
+	}
+}
+
+func TestChooseVariant(t *testing.T) {
+	variants := []model.Variant{
+		{Name: "a", Weight: 70},
+		{Name: "b", Weight: 20},
+		{Name: "c", Weight: 10},
+	}
+
+	tests := []struct {
+		Name     string
+		Roll     int
+		Expected string
+	}{
+		{"First", 0, "a"},
+		{"First_Upper_Bound", 69, "a"},
+		{"Second", 70, "b"},
+		{"Third", 95, "c"},
+		{"Out_Of_Range", 100, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var name string
+			if variant := url.ChooseVariant(variants, test.Roll); variant != nil {
+				name = variant.Name
+			}
+
+			if name != test.Expected {
+				t.Errorf("Expected 'variant' to be '%v', got '%v'", test.Expected, name)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	// the mock trash holds urls of users with the same id, deleted long ago for 'expired' ids
+	t.Run("Owner", func(t *testing.T) {
+		_, err := storageService.Restore(context.Background(), &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]}, "test-id")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, err := storageService.Restore(context.Background(), &model.ContextInfo{ID: "test-id-2", Role: constant.Roles[constant.User]}, "test-id")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+
+	t.Run("Past_Retention", func(t *testing.T) {
+		_, err := storageService.Restore(context.Background(), &model.ContextInfo{ID: "expired-id", Role: constant.Roles[constant.User]}, "expired-id")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package url
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/service/audit"
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VariantCookie returns the name of the cookie that pins a visitor to a variant of the url with 'hash'
+func VariantCookie(hash string) string {
+	return "brief_variant_" + hash
+}
+
+// GetVariants contains business logic to fetch the split test variants of a url along with
+// the number of clicks each of them received
+func (u *urlService) GetVariants(ctx context.Context, ctxInfo *model.ContextInfo, urlId string) ([]model.VariantStats, error) {
+
+	if err := u.authorize(ctx, ctxInfo, urlId, constant.PermStatsRead, constant.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	stats, err := u.dbRepo.GetVariantStats(ctx, urlId)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get variants")
+	}
+
+	return stats, nil
+}
+
+// SetVariants contains business logic to replace the split test variants of a url.
+// Variants sent with their existing 'id' keep their click history
+func (u *urlService) SetVariants(ctx context.Context, ctxInfo *model.ContextInfo, urlId string, variants []model.Variant) ([]model.Variant, error) {
+
+	if err := u.authorize(ctx, ctxInfo, urlId, constant.PermURLUpdateAny, constant.WorkspaceEditor); err != nil {
+		return nil, err
+	}
+
+	stats, err := u.dbRepo.GetVariantStats(ctx, urlId)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get variants")
+	}
+	before := model.VariantSet{}
+	for _, s := range stats {
+		before.Variants = append(before.Variants, s.Variant)
+	}
+
+	prepareVariants(urlId, variants)
+	if err := u.dbRepo.ReplaceVariants(ctx, urlId, variants); err != nil {
+		return nil, apperror.Internal(err, "could not store variants")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditURLUpdate, constant.AuditTargetURL, urlId,
+		before, model.VariantSet{Variants: variants})
+
+	return variants, nil
+}
+
+// ChooseVariant picks the variant that 'roll' falls on when the weights of 'variants'
+// are laid end to end. 'roll' must be in the range [0, sum of weights)
+func ChooseVariant(variants []model.Variant, roll int) *model.Variant {
+	for i := range variants {
+		if roll < variants[i].Weight {
+			return &variants[i]
+		}
+		roll -= variants[i].Weight
+	}
+	return nil
+}
+
+// stickyVariant returns the variant a visitor was previously served, or picks a new one
+// according to the variants' weights
+func stickyVariant(url *model.URL, r *http.Request) *model.Variant {
+	if len(url.Variants) == 0 {
+		return nil
+	}
+
+	if cookie, err := r.Cookie(VariantCookie(url.Hash)); err == nil {
+		for i := range url.Variants {
+			if url.Variants[i].ID == cookie.Value {
+				return &url.Variants[i]
+			}
+		}
+	}
+
+	total := 0
+	for _, variant := range url.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	return ChooseVariant(url.Variants, rand.Intn(total))
+}
+
+// prepareVariants assigns ids and order to the variants of a url with 'urlId'
+func prepareVariants(urlId string, variants []model.Variant) {
+	for i := range variants {
+		variant := &variants[i]
+		if variant.ID == "" {
+			variant.ID = uuid.NewString()
+		}
+		variant.URLID = urlId
+		variant.Position = i
+		variant.CreatedAt = time.Now()
+	}
+}
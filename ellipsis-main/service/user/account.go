@@ -0,0 +1,151 @@
+package user
+
+import (
+	"archive/zip"
+	"brief/internal/apperror"
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/service/audit"
+	"brief/utility"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeleteAccount contains business logic for users to delete their own account after confirming
+// their password. The account is permanently deleted, with its links or after giving them to the
+// admin user, or anonymized with its links kept live
+func (u *userService) DeleteAccount(ctx context.Context, ctxInfo *model.ContextInfo, req *model.DeleteAccount) error {
+	user, err := u.dbRepo.GetUser(ctx, ctxInfo.ID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return apperror.Internal(err, "could not fetch user")
+		}
+		return apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	if !utility.PasswordIsValid(req.Password, user.Salt, user.Password) {
+		return apperror.Forbidden(apperror.CodeInvalidCredentials, "invalid password")
+	}
+
+	// Prevents the last admin from locking everyone out
+	if utility.HasPermission(user.Role, constant.PermRoleAssign) {
+		return apperror.Forbidden(apperror.CodeForbidden, "admins cannot delete their account, assign yourself another role first")
+	}
+
+	// The account is purged rather than moved to the trash, nothing is left for an admin to restore
+	switch req.Links {
+	case constant.LinksDelete:
+		err = u.dbRepo.PurgeUser(ctx, user.ID)
+
+	case constant.LinksTransfer:
+		err = u.dbRepo.Transaction(ctx, func(tx storage.StorageRepository) error {
+			if _, err := tx.TransferUrls(ctx, user.ID, config.GetConfig().AdminID); err != nil {
+				return err
+			}
+			return tx.PurgeUser(ctx, user.ID)
+		})
+
+	case constant.LinksKeep:
+		_, err = u.dbRepo.AnonymizeUser(ctx, user.ID, fmt.Sprintf("deleted-%s@users.invalid", user.ID))
+
+	default:
+		return apperror.Validation(apperror.CodeInvalidOption, "unknown option '%s' for links", req.Links)
+	}
+	if err != nil {
+		return apperror.Internal(err, "could not delete account")
+	}
+
+	// Audit logs are never deleted, so they keep no personal data of the account
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditUserDelete, constant.AuditTargetUser, user.ID,
+		map[string]string{"id": user.ID}, map[string]string{"links": req.Links})
+
+	return nil
+}
+
+// Export contains business logic to put the profile, links and click statistics of the
+// requesting user in a ZIP archive
+func (u *userService) Export(ctx context.Context, ctxInfo *model.ContextInfo) ([]byte, error) {
+	user, err := u.dbRepo.GetUser(ctx, ctxInfo.ID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not fetch user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	urls, err := u.dbRepo.GetUrls(ctx, user.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get urls")
+	}
+
+	clicks, err := u.dbRepo.GetUserClicks(ctx, user.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get clicks")
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, file := range []struct {
+		Name string
+		Data interface{}
+	}{
+		{"profile.json", sanitize(user)},
+		{"links.json", urls},
+		{"clicks.json", clicks},
+		{"click_stats.json", LinkStats(urls, clicks)},
+	} {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return nil, apperror.Internal(err, "could not create export")
+		}
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.Data); err != nil {
+			return nil, apperror.Internal(err, "could not create export")
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, apperror.Internal(err, "could not create export")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LinkStats summarises 'clicks' for each of 'urls', in the order of 'urls'
+func LinkStats(urls []model.URL, clicks []model.Click) []model.LinkStats {
+	stats := make([]model.LinkStats, len(urls))
+	index := make(map[string]*model.LinkStats, len(urls))
+	for i, url := range urls {
+		stats[i] = model.LinkStats{URLID: url.ID, Hash: url.Hash, Countries: map[string]int64{}}
+		index[url.ID] = &stats[i]
+	}
+
+	for i := range clicks {
+		s, ok := index[clicks[i].URLID]
+		if !ok {
+			continue
+		}
+
+		s.Clicks++
+		if clicks[i].Country != "" {
+			s.Countries[clicks[i].Country]++
+		}
+		if s.FirstClick == nil || clicks[i].CreatedAt.Before(*s.FirstClick) {
+			s.FirstClick = &clicks[i].CreatedAt
+		}
+		if s.LastClick == nil || clicks[i].CreatedAt.After(*s.LastClick) {
+			s.LastClick = &clicks[i].CreatedAt
+		}
+	}
+
+	return stats
+}
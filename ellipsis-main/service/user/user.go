@@ -1,14 +1,19 @@
 package user
 
 import (
+	"brief/internal/apperror"
 	"brief/internal/constant"
 	"brief/internal/model"
+	"brief/pkg/events"
 	"brief/pkg/middleware"
 	"brief/pkg/repository/storage"
-	"brief/pkg/repository/storage/postgres"
+	"brief/pkg/tracing"
+	"brief/service/audit"
+	"brief/service/retention"
 	"brief/utility"
 	"context"
-	"fmt"
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,34 +22,42 @@ import (
 )
 
 type UserService interface {
-	Register(user *model.User, isAdmin ...bool) (string, error)
-	Login(userLogin *model.UserLogin) (*model.LoginResponse, error)
-	Get(idOrEmail string) (*model.User, error)
-	GetAll() ([]model.User, error)
-	Update(id string, user *model.User) error
-	ResetPassword(id string, rp *model.ResetPassword) (*model.User, error)
-	ForgotPassword(email *model.ForgotPassword) error
-	LockUser(idOrEmail string) (*model.User, error)
-	UnlockUser(idOrEmail string) (*model.User, error)
+	Register(ctx context.Context, user *model.User, isAdmin ...bool) (string, error)
+	Login(ctx context.Context, userLogin *model.UserLogin, r *http.Request) (*model.LoginResponse, error)
+	Get(ctx context.Context, idOrEmail string) (*model.User, error)
+	GetAll(ctx context.Context) ([]model.User, error)
+	Update(ctx context.Context, id string, user *model.User) error
+	ResetPassword(ctx context.Context, ctxInfo *model.ContextInfo, rp *model.ResetPassword) (*model.User, error)
+	ForgotPassword(ctx context.Context, email *model.ForgotPassword) error
+	LockUser(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error)
+	UnlockUser(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error)
+	AssignRole(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail, role string) (*model.User, error)
+	GetRoleChanges(ctx context.Context, idOrEmail string) ([]model.RoleChange, error)
+	Delete(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error)
+	Trash(ctx context.Context) ([]model.User, error)
+	Restore(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error)
+	DeleteAccount(ctx context.Context, ctxInfo *model.ContextInfo, req *model.DeleteAccount) error
+	Export(ctx context.Context, ctxInfo *model.ContextInfo) ([]byte, error)
 
 	// Specific function to create admin user on server start-up
-	CreateAdminUser(logger *log.Logger) error
+	CreateAdminUser(ctx context.Context, logger *log.Logger) error
 }
 
 type userService struct {
 	dbRepo storage.StorageRepository
+	bus    events.Bus
 }
 
-func NewUserService(dbRepo storage.StorageRepository) UserService {
-	return &userService{dbRepo: dbRepo}
+func NewUserService(dbRepo storage.StorageRepository, bus events.Bus) UserService {
+	return &userService{dbRepo: dbRepo, bus: bus}
 }
 
 // Register contains business logic for registering a new user
-func (u *userService) Register(user *model.User, isAdmin ...bool) (string, error) {
+func (u *userService) Register(ctx context.Context, user *model.User, isAdmin ...bool) (string, error) {
 	// Hash password
 	hash, salt, err := utility.HashPassword(user.Password)
 	if err != nil {
-		return "", fmt.Errorf("could not hash user password, got error: %w", err)
+		return "", apperror.Internal(err, "could not hash user password")
 	}
 
 	// Set/Modify some fields from the request struct
@@ -63,13 +76,21 @@ func (u *userService) Register(user *model.User, isAdmin ...bool) (string, error
 
 	token, err := middleware.CreateToken(user.ID, user.Email, user.Role)
 	if err != nil {
-		return "", fmt.Errorf("could not create token, got error: %w", err)
+		return "", apperror.Internal(err, "could not create token")
 	}
 
-	db := postgres.GetDB()
-	err = db.CreateUser(context.TODO(), user)
+	// The user is stored along with the event announcing them
+	err = events.Transact(ctx, u.bus, u.dbRepo, func(tx storage.StorageRepository) ([]events.Event, error) {
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.UserRegistered{User: *sanitize(user)}}, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("could not create user, got error: %w", err)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return "", apperror.Conflict(apperror.CodeEmailTaken, "'%s' is already registered", user.Email)
+		}
+		return "", apperror.Internal(err, "could not create user")
 	}
 
 	// Omit password and salt from response
@@ -79,30 +100,38 @@ func (u *userService) Register(user *model.User, isAdmin ...bool) (string, error
 	return token, nil
 }
 
-// Login contains business logic for logging in
-func (u *userService) Login(userLogin *model.UserLogin) (*model.LoginResponse, error) {
-	user, err := u.dbRepo.GetUser(context.TODO(), userLogin.Email)
+// Login contains business logic for logging in. Attempts are audited
+func (u *userService) Login(ctx context.Context, userLogin *model.UserLogin, r *http.Request) (*model.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "user.Login")
+	defer span.End()
+
+	user, err := u.dbRepo.GetUser(ctx, userLogin.Email)
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("could not get user, got error %w", err)
+			return nil, apperror.Internal(err, "could not get user")
 		}
-		return nil, fmt.Errorf("invalid user")
+		audit.Record(ctx, u.dbRepo, audit.RequestInfo(r, ""), constant.AuditLoginFailed, constant.AuditTargetUser, userLogin.Email, nil, nil)
+		return nil, apperror.Unauthorized(apperror.CodeInvalidCredentials, "invalid user")
 	}
 
 	if !utility.PasswordIsValid(userLogin.Password, user.Salt, user.Password) {
-		return nil, fmt.Errorf("invalid password")
+		audit.Record(ctx, u.dbRepo, audit.RequestInfo(r, ""), constant.AuditLoginFailed, constant.AuditTargetUser, user.ID, nil, nil)
+		return nil, apperror.Unauthorized(apperror.CodeInvalidCredentials, "invalid password")
 	}
 
 	// Ensure that user is not locked
 	if user.IsLocked {
-		return nil, fmt.Errorf("cannot login, user is currently locked")
+		audit.Record(ctx, u.dbRepo, audit.RequestInfo(r, ""), constant.AuditLoginFailed, constant.AuditTargetUser, user.ID, nil, nil)
+		return nil, apperror.Forbidden(apperror.CodeAccountLocked, "cannot login, user is currently locked")
 	}
 
 	token, err := middleware.CreateToken(user.ID, user.Email, user.Role)
 	if err != nil {
-		return nil, fmt.Errorf("could not create token")
+		return nil, apperror.Internal(err, "could not create token")
 	}
 
+	audit.Record(ctx, u.dbRepo, audit.RequestInfo(r, user.ID), constant.AuditLogin, constant.AuditTargetUser, user.ID, nil, nil)
+
 	// Omit password and salt from response
 	user.Password = ""
 	user.Salt = ""
@@ -114,13 +143,13 @@ func (u *userService) Login(userLogin *model.UserLogin) (*model.LoginResponse, e
 }
 
 // Get contains business logic to get a user by id or email
-func (u *userService) Get(idOrEmail string) (*model.User, error) {
-	user, err := u.dbRepo.GetUser(context.TODO(), idOrEmail)
+func (u *userService) Get(ctx context.Context, idOrEmail string) (*model.User, error) {
+	user, err := u.dbRepo.GetUser(ctx, idOrEmail)
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("could not get user, got error %w", err)
+			return nil, apperror.Internal(err, "could not get user")
 		}
-		return nil, fmt.Errorf("invalid user")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "invalid user")
 	}
 
 	// Omit password and salt from response
@@ -131,32 +160,32 @@ func (u *userService) Get(idOrEmail string) (*model.User, error) {
 }
 
 // GetAll contains business logic for fetching all users
-func (u *userService) GetAll() ([]model.User, error) {
-	users, err := u.dbRepo.GetAllUsers(context.TODO())
+func (u *userService) GetAll(ctx context.Context) ([]model.User, error) {
+	users, err := u.dbRepo.GetAllUsers(ctx)
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("could not get user, got error: %w", err)
+			return nil, apperror.Internal(err, "could not get user")
 		}
-		return nil, fmt.Errorf("invalid user")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "invalid user")
 	}
 
 	return users, nil
 }
 
 // Update contains business logic to update a user
-func (u *userService) Update(id string, user *model.User) error {
-	fUser, err := u.dbRepo.GetUser(context.TODO(), id)
+func (u *userService) Update(ctx context.Context, id string, user *model.User) error {
+	fUser, err := u.dbRepo.GetUser(ctx, id)
 	if err != nil {
-		return fmt.Errorf("user not found")
+		return apperror.NotFound(apperror.CodeUserNotFound, "user not found")
 	}
 
 	// Ensure that user is not locked
 	if fUser.IsLocked {
-		return fmt.Errorf("cannot update, user is currently locked")
+		return apperror.Forbidden(apperror.CodeAccountLocked, "cannot update, user is currently locked")
 	}
 
-	if err := u.dbRepo.UpdateUser(context.TODO(), id, user); err != nil {
-		return fmt.Errorf("could not update user, got error: %w", err)
+	if err := u.dbRepo.UpdateUser(ctx, id, user); err != nil {
+		return apperror.Internal(err, "could not update user")
 	}
 
 	// Omit password and salt from response
@@ -166,32 +195,35 @@ func (u *userService) Update(id string, user *model.User) error {
 	return nil
 }
 
-// ResetPassword contains business logic to reset a user's password
-func (u *userService) ResetPassword(id string, rp *model.ResetPassword) (*model.User, error) {
-	fUser, err := u.dbRepo.GetUser(context.TODO(), id)
+// ResetPassword contains business logic to reset the requesting user's password
+func (u *userService) ResetPassword(ctx context.Context, ctxInfo *model.ContextInfo, rp *model.ResetPassword) (*model.User, error) {
+	id := ctxInfo.ID
+	fUser, err := u.dbRepo.GetUser(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found")
 	}
 
 	// Ensure that user is not locked
 	if fUser.IsLocked {
-		return nil, fmt.Errorf("cannot update, user is currently locked")
+		return nil, apperror.Forbidden(apperror.CodeAccountLocked, "cannot update, user is currently locked")
 	}
 
 	hashedPassword, salt, err := utility.HashPassword(rp.Password)
 	if err != nil {
-		return nil, fmt.Errorf("could not hash password, got error: %w", err)
+		return nil, apperror.Internal(err, "could not hash password")
 	}
 
 	// Set hashed password and salt
 	rp.Password = hashedPassword
 	rp.Salt = salt
 
-	user, err := u.dbRepo.ResetPassword(context.TODO(), id, rp)
+	user, err := u.dbRepo.ResetPassword(ctx, id, rp)
 	if err != nil {
-		return nil, fmt.Errorf("could not reset password, got error: %w", err)
+		return nil, apperror.Internal(err, "could not reset password")
 	}
 
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditPasswordReset, constant.AuditTargetUser, id, nil, nil)
+
 	// Omit password and salt from response
 	user.Password = ""
 	user.Salt = ""
@@ -200,13 +232,13 @@ func (u *userService) ResetPassword(id string, rp *model.ResetPassword) (*model.
 }
 
 // ForgotPassword contains business logic to handle a forgot-password request
-func (u *userService) ForgotPassword(email *model.ForgotPassword) error {
-	user, err := u.dbRepo.GetUser(context.TODO(), email.Email)
+func (u *userService) ForgotPassword(ctx context.Context, email *model.ForgotPassword) error {
+	user, err := u.dbRepo.GetUser(ctx, email.Email)
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
-			return fmt.Errorf("could not fetch user, got error: %w", err)
+			return apperror.Internal(err, "could not fetch user")
 		}
-		return fmt.Errorf("user does not exist")
+		return apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
 	}
 
 	_ = user // Prevents the Go compiler from calling an error
@@ -215,7 +247,7 @@ func (u *userService) ForgotPassword(email *model.ForgotPassword) error {
 
 	//token, err := middleware.CreateToken(user.ID, user.Email, user.Role)
 	//if err != nil {
-	//	return fmt.Errorf("could not create token, got error: %w", err)
+	//	return apperror.Internal(err, "could not create token")
 	//}
 
 	// TODO: Send Forgot-Password Email
@@ -224,28 +256,201 @@ func (u *userService) ForgotPassword(email *model.ForgotPassword) error {
 }
 
 // LockUser contains business logic to lock a user's account
-func (u *userService) LockUser(idOrEmail string) (*model.User, error) {
-	user, err := u.dbRepo.LockUnlock(context.TODO(), idOrEmail, true)
+func (u *userService) LockUser(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error) {
+	return u.lockUnlock(ctx, ctxInfo, idOrEmail, true)
+}
+
+// UnlockUser contains business logic to unlock a user's account
+func (u *userService) UnlockUser(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error) {
+	return u.lockUnlock(ctx, ctxInfo, idOrEmail, false)
+}
+
+// lockUnlock locks or unlocks a user's account and audits the change
+func (u *userService) lockUnlock(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string, isLocked bool) (*model.User, error) {
+	before, err := u.dbRepo.GetUser(ctx, idOrEmail)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not fetch user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	// Staff cannot lock their peers or their superiors, nor anyone who can assign roles
+	if utility.HasPermission(before.Role, constant.PermRoleAssign) ||
+		constant.RoleRanks[before.Role] >= constant.RoleRanks[ctxInfo.Role] {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "cannot lock or unlock a user of this role")
+	}
+
+	// A lock is stored along with the event announcing it
+	var user *model.User
+	err = events.Transact(ctx, u.bus, u.dbRepo, func(tx storage.StorageRepository) ([]events.Event, error) {
+		var err error
+		if user, err = tx.LockUnlock(ctx, before.ID, isLocked); err != nil || !isLocked {
+			return nil, err
+		}
+		return []events.Event{events.UserLocked{User: *sanitize(user)}}, nil
+	})
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("could not unlock user, got error: %w", err)
+			return nil, apperror.Internal(err, "could not update user")
 		}
-		return nil, fmt.Errorf("user does not exist")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	action := constant.AuditUserUnlock
+	if isLocked {
+		action = constant.AuditUserLock
 	}
+	audit.Record(ctx, u.dbRepo, ctxInfo, action, constant.AuditTargetUser, before.ID, sanitize(before), sanitize(user))
 
 	return user, nil
 }
 
-// UnlockUser contains business logic to unlock a user's account
-func (u *userService) UnlockUser(idOrEmail string) (*model.User, error) {
+// AssignRole contains business logic to change the role of a user. The change is recorded and
+// takes effect on the user's next request
+func (u *userService) AssignRole(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail, role string) (*model.User, error) {
+	newRole, ok := constant.Roles[role]
+	if !ok {
+		return nil, apperror.Validation(apperror.CodeInvalidRole, "unknown role '%s'", role)
+	}
 
-	user, err := u.dbRepo.LockUnlock(context.TODO(), idOrEmail, false)
+	user, err := u.dbRepo.GetUser(ctx, idOrEmail)
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("could not unlock user, got error: %w", err)
+			return nil, apperror.Internal(err, "could not fetch user")
 		}
-		return nil, fmt.Errorf("user does not exist")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
 	}
 
+	// Prevents the last admin from locking everyone out
+	if user.ID == ctxInfo.ID {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "cannot change your own role")
+	}
+
+	change := &model.RoleChange{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		ChangedBy: ctxInfo.ID,
+		NewRole:   newRole,
+		CreatedAt: time.Now(),
+	}
+
+	user, err = u.dbRepo.AssignRole(ctx, change)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not assign role")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditRoleChange, constant.AuditTargetUser, change.UserID,
+		map[string]string{"role": utility.RoleName(change.OldRole)}, map[string]string{"role": utility.RoleName(change.NewRole)})
+
+	// Omit password and salt from response
+	user.Password = ""
+	user.Salt = ""
+
 	return user, nil
 }
+
+// GetRoleChanges contains business logic to fetch the history of a user's roles
+func (u *userService) GetRoleChanges(ctx context.Context, idOrEmail string) ([]model.RoleChange, error) {
+	user, err := u.dbRepo.GetUser(ctx, idOrEmail)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not fetch user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	changes, err := u.dbRepo.GetRoleChanges(ctx, user.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get role changes")
+	}
+
+	return changes, nil
+}
+
+// Delete contains business logic to move a user and their urls to the trash, their links in
+// workspaces stay live. Their email and hashes stay reserved until they are purged after the
+// retention period
+func (u *userService) Delete(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error) {
+	fUser, err := u.dbRepo.GetUser(ctx, idOrEmail)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not fetch user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	if fUser.ID == ctxInfo.ID {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "cannot delete your own account from here")
+	}
+
+	user, err := u.dbRepo.DeleteUser(ctx, fUser.ID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not delete user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user does not exist")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditUserDelete, constant.AuditTargetUser, fUser.ID, sanitize(fUser), nil)
+
+	return sanitize(user), nil
+}
+
+// Trash contains business logic to fetch the deleted users that can still be restored
+func (u *userService) Trash(ctx context.Context) ([]model.User, error) {
+	users, err := u.dbRepo.GetDeletedUsers(ctx)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get deleted users")
+	}
+
+	restorable := []model.User{}
+	for _, user := range users {
+		if !retention.Expired(user.DeletedAt) {
+			restorable = append(restorable, *sanitize(&user))
+		}
+	}
+
+	return restorable, nil
+}
+
+// Restore contains business logic to take a deleted user and the urls deleted with them out of
+// the trash, within the retention period
+func (u *userService) Restore(ctx context.Context, ctxInfo *model.ContextInfo, idOrEmail string) (*model.User, error) {
+	deleted, err := u.dbRepo.GetDeletedUser(ctx, idOrEmail)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not fetch user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found in trash")
+	}
+
+	if retention.Expired(deleted.DeletedAt) {
+		return nil, apperror.Conflict(apperror.CodeRestoreExpired, "user was deleted more than %d days ago and can no longer be restored", int(retention.Period().Hours()/24))
+	}
+
+	user, err := u.dbRepo.RestoreUser(ctx, deleted.ID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not restore user")
+		}
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found in trash")
+	}
+
+	audit.Record(ctx, u.dbRepo, ctxInfo, constant.AuditUserRestore, constant.AuditTargetUser, deleted.ID, nil, sanitize(user))
+
+	return sanitize(user), nil
+}
+
+// sanitize returns a copy of 'user' that is safe to record, without its password and salt
+func sanitize(user *model.User) *model.User {
+	if user == nil {
+		return nil
+	}
+
+	c := *user
+	c.Password = ""
+	c.Salt = ""
+	c.Urls = nil
+	return &c
+}
@@ -1,10 +1,258 @@
 package user_test
 
 import (
+	"archive/zip"
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
 	"brief/pkg/repository/storage"
 	"brief/service/mock"
 	"brief/service/url"
+	"brief/service/user"
+	"brief/utility"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
 )
 
 var mockStorage storage.StorageRepository = &mock.Repo{}
-var storageService url.UrlService = url.NewUrlService(mockStorage)
+var storageService url.UrlService = url.NewUrlService(mockStorage, nil, events.NewBus())
+var userService user.UserService = user.NewUserService(mockStorage, events.NewBus())
+
+func TestAssignRole(t *testing.T) {
+	t.Run("Assign", func(t *testing.T) {
+		u, err := userService.AssignRole(context.Background(), &model.ContextInfo{ID: "admin-id"}, "test-id", constant.Moderator)
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if u != nil && u.Role != constant.Roles[constant.Moderator] {
+			t.Errorf("Expected 'user.Role' to be '%v', got '%v'", constant.Roles[constant.Moderator], u.Role)
+		}
+	})
+
+	t.Run("Unknown_Role", func(t *testing.T) {
+		_, err := userService.AssignRole(context.Background(), &model.ContextInfo{ID: "admin-id"}, "test-id", "superuser")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+
+	t.Run("Own_Role", func(t *testing.T) {
+		_, err := userService.AssignRole(context.Background(), &model.ContextInfo{ID: "admin-id"}, "admin-id", constant.User)
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
+
+func TestLockUser(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Actor   string
+		Target  string
+		IsError bool
+	}{
+		{"Moderator_Locks_User", constant.Moderator, "test-id", false},
+		{"Admin_Locks_Moderator", constant.Admin, "moderator-id", false},
+		{"Moderator_Locks_Moderator", constant.Moderator, "moderator-id", true},
+		{"Moderator_Locks_Admin", constant.Moderator, "admin-id", true},
+		{"Admin_Locks_Admin", constant.Admin, "admin-id", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := userService.LockUser(context.Background(), &model.ContextInfo{ID: "actor-id", Role: constant.Roles[test.Actor]}, test.Target)
+			if (err != nil) != test.IsError {
+				t.Errorf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("Other_User", func(t *testing.T) {
+		u, err := userService.Delete(context.Background(), &model.ContextInfo{ID: "admin-id"}, "test-id")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if u != nil && !u.DeletedAt.Valid {
+			t.Errorf("Expected 'user.DeletedAt' to be set")
+		}
+	})
+
+	t.Run("Own_Account", func(t *testing.T) {
+		_, err := userService.Delete(context.Background(), &model.ContextInfo{ID: "admin-id"}, "admin-id")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	// the mock trash holds users deleted long ago for 'expired' ids
+	t.Run("Within_Retention", func(t *testing.T) {
+		_, err := userService.Restore(context.Background(), &model.ContextInfo{ID: "admin-id"}, "test-id")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Past_Retention", func(t *testing.T) {
+		_, err := userService.Restore(context.Background(), &model.ContextInfo{ID: "admin-id"}, "expired-id")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
+
+// account keeps the calls made to delete an account with the password 'password'
+type account struct {
+	mock.Repo
+	password, salt string
+	inTx           bool
+	calls          []string
+	audits         []model.AuditLog
+}
+
+func (a *account) GetUser(ctx context.Context, idOrEmail string) (*model.User, error) {
+	return &model.User{ID: idOrEmail, Email: "jane@example.com", Firstname: "Jane", Password: a.password, Salt: a.salt,
+		Role: constant.Roles[constant.User]}, nil
+}
+
+func (a *account) Transaction(ctx context.Context, fn func(tx storage.StorageRepository) error) error {
+	a.inTx = true
+	defer func() { a.inTx = false }()
+	return fn(a)
+}
+
+func (a *account) call(name string) {
+	if a.inTx {
+		name += " in transaction"
+	}
+	a.calls = append(a.calls, name)
+}
+
+func (a *account) PurgeUser(ctx context.Context, id string) error {
+	a.call("PurgeUser")
+	return nil
+}
+
+func (a *account) DeleteUser(ctx context.Context, id string) (*model.User, error) {
+	a.call("DeleteUser")
+	return &model.User{ID: id}, nil
+}
+
+func (a *account) TransferUrls(ctx context.Context, fromUserID, toUserID string) (int64, error) {
+	a.call("TransferUrls")
+	return 0, nil
+}
+
+func (a *account) AnonymizeUser(ctx context.Context, id, email string) (*model.User, error) {
+	a.call("AnonymizeUser")
+	return &model.User{ID: id}, nil
+}
+
+func (a *account) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	a.audits = append(a.audits, *entry)
+	return nil
+}
+
+func TestDeleteAccount(t *testing.T) {
+	cfg := config.Config
+	config.Config = &config.Configuration{AdminID: "admin-id"}
+	defer func() { config.Config = cfg }()
+
+	hashed, salt, err := utility.HashPassword("password")
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	tests := []struct {
+		Name     string
+		Password string
+		Links    string
+		Expected []string
+		IsError  bool
+	}{
+		{"Delete", "password", constant.LinksDelete, []string{"PurgeUser"}, false},
+		{"Transfer", "password", constant.LinksTransfer, []string{"TransferUrls in transaction", "PurgeUser in transaction"}, false},
+		{"Keep", "password", constant.LinksKeep, []string{"AnonymizeUser"}, false},
+		{"Wrong_Password", "wrong", constant.LinksDelete, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			repo := &account{password: hashed, salt: salt}
+			err := user.NewUserService(repo, events.NewBus()).DeleteAccount(context.Background(),
+				&model.ContextInfo{ID: "test-id"}, &model.DeleteAccount{Password: test.Password, Links: test.Links})
+			if (err != nil) != test.IsError {
+				t.Fatalf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+
+			if strings.Join(repo.calls, ", ") != strings.Join(test.Expected, ", ") {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, repo.calls)
+			}
+			if test.IsError {
+				return
+			}
+			if len(repo.audits) != 1 || string(repo.audits[0].Before) != `{"id":"test-id"}` {
+				t.Errorf("Expected an audit log of the user id only, got '%+v'", repo.audits)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	archive, err := userService.Export(context.Background(), &model.ContextInfo{ID: "test-id"})
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("Expected a valid ZIP archive, got error '%v'", err)
+	}
+
+	expected := []string{"profile.json", "links.json", "clicks.json", "click_stats.json"}
+	if len(zr.File) != len(expected) {
+		t.Fatalf("Expected '%v' files, got '%v'", len(expected), len(zr.File))
+	}
+	for i, f := range zr.File {
+		if f.Name != expected[i] {
+			t.Errorf("Expected file '%v', got '%v'", expected[i], f.Name)
+		}
+	}
+}
+
+func TestLinkStats(t *testing.T) {
+	now := time.Now()
+	urls := []model.URL{{ID: "a", Hash: "abc"}, {ID: "b", Hash: "def"}}
+	clicks := []model.Click{
+		{URLID: "a", Country: "NG", CreatedAt: now.Add(-time.Hour)},
+		{URLID: "a", Country: "NG", CreatedAt: now},
+		{URLID: "a", CreatedAt: now.Add(-2 * time.Hour)},
+		{URLID: "c", Country: "US", CreatedAt: now},
+	}
+
+	stats := user.LinkStats(urls, clicks)
+	if len(stats) != 2 {
+		t.Fatalf("Expected '2' stats, got '%v'", len(stats))
+	}
+
+	if stats[0].Clicks != 3 || stats[0].Countries["NG"] != 2 {
+		t.Errorf("Expected '3' clicks with '2' from 'NG', got '%v' and '%v'", stats[0].Clicks, stats[0].Countries["NG"])
+	}
+
+	if !stats[0].FirstClick.Equal(now.Add(-2*time.Hour)) || !stats[0].LastClick.Equal(now) {
+		t.Errorf("Expected first and last clicks to be tracked, got '%v' and '%v'", stats[0].FirstClick, stats[0].LastClick)
+	}
+
+	if stats[1].Clicks != 0 || stats[1].FirstClick != nil {
+		t.Errorf("Expected no clicks for 'def', got '%v'", stats[1].Clicks)
+	}
+}
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/metrics"
+	"brief/pkg/repository/storage"
+	"brief/pkg/retry"
+	"brief/pkg/tracing"
+	"brief/utility"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+var (
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+	batchSize          = 20
+	baseBackoff        = 30 * time.Second
+	maxBackoff         = 6 * time.Hour
+)
+
+type Dispatcher interface {
+	// Dispatch attempts the deliveries due at 'now' and returns how many were attempted
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+	Run(ctx context.Context, logger *log.Logger, interval time.Duration)
+}
+
+type dispatcher struct {
+	dbRepo      storage.StorageRepository
+	client      *http.Client
+	maxAttempts int
+}
+
+// NewDispatcher returns a dispatcher giving webhooks 'timeout' to answer, a delivery is failed
+// after 'maxAttempts' attempts. Defaults are used for values that are not positive
+func NewDispatcher(dbRepo storage.StorageRepository, timeout time.Duration, maxAttempts int) Dispatcher {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &dispatcher{dbRepo: dbRepo, client: newClient(timeout), maxAttempts: maxAttempts}
+}
+
+// policy returns how deliveries are retried, 'maxAttempts' times at most with a backoff doubling
+// from 30 seconds up to 6 hours
+func policy(maxAttempts int) retry.Policy {
+	return retry.Policy{Base: baseBackoff, Max: maxBackoff, MaxAttempts: maxAttempts}
+}
+
+// Dispatch contains business logic to send a batch of due deliveries concurrently. Claimed
+// deliveries are leased for longer than a request can take, an instance that stops while sending
+// them leaves them to be retried once the lease is over
+func (d *dispatcher) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := d.dbRepo.ClaimDeliveries(ctx, now, d.client.Timeout+time.Minute, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("could not claim deliveries, got error: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			d.attempt(ctx, delivery, now)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// Run sends due deliveries every 'interval' until 'ctx' is done, full batches are followed
+// by the next one right away
+func (d *dispatcher) Run(ctx context.Context, logger *log.Logger, interval time.Duration) {
+	retry.Poll(ctx, interval, batchSize, d.Dispatch, func(err error) {
+		logger.Errorf("webhook dispatch failed: %s", err)
+	})
+}
+
+// attempt sends 'delivery' once and stores the outcome
+func (d *dispatcher) attempt(ctx context.Context, delivery *model.WebhookDelivery, now time.Time) {
+	ctx, span := tracing.Start(ctx, "webhook.Deliver",
+		attribute.String("webhook.event", delivery.Event), attribute.String("webhook.delivery", delivery.ID))
+	delivery.Attempts++
+	status, err := d.send(ctx, delivery, now)
+	tracing.End(span, err)
+	delivery.ResponseStatus = status
+
+	outcome, retryAt := policy(d.maxAttempts).Next(delivery.Attempts, err, now)
+	switch outcome {
+	case retry.Succeeded:
+		delivery.Status = constant.DeliverySucceeded
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+	case retry.Failed:
+		delivery.Status = constant.DeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = retryAt
+	}
+	metrics.WebhookDeliveries.WithLabelValues(delivery.Event, outcome).Inc()
+
+	// The outcome is stored even if the dispatcher is stopping
+	if err := d.dbRepo.UpdateDelivery(utility.Detach(ctx), delivery); err != nil {
+		log.Errorf("could not update delivery '%s', got error: %s", delivery.ID, err)
+	}
+}
+
+// send posts the payload of 'delivery' to its webhook and returns the status code it answered with
+func (d *dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery, now time.Time) (int, error) {
+	webhook, err := d.dbRepo.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, fmt.Errorf("webhook was deleted")
+		}
+		return 0, fmt.Errorf("could not fetch webhook, got error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("could not build request, got error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Brief-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, now, delivery.Payload))
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a bounded part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("got status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
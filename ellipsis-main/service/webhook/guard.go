@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"brief/internal/config"
+	"brief/pkg/netguard"
+	"context"
+	"net/http"
+	"time"
+)
+
+// allowPrivate reports whether webhooks may target loopback, private and link-local addresses
+func allowPrivate() bool {
+	cfg := config.GetConfig()
+	return cfg != nil && cfg.WebhookAllowPrivate
+}
+
+// checkURL ensures that every address the host of 'rawURL' resolves to is public
+func checkURL(ctx context.Context, rawURL string) error {
+	if allowPrivate() {
+		return nil
+	}
+	return netguard.CheckURL(ctx, rawURL)
+}
+
+// newClient returns a client giving webhooks 'timeout' to answer. It only connects to public
+// addresses and does not follow redirects
+func newClient(timeout time.Duration) *http.Client {
+	return netguard.NewClient(timeout, allowPrivate)
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/pkg/repository/storage"
+	"brief/utility"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Publish queues 'event' with 'data' in the outbox of every webhook of the user with 'ownerID'
+// subscribed to it. Events about users are queued for every webhook subscribed to them whose owner
+// currently has the permission to read any user
+func Publish(ctx context.Context, dbRepo storage.StorageRepository, ownerID, event string, data interface{}) error {
+	if event == constant.EventUserLocked {
+		ownerID = ""
+	} else if ownerID == "" {
+		// Anonymous links have no webhooks
+		return nil
+	}
+
+	// The event is queued even if 'ctx' has been cancelled since the action completed
+	ctx = utility.Detach(ctx)
+
+	webhooks, err := dbRepo.GetSubscribedWebhooks(ctx, ownerID, event)
+	if err != nil {
+		return fmt.Errorf("could not get webhooks subscribed to '%s', got error: %w", event, err)
+	}
+	if ownerID == "" {
+		if webhooks, err = staffOnly(ctx, dbRepo, webhooks); err != nil {
+			return err
+		}
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(model.WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      event,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode '%s' event, got error: %w", event, err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = model.WebhookDelivery{
+			ID:            uuid.NewString(),
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       payload,
+			Status:        constant.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+	}
+
+	if err := dbRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("could not queue '%s' event, got error: %w", event, err)
+	}
+	return nil
+}
+
+// staffOnly returns the 'webhooks' whose owner may still read any user, the role of an owner may
+// have changed since they subscribed
+func staffOnly(ctx context.Context, dbRepo storage.StorageRepository, webhooks []model.Webhook) ([]model.Webhook, error) {
+	allowed := map[string]bool{}
+	kept := webhooks[:0]
+	for _, webhook := range webhooks {
+		ok, seen := allowed[webhook.UserID]
+		if !seen {
+			owner, err := dbRepo.GetUser(ctx, webhook.UserID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("could not get owner of webhook '%s', got error: %w", webhook.ID, err)
+			}
+			ok = err == nil && utility.HasPermission(owner.Role, constant.PermUserReadAny)
+			allowed[webhook.UserID] = ok
+		}
+		if ok {
+			kept = append(kept, webhook)
+		}
+	}
+	return kept, nil
+}
+
+// Subscribe queues the domain events published on 'bus' in the outbox of the webhooks subscribed
+// to them. Clicks are queued in a goroutine of their own, other events in the publisher's
+func Subscribe(bus events.Bus, dbRepo storage.StorageRepository) {
+	bus.Subscribe(constant.DomainURLCreated, "webhook", events.Sync, func(ctx context.Context, event events.Event) error {
+		e := event.(events.URLCreated)
+		return Publish(ctx, dbRepo, e.URL.UserID, constant.EventURLCreated, &e.URL)
+	})
+	bus.Subscribe(constant.DomainURLDeleted, "webhook", events.Sync, func(ctx context.Context, event events.Event) error {
+		e := event.(events.URLDeleted)
+		return Publish(ctx, dbRepo, e.URL.UserID, constant.EventURLDeleted, &e.URL)
+	})
+	bus.Subscribe(constant.DomainRedirected, "webhook", events.Async, func(ctx context.Context, event events.Event) error {
+		e := event.(events.Redirected)
+		return Publish(ctx, dbRepo, e.URL.UserID, constant.EventURLClicked, &e.Click)
+	})
+	bus.Subscribe(constant.DomainURLBroken, "webhook", events.Sync, func(ctx context.Context, event events.Event) error {
+		e := event.(events.URLBroken)
+		return Publish(ctx, dbRepo, e.URL.UserID, constant.EventURLBroken, &e)
+	})
+	bus.Subscribe(constant.DomainUserLocked, "webhook", events.Sync, func(ctx context.Context, event events.Event) error {
+		e := event.(events.UserLocked)
+		return Publish(ctx, dbRepo, e.User.ID, constant.EventUserLocked, &e.User)
+	})
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of the requests sent to webhooks
+const (
+	SignatureHeader = "X-Brief-Signature" // 't=<unix time>,v1=<hex HMAC-SHA256 of '<unix time>.<body>'>'
+	EventHeader     = "X-Brief-Event"
+	DeliveryHeader  = "X-Brief-Delivery"
+)
+
+// Sign returns the signature header of 'payload' sent at 'timestamp', made with the secret of
+// the webhook. The timestamp is signed so that captured requests cannot be replayed later
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, digest(secret, t, payload))
+}
+
+// Verify checks the signature 'header' of 'payload' against the secret of the webhook, and that it
+// was made within 'tolerance' of 'now'. Receivers written in Go can use it as is
+func Verify(secret, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return fmt.Errorf("malformed signature '%s'", header)
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature is %s old, more than %s", age.Round(time.Second), tolerance)
+	}
+	if !hmac.Equal([]byte(v1), []byte(digest(secret, t, payload))) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
+
+func digest(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
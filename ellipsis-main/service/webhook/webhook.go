@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/utility"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var deliveriesLimit = 100
+
+type WebhookService interface {
+	Create(ctx context.Context, webhook *model.Webhook, ctxInfo *model.ContextInfo) (*model.Webhook, error)
+	GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Webhook, error)
+	Delete(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Webhook, error)
+	GetDeliveries(ctx context.Context, ctxInfo *model.ContextInfo, id string) ([]model.WebhookDelivery, error)
+	Replay(ctx context.Context, ctxInfo *model.ContextInfo, id, deliveryID string) (*model.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	dbRepo storage.StorageRepository
+}
+
+func NewWebhookService(dbRepo storage.StorageRepository) WebhookService {
+	return &webhookService{dbRepo: dbRepo}
+}
+
+// Create contains business logic to register a webhook of the requesting user. Its secret is only
+// returned here, it signs every payload sent to the webhook
+func (ws *webhookService) Create(ctx context.Context, webhook *model.Webhook, ctxInfo *model.ContextInfo) (*model.Webhook, error) {
+	events := make([]string, 0, len(webhook.Events))
+	seen := make(map[string]bool, len(webhook.Events))
+	for _, event := range webhook.Events {
+		if seen[event] {
+			continue
+		}
+		seen[event] = true
+		events = append(events, event)
+	}
+
+	// Events about users reveal other accounts
+	if seen[constant.EventUserLocked] && !utility.HasPermission(ctxInfo.Role, constant.PermUserReadAny) {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "unauthorized to subscribe to '%s'", constant.EventUserLocked)
+	}
+
+	if err := checkURL(ctx, webhook.URL); err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidURL, "invalid webhook url, %s", err)
+	}
+
+	secret, err := utility.GenerateSecret(32)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not generate secret")
+	}
+
+	webhook.ID = uuid.NewString()
+	webhook.UserID = ctxInfo.ID
+	webhook.Events = events
+	webhook.Secret = "whsec_" + secret
+	webhook.CreatedAt = time.Now()
+
+	if err := ws.dbRepo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, apperror.Internal(err, "could not create webhook")
+	}
+
+	return webhook, nil
+}
+
+// GetAll contains business logic to fetch all webhooks of the requesting user
+func (ws *webhookService) GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Webhook, error) {
+	webhooks, err := ws.dbRepo.GetWebhooks(ctx, ctxInfo.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get webhooks")
+	}
+
+	// Omit secrets from response
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// Delete contains business logic to delete a webhook, its pending deliveries are dropped
+func (ws *webhookService) Delete(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Webhook, error) {
+	if _, err := ws.authorize(ctx, ctxInfo, id); err != nil {
+		return nil, err
+	}
+
+	webhook, err := ws.dbRepo.DeleteWebhook(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeWebhookNotFound, "webhook not found")
+		}
+		return nil, apperror.Internal(err, "could not delete webhook")
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// GetDeliveries contains business logic to fetch the delivery log of a webhook, latest first
+func (ws *webhookService) GetDeliveries(ctx context.Context, ctxInfo *model.ContextInfo, id string) ([]model.WebhookDelivery, error) {
+	if _, err := ws.authorize(ctx, ctxInfo, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := ws.dbRepo.GetDeliveries(ctx, id, deliveriesLimit)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// Replay contains business logic to send a delivery of a webhook again. The replay is a new
+// delivery of the same event, the original stays in the log
+func (ws *webhookService) Replay(ctx context.Context, ctxInfo *model.ContextInfo, id, deliveryID string) (*model.WebhookDelivery, error) {
+	if _, err := ws.authorize(ctx, ctxInfo, id); err != nil {
+		return nil, err
+	}
+
+	original, err := ws.dbRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apperror.Internal(err, "could not fetch delivery")
+		}
+		return nil, apperror.NotFound(apperror.CodeDeliveryNotFound, "delivery not found")
+	}
+	if original.WebhookID != id {
+		return nil, apperror.NotFound(apperror.CodeDeliveryNotFound, "delivery not found")
+	}
+
+	now := time.Now()
+	replay := model.WebhookDelivery{
+		ID:            uuid.NewString(),
+		WebhookID:     original.WebhookID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        constant.DeliveryPending,
+		NextAttemptAt: now,
+		ReplayOf:      original.ID,
+		CreatedAt:     now,
+	}
+	if err := ws.dbRepo.CreateDeliveries(ctx, []model.WebhookDelivery{replay}); err != nil {
+		return nil, apperror.Internal(err, "could not replay delivery")
+	}
+
+	return &replay, nil
+}
+
+// authorize fetches a webhook and ensures it can be managed by the requesting user
+func (ws *webhookService) authorize(ctx context.Context, ctxInfo *model.ContextInfo, id string) (*model.Webhook, error) {
+	webhook, err := ws.dbRepo.GetWebhook(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeWebhookNotFound, "webhook not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch webhook")
+	}
+
+	if !utility.HasPermission(ctxInfo.Role, constant.PermWebhookManageAny) && webhook.UserID != ctxInfo.ID {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action")
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,457 @@
+// build+ unit
+package webhook_test
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/config"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/events"
+	"brief/service/mock"
+	"brief/service/webhook"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// outbox keeps webhooks and their deliveries in memory
+type outbox struct {
+	mock.Repo
+	mu         sync.Mutex
+	webhooks   map[string]model.Webhook
+	deliveries map[string]model.WebhookDelivery
+}
+
+func newOutbox(webhooks ...model.Webhook) *outbox {
+	o := &outbox{webhooks: map[string]model.Webhook{}, deliveries: map[string]model.WebhookDelivery{}}
+	for _, w := range webhooks {
+		o.webhooks[w.ID] = w
+	}
+	return o
+}
+
+func (o *outbox) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	w, ok := o.webhooks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &w, nil
+}
+
+func (o *outbox) GetSubscribedWebhooks(ctx context.Context, userID, event string) ([]model.Webhook, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var webhooks []model.Webhook
+	for _, w := range o.webhooks {
+		for _, e := range w.Events {
+			if e == event && (userID == "" || w.UserID == userID) {
+				webhooks = append(webhooks, w)
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+func (o *outbox) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, d := range deliveries {
+		o.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+func (o *outbox) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	d, ok := o.deliveries[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &d, nil
+}
+
+func (o *outbox) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []model.WebhookDelivery
+	for id, d := range o.deliveries {
+		if d.Status == constant.DeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, d)
+			d.NextAttemptAt = now.Add(lease)
+			o.deliveries[id] = d
+		}
+	}
+	return due, nil
+}
+
+func (o *outbox) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+// all returns every delivery, oldest first
+func (o *outbox) all() []model.WebhookDelivery {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	deliveries := make([]model.WebhookDelivery, 0, len(o.deliveries))
+	for _, d := range o.deliveries {
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt) })
+	return deliveries
+}
+
+// receiver is a webhook endpoint answering with 'status' and checking signatures with 'secret'
+func receiver(t *testing.T, secret string, status int) (*httptest.Server, chan model.WebhookEvent) {
+	received := make(chan model.WebhookEvent, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		var event model.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+		if r.Header.Get(webhook.EventHeader) != event.Type {
+			t.Errorf("Expected '%s', got '%s'", event.Type, r.Header.Get(webhook.EventHeader))
+		}
+		received <- event
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	allowPrivate(t)
+	return srv, received
+}
+
+// allowPrivate lets webhooks target the loopback address of test receivers until 't' is done
+func allowPrivate(t *testing.T) {
+	cfg := config.Config
+	config.Config = &config.Configuration{WebhookAllowPrivate: true}
+	t.Cleanup(func() { config.Config = cfg })
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Status      int
+		Attempts    int // failed attempts before this one
+		Expected    string
+		NextAttempt time.Duration
+		Error       string
+	}{
+		{"Delivered", http.StatusNoContent, 0, constant.DeliverySucceeded, 0, ""},
+		{"Retried", http.StatusInternalServerError, 0, constant.DeliveryPending, 30 * time.Second, "got status code 500"},
+		{"Backed_Off", http.StatusBadGateway, 3, constant.DeliveryPending, 4 * time.Minute, "got status code 502"},
+		{"Out_Of_Attempts", http.StatusInternalServerError, 4, constant.DeliveryFailed, 0, "got status code 500"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			srv, received := receiver(t, "whsec_test", test.Status)
+			repo := newOutbox(model.Webhook{ID: "webhook-id", UserID: "test-id", URL: srv.URL,
+				Events: []string{constant.EventURLCreated}, Secret: "whsec_test"})
+
+			webhook.Publish(context.Background(), repo, "test-id", constant.EventURLCreated, &model.URL{ID: "url-id"})
+			queued := repo.all()
+			if len(queued) != 1 {
+				t.Fatalf("Expected '1' delivery, got '%d'", len(queued))
+			}
+			queued[0].Attempts = test.Attempts
+			repo.UpdateDelivery(context.Background(), &queued[0])
+
+			now := time.Now()
+			n, err := webhook.NewDispatcher(repo, time.Second, 5).Dispatch(context.Background(), now)
+			if err != nil || n != 1 {
+				t.Fatalf("Expected '1' attempt, got '%d' and '%v'", n, err)
+			}
+
+			event := <-received
+			if event.Type != constant.EventURLCreated || event.ID == "" {
+				t.Errorf("Expected a '%s' event, got '%+v'", constant.EventURLCreated, event)
+			}
+
+			delivery := repo.all()[0]
+			if delivery.Status != test.Expected {
+				t.Errorf("Expected '%s', got '%s'", test.Expected, delivery.Status)
+			}
+			if delivery.Attempts != test.Attempts+1 || delivery.ResponseStatus != test.Status {
+				t.Errorf("Expected attempt '%d' answered with '%d', got '%+v'", test.Attempts+1, test.Status, delivery)
+			}
+			if delivery.Error != test.Error {
+				t.Errorf("Expected '%s', got '%s'", test.Error, delivery.Error)
+			}
+			if test.NextAttempt > 0 && !delivery.NextAttemptAt.Equal(now.Add(test.NextAttempt)) {
+				t.Errorf("Expected next attempt in '%s', got '%s'", test.NextAttempt, delivery.NextAttemptAt.Sub(now))
+			}
+
+			// Deliveries that are not due are left alone
+			if n, _ := webhook.NewDispatcher(repo, time.Second, 5).Dispatch(context.Background(), now); n != 0 {
+				t.Errorf("Expected '0' attempts, got '%d'", n)
+			}
+		})
+	}
+}
+
+func TestPublish(t *testing.T) {
+	repo := newOutbox(
+		model.Webhook{ID: "owner", UserID: "test-id", Events: []string{constant.EventURLClicked}},
+		model.Webhook{ID: "other", UserID: "other-id", Events: []string{constant.EventURLClicked}},
+		model.Webhook{ID: "staff", UserID: "moderator-id", Events: []string{constant.EventUserLocked}},
+		// Subscribed while a moderator, the owner has since lost the role
+		model.Webhook{ID: "demoted", UserID: "demoted-id", Events: []string{constant.EventUserLocked}},
+	)
+
+	tests := []struct {
+		Name     string
+		OwnerID  string
+		Event    string
+		Expected []string
+	}{
+		{"Owner_Only", "test-id", constant.EventURLClicked, []string{"owner"}},
+		{"Anonymous_Link", "", constant.EventURLClicked, nil},
+		{"Not_Subscribed", "test-id", constant.EventURLDeleted, nil},
+		{"User_Event", "locked-id", constant.EventUserLocked, []string{"staff"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			repo.deliveries = map[string]model.WebhookDelivery{}
+			if err := webhook.Publish(context.Background(), repo, test.OwnerID, test.Event, nil); err != nil {
+				t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+			}
+
+			var got []string
+			for _, d := range repo.all() {
+				got = append(got, d.WebhookID)
+			}
+			if len(got) != len(test.Expected) || (len(got) > 0 && got[0] != test.Expected[0]) {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, got)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	bus := events.NewBus()
+	repo := newOutbox(model.Webhook{ID: "webhook-id", UserID: "moderator-id",
+		Events: []string{constant.EventURLCreated, constant.EventURLClicked, constant.EventURLBroken, constant.EventUserLocked}})
+	webhook.Subscribe(bus, repo)
+
+	tests := []struct {
+		Name     string
+		Event    events.Event
+		Expected string
+	}{
+		{"URL_Created", events.URLCreated{URL: model.URL{ID: "url-id", UserID: "moderator-id"}}, constant.EventURLCreated},
+		{"URL_Deleted", events.URLDeleted{URL: model.URL{ID: "url-id", UserID: "moderator-id"}}, ""},
+		{"Redirected", events.Redirected{URL: model.URL{ID: "url-id", UserID: "moderator-id"}, Click: model.Click{ID: "click-id"}}, constant.EventURLClicked},
+		{"URL_Broken", events.URLBroken{URL: model.URL{ID: "url-id", UserID: "moderator-id"}, Check: model.LinkCheck{StatusCode: 404}}, constant.EventURLBroken},
+		{"User_Registered", events.UserRegistered{User: model.User{ID: "user-id"}}, ""},
+		{"User_Locked", events.UserLocked{User: model.User{ID: "user-id"}}, constant.EventUserLocked},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			repo.deliveries = map[string]model.WebhookDelivery{}
+			bus.Publish(context.Background(), test.Event)
+			bus.Drain(context.Background())
+
+			deliveries := repo.all()
+			if test.Expected == "" {
+				if len(deliveries) != 0 {
+					t.Errorf("Expected no delivery, got '%+v'", deliveries)
+				}
+				return
+			}
+			if len(deliveries) != 1 || deliveries[0].Event != test.Expected {
+				t.Errorf("Expected a '%s' delivery, got '%+v'", test.Expected, deliveries)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	srv, received := receiver(t, "whsec_test", http.StatusOK)
+	repo := newOutbox(model.Webhook{ID: "webhook-id", UserID: "test-id", URL: srv.URL,
+		Events: []string{constant.EventURLDeleted}, Secret: "whsec_test"})
+	wService := webhook.NewWebhookService(repo)
+	dispatcher := webhook.NewDispatcher(repo, time.Second, 3)
+
+	webhook.Publish(context.Background(), repo, "test-id", constant.EventURLDeleted, &model.URL{ID: "url-id"})
+	dispatcher.Dispatch(context.Background(), time.Now())
+	original := repo.all()[0]
+	first := <-received
+
+	t.Run("Not_Owner", func(t *testing.T) {
+		_, err := wService.Replay(context.Background(), &model.ContextInfo{ID: "other-id", Role: constant.Roles[constant.User]}, "webhook-id", original.ID)
+		if !apperror.Is(err, apperror.KindForbidden) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindForbidden, err)
+		}
+	})
+
+	t.Run("Unknown_Delivery", func(t *testing.T) {
+		_, err := wService.Replay(context.Background(), &model.ContextInfo{ID: "test-id"}, "webhook-id", "delivery-id")
+		if !apperror.Is(err, apperror.KindNotFound) {
+			t.Errorf("Expected '%s' error, got '%v'", apperror.KindNotFound, err)
+		}
+	})
+
+	t.Run("Owner", func(t *testing.T) {
+		replay, err := wService.Replay(context.Background(), &model.ContextInfo{ID: "test-id"}, "webhook-id", original.ID)
+		if err != nil {
+			t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+		}
+		if replay.ReplayOf != original.ID || replay.Status != constant.DeliveryPending {
+			t.Errorf("Expected a pending replay of '%s', got '%+v'", original.ID, replay)
+		}
+
+		dispatcher.Dispatch(context.Background(), time.Now())
+		if second := <-received; second.ID != first.ID {
+			t.Errorf("Expected '%s', got '%s'", first.ID, second.ID)
+		}
+		if len(repo.all()) != 2 {
+			t.Errorf("Expected the original delivery to stay in the log, got '%d' deliveries", len(repo.all()))
+		}
+	})
+}
+
+func TestCreate(t *testing.T) {
+	wService := webhook.NewWebhookService(&mock.Repo{})
+
+	tests := []struct {
+		Name    string
+		Role    int
+		Events  []string
+		IsError bool
+	}{
+		{"User", constant.Roles[constant.User], []string{constant.EventURLCreated, constant.EventURLCreated, constant.EventURLClicked}, false},
+		{"User_Event_Forbidden", constant.Roles[constant.User], []string{constant.EventUserLocked}, true},
+		{"User_Event_Moderator", constant.Roles[constant.Moderator], []string{constant.EventUserLocked}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			w, err := wService.Create(context.Background(), &model.Webhook{URL: "https://1.1.1.1/hooks", Events: test.Events},
+				&model.ContextInfo{ID: "test-id", Role: test.Role})
+			if (err != nil) != test.IsError {
+				t.Fatalf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if w.UserID != "test-id" || len(w.Secret) < 40 {
+				t.Errorf("Expected a webhook of 'test-id' with a secret, got '%+v'", w)
+			}
+			if test.Name == "User" && len(w.Events) != 2 {
+				t.Errorf("Expected duplicate events to be dropped, got '%v'", w.Events)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"type":"url.created"}`)
+	now := time.Now()
+
+	tests := []struct {
+		Name    string
+		Header  string
+		IsError bool
+	}{
+		{"Valid", webhook.Sign("secret", now, payload), false},
+		{"Wrong_Secret", webhook.Sign("other", now, payload), true},
+		{"Too_Old", webhook.Sign("secret", now.Add(-10*time.Minute), payload), true},
+		{"Malformed", "v1=abc", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := webhook.Verify("secret", test.Header, payload, now, 5*time.Minute)
+			if (err != nil) != test.IsError {
+				t.Errorf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+		})
+	}
+}
+
+func TestGuard(t *testing.T) {
+	wService := webhook.NewWebhookService(&mock.Repo{})
+	ctxInfo := &model.ContextInfo{ID: "test-id", Role: constant.Roles[constant.User]}
+
+	tests := []struct {
+		Name    string
+		URL     string
+		IsError bool
+	}{
+		{"Public", "https://1.1.1.1/hooks", false},
+		{"Loopback", "http://127.0.0.1:8080/hooks", true},
+		{"Localhost", "http://localhost/hooks", true},
+		{"Private", "http://10.0.0.7/hooks", true},
+		{"Metadata", "http://169.254.169.254/latest/meta-data", true},
+		{"IPv6_Loopback", "http://[::1]/hooks", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := wService.Create(context.Background(), &model.Webhook{URL: test.URL, Events: []string{constant.EventURLCreated}}, ctxInfo)
+			if (err != nil) != test.IsError {
+				t.Errorf("Expected 'error' to be '%v', got '%v'", test.IsError, err)
+			}
+			if err != nil && !apperror.Is(err, apperror.KindValidation) {
+				t.Errorf("Expected '%s' error, got '%v'", apperror.KindValidation, err)
+			}
+		})
+	}
+
+	// A webhook whose host resolved to a public address when it was created
+	t.Run("Dial", func(t *testing.T) {
+		var hit bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+		defer srv.Close()
+		repo := newOutbox(model.Webhook{ID: "webhook-id", UserID: "test-id", URL: srv.URL,
+			Events: []string{constant.EventURLDeleted}, Secret: "whsec_test"})
+
+		webhook.Publish(context.Background(), repo, "test-id", constant.EventURLDeleted, &model.URL{ID: "url-id"})
+		webhook.NewDispatcher(repo, time.Second, 3).Dispatch(context.Background(), time.Now())
+
+		delivery := repo.all()[0]
+		if hit || delivery.Status == constant.DeliverySucceeded || !strings.Contains(delivery.Error, "not a public address") {
+			t.Errorf("Expected the loopback address not to be dialed, got '%+v'", delivery)
+		}
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		allowPrivate(t)
+		var redirected bool
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { redirected = true }))
+		defer target.Close()
+		srv := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer srv.Close()
+		repo := newOutbox(model.Webhook{ID: "webhook-id", UserID: "test-id", URL: srv.URL,
+			Events: []string{constant.EventURLDeleted}, Secret: "whsec_test"})
+
+		webhook.Publish(context.Background(), repo, "test-id", constant.EventURLDeleted, &model.URL{ID: "url-id"})
+		webhook.NewDispatcher(repo, time.Second, 3).Dispatch(context.Background(), time.Now())
+
+		if delivery := repo.all()[0]; redirected || delivery.ResponseStatus != http.StatusTemporaryRedirect {
+			t.Errorf("Expected the redirect not to be followed, got '%+v'", delivery)
+		}
+	})
+}
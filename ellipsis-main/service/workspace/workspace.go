@@ -0,0 +1,259 @@
+package workspace
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/utility"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var invitationTTL = 7 * 24 * time.Hour
+
+type WorkspaceService interface {
+	Create(ctx context.Context, workspace *model.Workspace, ctxInfo *model.ContextInfo) error
+	GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Workspace, error)
+	GetMembers(ctx context.Context, ctxInfo *model.ContextInfo, id string) ([]model.Membership, error)
+	UpdateMember(ctx context.Context, ctxInfo *model.ContextInfo, id, userID, role string) (*model.Membership, error)
+	RemoveMember(ctx context.Context, ctxInfo *model.ContextInfo, id, userID string) (*model.Membership, error)
+	Invite(ctx context.Context, ctxInfo *model.ContextInfo, id string, invitation *model.Invitation) error
+	Accept(ctx context.Context, ctxInfo *model.ContextInfo, token string) (*model.Membership, error)
+	Transfer(ctx context.Context, ctxInfo *model.ContextInfo, id, userID string) (*model.Workspace, error)
+}
+
+type workspaceService struct {
+	dbRepo storage.StorageRepository
+}
+
+func NewWorkspaceService(dbRepo storage.StorageRepository) WorkspaceService {
+	return &workspaceService{dbRepo: dbRepo}
+}
+
+// Authorize ensures the requesting user holds at least 'minRole' in a workspace with 'workspaceID'.
+// Roles allowed to manage any workspace are allowed in every workspace
+func Authorize(ctx context.Context, dbRepo storage.StorageRepository, ctxInfo *model.ContextInfo, workspaceID, minRole string) error {
+	if ctxInfo == nil || ctxInfo.ID == "" {
+		return apperror.Unauthorized(apperror.CodeSignInRequired, "workspaces can only be used by signed in users")
+	}
+
+	if utility.HasPermission(ctxInfo.Role, constant.PermWorkspaceManageAny) {
+		return nil
+	}
+
+	membership, err := dbRepo.GetMembership(ctx, workspaceID, ctxInfo.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action")
+		}
+		return apperror.Internal(err, "could not fetch membership")
+	}
+
+	if constant.WorkspaceRoles[membership.Role] < constant.WorkspaceRoles[minRole] {
+		return apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action, requires the '%s' role", minRole)
+	}
+
+	return nil
+}
+
+// Create contains business logic to create a workspace owned by the requesting user
+func (ws *workspaceService) Create(ctx context.Context, workspace *model.Workspace, ctxInfo *model.ContextInfo) error {
+	workspace.ID = uuid.NewString()
+	workspace.OwnerID = ctxInfo.ID
+	workspace.CreatedAt = time.Now()
+
+	if err := ws.dbRepo.CreateWorkspace(ctx, workspace); err != nil {
+		return apperror.Internal(err, "could not create workspace")
+	}
+
+	return nil
+}
+
+// GetAll contains business logic to fetch all workspaces the requesting user is a member of
+func (ws *workspaceService) GetAll(ctx context.Context, ctxInfo *model.ContextInfo) ([]model.Workspace, error) {
+	workspaces, err := ws.dbRepo.GetWorkspaces(ctx, ctxInfo.ID)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get workspaces")
+	}
+
+	return workspaces, nil
+}
+
+// GetMembers contains business logic to list the members of a workspace
+func (ws *workspaceService) GetMembers(ctx context.Context, ctxInfo *model.ContextInfo, id string) ([]model.Membership, error) {
+	if err := Authorize(ctx, ws.dbRepo, ctxInfo, id, constant.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := ws.dbRepo.GetMembers(ctx, id)
+	if err != nil {
+		return nil, apperror.Internal(err, "could not get members")
+	}
+
+	return members, nil
+}
+
+// UpdateMember contains business logic to change the role of a member, ownership can only be
+// changed through a transfer
+func (ws *workspaceService) UpdateMember(ctx context.Context, ctxInfo *model.ContextInfo, id, userID, role string) (*model.Membership, error) {
+	if err := Authorize(ctx, ws.dbRepo, ctxInfo, id, constant.WorkspaceOwner); err != nil {
+		return nil, err
+	}
+
+	if role == constant.WorkspaceOwner {
+		return nil, apperror.Conflict(apperror.CodeWorkspaceOwner, "ownership can only be changed by transferring the workspace")
+	}
+
+	if err := ws.ensureNotOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	membership, err := ws.dbRepo.UpdateMember(ctx, id, userID, role)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeMemberNotFound, "member not found")
+		}
+		return nil, apperror.Internal(err, "could not update member")
+	}
+
+	return membership, nil
+}
+
+// RemoveMember contains business logic to remove a member from a workspace. Owners can remove
+// anyone but themselves, other members can only leave. Links stay in the workspace
+func (ws *workspaceService) RemoveMember(ctx context.Context, ctxInfo *model.ContextInfo, id, userID string) (*model.Membership, error) {
+	if userID != ctxInfo.ID {
+		if err := Authorize(ctx, ws.dbRepo, ctxInfo, id, constant.WorkspaceOwner); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ws.ensureNotOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	membership, err := ws.dbRepo.RemoveMember(ctx, id, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeMemberNotFound, "member not found")
+		}
+		return nil, apperror.Internal(err, "could not remove member")
+	}
+
+	return membership, nil
+}
+
+// Invite contains business logic to invite a user to a workspace by email
+func (ws *workspaceService) Invite(ctx context.Context, ctxInfo *model.ContextInfo, id string, invitation *model.Invitation) error {
+	if err := Authorize(ctx, ws.dbRepo, ctxInfo, id, constant.WorkspaceOwner); err != nil {
+		return err
+	}
+
+	invitation.ID = uuid.NewString()
+	invitation.WorkspaceID = id
+	invitation.Email = strings.ToLower(invitation.Email)
+	invitation.Token = strings.ReplaceAll(uuid.NewString(), "-", "")
+	invitation.InvitedBy = ctxInfo.ID
+	invitation.CreatedAt = time.Now()
+	invitation.ExpiresAt = invitation.CreatedAt.Add(invitationTTL)
+	invitation.AcceptedAt = nil
+
+	if err := ws.dbRepo.CreateInvitation(ctx, invitation); err != nil {
+		return apperror.Internal(err, "could not create invitation")
+	}
+
+	// TODO: Send Invitation Email
+
+	return nil
+}
+
+// Accept contains business logic for the requesting user to join a workspace they were invited to
+func (ws *workspaceService) Accept(ctx context.Context, ctxInfo *model.ContextInfo, token string) (*model.Membership, error) {
+	invitation, err := ws.dbRepo.GetInvitation(ctx, token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeInvitationNotFound, "invitation not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch invitation")
+	}
+
+	if !strings.EqualFold(invitation.Email, ctxInfo.Email) {
+		return nil, apperror.Forbidden(apperror.CodeForbidden, "invitation was sent to a different email")
+	}
+
+	if invitation.AcceptedAt != nil {
+		return nil, apperror.Conflict(apperror.CodeInvitationAccepted, "invitation has already been accepted")
+	}
+
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, apperror.Conflict(apperror.CodeInvitationExpired, "invitation has expired")
+	}
+
+	membership := &model.Membership{
+		WorkspaceID: invitation.WorkspaceID,
+		UserID:      ctxInfo.ID,
+		Role:        invitation.Role,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := ws.dbRepo.AcceptInvitation(ctx, invitation, membership); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.Conflict(apperror.CodeInvitationAccepted, "invitation has already been accepted")
+		}
+		return nil, apperror.Internal(err, "could not accept invitation")
+	}
+
+	return membership, nil
+}
+
+// Transfer contains business logic to hand ownership of a workspace to another member
+func (ws *workspaceService) Transfer(ctx context.Context, ctxInfo *model.ContextInfo, id, userID string) (*model.Workspace, error) {
+	if err := Authorize(ctx, ws.dbRepo, ctxInfo, id, constant.WorkspaceOwner); err != nil {
+		return nil, err
+	}
+
+	current, err := ws.dbRepo.GetWorkspace(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFound(apperror.CodeWorkspaceNotFound, "workspace not found")
+		}
+		return nil, apperror.Internal(err, "could not fetch workspace")
+	}
+
+	if current.OwnerID == userID {
+		return current, nil
+	}
+
+	workspace, err := ws.dbRepo.TransferWorkspace(ctx, id, current.OwnerID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.Conflict(apperror.CodeWorkspaceOwner, "new owner must be a member of the workspace")
+		}
+		return nil, apperror.Internal(err, "could not transfer workspace")
+	}
+
+	return workspace, nil
+}
+
+// ensureNotOwner prevents the owner of a workspace from being demoted or removed
+func (ws *workspaceService) ensureNotOwner(ctx context.Context, id, userID string) error {
+	workspace, err := ws.dbRepo.GetWorkspace(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apperror.NotFound(apperror.CodeWorkspaceNotFound, "workspace not found")
+		}
+		return apperror.Internal(err, "could not fetch workspace")
+	}
+
+	if workspace.OwnerID == userID {
+		return apperror.Conflict(apperror.CodeWorkspaceOwner, "the owner cannot be changed, transfer the workspace first")
+	}
+
+	return nil
+}
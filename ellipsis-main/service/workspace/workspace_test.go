@@ -0,0 +1,148 @@
+// build+ unit
+package workspace_test
+
+import (
+	"brief/internal/constant"
+	"brief/internal/model"
+	"brief/pkg/repository/storage"
+	"brief/service/mock"
+	"brief/service/workspace"
+	"context"
+	"testing"
+)
+
+// The mock repository makes users members of every workspace with the role given by the
+// prefix of their id, e.g. 'editor-1'
+var mockStorage storage.StorageRepository = &mock.Repo{}
+var workspaceService workspace.WorkspaceService = workspace.NewWorkspaceService(mockStorage)
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		Name    string
+		UserID  string
+		Role    int
+		MinRole string
+		Allowed bool
+	}{
+		{"Owner_Needs_Owner", "owner-1", constant.Roles[constant.User], constant.WorkspaceOwner, true},
+		{"Editor_Needs_Owner", "editor-1", constant.Roles[constant.User], constant.WorkspaceOwner, false},
+		{"Editor_Needs_Editor", "editor-1", constant.Roles[constant.User], constant.WorkspaceEditor, true},
+		{"Viewer_Needs_Editor", "viewer-1", constant.Roles[constant.User], constant.WorkspaceEditor, false},
+		{"Viewer_Needs_Viewer", "viewer-1", constant.Roles[constant.User], constant.WorkspaceViewer, true},
+		{"Non_Member", "test-id", constant.Roles[constant.User], constant.WorkspaceViewer, false},
+		{"Admin", "test-id", constant.Roles[constant.Admin], constant.WorkspaceOwner, true},
+		// Moderators may read any url, not manage any workspace
+		{"Moderator", "test-id", constant.Roles[constant.Moderator], constant.WorkspaceViewer, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := workspace.Authorize(context.Background(), mockStorage, &model.ContextInfo{ID: test.UserID, Role: test.Role}, "workspace-id", test.MinRole)
+			if test.Allowed && err != nil {
+				t.Errorf("Expected 'error' to be nil, got '%v'", err)
+			}
+			if !test.Allowed && err == nil {
+				t.Errorf("Expected 'error' to be not nil")
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	w := &model.Workspace{Name: "marketing"}
+	if err := workspaceService.Create(context.Background(), w, &model.ContextInfo{ID: "test-id"}); err != nil {
+		t.Errorf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	if w.OwnerID != "test-id" {
+		t.Errorf("Expected 'workspace.OwnerID' to be 'test-id', got '%v'", w.OwnerID)
+	}
+}
+
+func TestUpdateMember(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		_, err := workspaceService.UpdateMember(context.Background(), &model.ContextInfo{ID: "owner-1"}, "workspace-id", "editor-1", constant.WorkspaceViewer)
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Promote_To_Owner", func(t *testing.T) {
+		_, err := workspaceService.UpdateMember(context.Background(), &model.ContextInfo{ID: "owner-1"}, "workspace-id", "editor-1", constant.WorkspaceOwner)
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+
+	t.Run("Editor", func(t *testing.T) {
+		_, err := workspaceService.UpdateMember(context.Background(), &model.ContextInfo{ID: "editor-1"}, "workspace-id", "viewer-1", constant.WorkspaceEditor)
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("Leave", func(t *testing.T) {
+		_, err := workspaceService.RemoveMember(context.Background(), &model.ContextInfo{ID: "viewer-1"}, "workspace-id", "viewer-1")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+	})
+
+	t.Run("Remove_Other", func(t *testing.T) {
+		_, err := workspaceService.RemoveMember(context.Background(), &model.ContextInfo{ID: "editor-1"}, "workspace-id", "viewer-1")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+
+	t.Run("Remove_Owner", func(t *testing.T) {
+		// the mock workspace is owned by a user with the same id as the workspace
+		_, err := workspaceService.RemoveMember(context.Background(), &model.ContextInfo{ID: "owner-1"}, "owner-1", "owner-1")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
+
+func TestAccept(t *testing.T) {
+	// the mock invitation is addressed to an email equal to its token
+	t.Run("Invited", func(t *testing.T) {
+		membership, err := workspaceService.Accept(context.Background(), &model.ContextInfo{ID: "test-id", Email: "jane@mail.com"}, "jane@mail.com")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if membership != nil && membership.Role != constant.WorkspaceEditor {
+			t.Errorf("Expected 'membership.Role' to be '%v', got '%v'", constant.WorkspaceEditor, membership.Role)
+		}
+	})
+
+	t.Run("Different_Email", func(t *testing.T) {
+		_, err := workspaceService.Accept(context.Background(), &model.ContextInfo{ID: "test-id", Email: "john@mail.com"}, "jane@mail.com")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
+
+func TestTransfer(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		w, err := workspaceService.Transfer(context.Background(), &model.ContextInfo{ID: "owner-1"}, "owner-1", "editor-1")
+		if err != nil {
+			t.Errorf("Expected 'error' to be nil, got '%v'", err)
+		}
+
+		if w != nil && w.OwnerID != "editor-1" {
+			t.Errorf("Expected 'workspace.OwnerID' to be 'editor-1', got '%v'", w.OwnerID)
+		}
+	})
+
+	t.Run("Editor", func(t *testing.T) {
+		_, err := workspaceService.Transfer(context.Background(), &model.ContextInfo{ID: "editor-1"}, "owner-1", "editor-1")
+		if err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package utility
+
+import (
+	"context"
+	"time"
+)
+
+// Detach returns a context carrying the values of 'ctx', such as its trace, without its deadline or
+// cancellation. Writes that must happen once an action completed use it, even if the client is gone
+func Detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+type detached struct{ context.Context }
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
@@ -0,0 +1,49 @@
+// build+ unit
+package utility_test
+
+import (
+	"brief/utility"
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := utility.ParseTrustedProxies("10.0.0.0/8, 127.0.0.1")
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+
+	tests := []struct {
+		Name          string
+		RemoteAddr    string
+		XForwardedFor string
+		Expected      string
+	}{
+		{"Direct", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"Untrusted_Proxy_Ignored", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"Trusted_Proxy", "127.0.0.1:5000", "198.51.100.1", "198.51.100.1"},
+		{"Proxy_Chain", "10.0.0.2:5000", "198.51.100.1, 203.0.113.9, 10.0.0.5", "203.0.113.9"},
+		{"Spoofed_Hop_Ignored", "10.0.0.2:5000", "1.1.1.1, garbage, 198.51.100.1", "198.51.100.1"},
+		{"Only_Trusted_Hops", "10.0.0.2:5000", "10.0.0.9", "10.0.0.9"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "http://my-url.com", nil)
+			r.RemoteAddr = test.RemoteAddr
+			if test.XForwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", test.XForwardedFor)
+			}
+
+			if ip := utility.ClientIP(r, trusted); ip.String() != test.Expected {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, ip)
+			}
+		})
+	}
+
+	t.Run("Invalid_Proxy", func(t *testing.T) {
+		if _, err := utility.ParseTrustedProxies("not-an-ip"); err == nil {
+			t.Errorf("Expected 'error' to be not nil")
+		}
+	})
+}
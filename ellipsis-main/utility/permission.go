@@ -0,0 +1,23 @@
+package utility
+
+import "brief/internal/constant"
+
+// HasPermission reports whether 'role' is granted 'permission'
+func HasPermission(role int, permission string) bool {
+	for _, p := range constant.RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleName returns the name of 'role', or an empty string for unknown roles
+func RoleName(role int) string {
+	for name, r := range constant.Roles {
+		if r == role {
+			return name
+		}
+	}
+	return ""
+}
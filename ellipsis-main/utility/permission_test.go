@@ -0,0 +1,45 @@
+// build+ unit
+package utility_test
+
+import (
+	"brief/internal/constant"
+	"brief/utility"
+	"testing"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Role       int
+		Permission string
+		Expected   bool
+	}{
+		{"Admin_Assign_Role", constant.Roles[constant.Admin], constant.PermRoleAssign, true},
+		{"Moderator_Lock_User", constant.Roles[constant.Moderator], constant.PermUserLock, true},
+		{"Moderator_Assign_Role", constant.Roles[constant.Moderator], constant.PermRoleAssign, false},
+		{"Admin_Manage_Workspaces", constant.Roles[constant.Admin], constant.PermWorkspaceManageAny, true},
+		{"Moderator_Manage_Webhooks", constant.Roles[constant.Moderator], constant.PermWebhookManageAny, false},
+		{"Analyst_Read_Stats", constant.Roles[constant.Analyst], constant.PermStatsRead, true},
+		{"Analyst_Delete_URL", constant.Roles[constant.Analyst], constant.PermURLDeleteAny, false},
+		{"User_Read_URL", constant.Roles[constant.User], constant.PermURLReadAny, false},
+		{"Unknown_Role", 0, constant.PermURLReadAny, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if got := utility.HasPermission(test.Role, test.Permission); got != test.Expected {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, got)
+			}
+		})
+	}
+}
+
+func TestRoleName(t *testing.T) {
+	if name := utility.RoleName(constant.Roles[constant.Moderator]); name != constant.Moderator {
+		t.Errorf("Expected '%v', got '%v'", constant.Moderator, name)
+	}
+
+	if name := utility.RoleName(0); name != "" {
+		t.Errorf("Expected '', got '%v'", name)
+	}
+}
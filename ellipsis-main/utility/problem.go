@@ -0,0 +1,96 @@
+package utility
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/pkg/logging"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of problem details, clients sending it in their 'Accept'
+// header get errors as a Problem instead of a Response
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object
+type Problem struct {
+	Type      string      `json:"type"` // 'urn:brief:problem:' followed by the code
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"` // path of the failed request
+	Code      string      `json:"code" enums:"invalid_body,validation_failed,invalid_url,invalid_rule,invalid_utm,invalid_role,invalid_option,domain_verification_failed,unauthorized,invalid_credentials,sign_in_required,forbidden,account_locked,url_not_found,user_not_found,campaign_not_found,domain_not_found,workspace_not_found,member_not_found,invitation_not_found,webhook_not_found,delivery_not_found,not_found,hash_taken,email_taken,domain_taken,domain_not_verified,restore_expired,invitation_accepted,invitation_expired,workspace_owner,internal_error"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"` // message of each invalid field of a validation error
+}
+
+// WriteError writes 'err' with the status of its kind, as a Problem to clients accepting one and
+// as a Response otherwise. Internal errors are logged, their cause is not shown to clients
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	e := apperror.From(err)
+	status := e.Status()
+	requestID := logging.RequestID(r.Context())
+
+	if e.Kind == apperror.KindInternal {
+		logging.FromContext(r.Context()).WithError(err).Error(e.Message)
+	}
+
+	if acceptsProblem(r) {
+		problem := Problem{
+			Type:      "urn:brief:problem:" + e.Code,
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    e.Message,
+			Instance:  r.URL.Path,
+			Code:      e.Code,
+			RequestID: requestID,
+			Errors:    e.Details,
+		}
+		res, _ := json.Marshal(problem)
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(status)
+		w.Write(res)
+		return
+	}
+
+	var detail interface{} = e.Message
+	if e.Details != nil {
+		detail = e.Details
+	}
+	rd := ResponseMessage(status, constant.StatusFailed, e.Code, legacyMessage(e), detail, nil, nil, nil)
+	rd.RequestID = requestID
+	res, _ := json.Marshal(rd)
+	w.WriteHeader(status)
+	w.Write(res)
+}
+
+// legacyMessage keeps the message of a Response to the category clients matched on before errors
+// had codes
+func legacyMessage(e *apperror.Error) string {
+	switch e.Kind {
+	case apperror.KindValidation:
+		switch e.Code {
+		case apperror.CodeInvalidBody:
+			return constant.ErrBinding
+		case apperror.CodeValidationFailed:
+			return constant.ErrValidation
+		}
+		return constant.ErrRequest
+	case apperror.KindUnauthorized, apperror.KindForbidden:
+		return constant.ErrUnauthorized
+	case apperror.KindInternal:
+		return constant.ErrServer
+	default:
+		return constant.ErrRequest
+	}
+}
+
+func acceptsProblem(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, ProblemContentType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// build+ unit
+package utility_test
+
+import (
+	"brief/internal/apperror"
+	"brief/internal/constant"
+	"brief/utility"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Err     error
+		Status  int
+		Code    string
+		Message string
+		Detail  string
+	}{
+		{"Not_Found", apperror.NotFound(apperror.CodeURLNotFound, "url not found"),
+			http.StatusNotFound, apperror.CodeURLNotFound, constant.ErrRequest, "url not found"},
+		{"Conflict", apperror.Conflict(apperror.CodeHashTaken, "oops, '%s' already exists", "custom"),
+			http.StatusConflict, apperror.CodeHashTaken, constant.ErrRequest, "oops, 'custom' already exists"},
+		{"Forbidden", apperror.Forbidden(apperror.CodeForbidden, "unauthorized to perform this action"),
+			http.StatusForbidden, apperror.CodeForbidden, constant.ErrUnauthorized, "unauthorized to perform this action"},
+		{"Binding", apperror.Binding(errors.New("unexpected EOF")),
+			http.StatusBadRequest, apperror.CodeInvalidBody, constant.ErrBinding, "unexpected EOF"},
+		// The cause of internal errors is not shown to clients
+		{"Internal", apperror.Internal(errors.New("connection refused"), "could not fetch url"),
+			http.StatusInternalServerError, apperror.CodeInternal, constant.ErrServer, "could not fetch url"},
+		{"Untyped", errors.New("connection refused"),
+			http.StatusInternalServerError, apperror.CodeInternal, constant.ErrServer, "something went wrong"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Run("Response", func(t *testing.T) {
+				w := httptest.NewRecorder()
+				utility.WriteError(w, httptest.NewRequest(http.MethodGet, "/api/v1/url/custom", nil), test.Err)
+
+				var res utility.Response
+				json.Unmarshal(w.Body.Bytes(), &res)
+				if w.Code != test.Status || res.Code != test.Status {
+					t.Errorf("Expected '%d', got '%d'", test.Status, w.Code)
+				}
+				if res.Name != test.Code {
+					t.Errorf("Expected '%s', got '%s'", test.Code, res.Name)
+				}
+				if res.Message != test.Message {
+					t.Errorf("Expected '%s', got '%s'", test.Message, res.Message)
+				}
+				if res.Error != test.Detail {
+					t.Errorf("Expected '%s', got '%v'", test.Detail, res.Error)
+				}
+			})
+
+			t.Run("Problem", func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodGet, "/api/v1/url/custom", nil)
+				r.Header.Set("Accept", utility.ProblemContentType)
+				w := httptest.NewRecorder()
+				utility.WriteError(w, r, test.Err)
+
+				if contentType := w.Header().Get("Content-Type"); contentType != utility.ProblemContentType {
+					t.Errorf("Expected '%s', got '%s'", utility.ProblemContentType, contentType)
+				}
+
+				var problem utility.Problem
+				json.Unmarshal(w.Body.Bytes(), &problem)
+				if w.Code != test.Status || problem.Status != test.Status {
+					t.Errorf("Expected '%d', got '%d'", test.Status, problem.Status)
+				}
+				if expected := "urn:brief:problem:" + test.Code; problem.Type != expected || problem.Code != test.Code {
+					t.Errorf("Expected '%s', got '%s'", expected, problem.Type)
+				}
+				if problem.Detail != test.Detail {
+					t.Errorf("Expected '%s', got '%s'", test.Detail, problem.Detail)
+				}
+				if problem.Instance != "/api/v1/url/custom" {
+					t.Errorf("Expected '/api/v1/url/custom', got '%s'", problem.Instance)
+				}
+			})
+		})
+	}
+
+	t.Run("Validation_Fields", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/url/shorten", nil)
+		r.Header.Set("Accept", utility.ProblemContentType)
+		w := httptest.NewRecorder()
+		utility.WriteError(w, r, apperror.Invalid(map[string]string{"URL.LongURL": "LongURL is a required field"}))
+
+		var problem struct {
+			Code   string            `json:"code"`
+			Errors map[string]string `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &problem)
+		if problem.Code != apperror.CodeValidationFailed {
+			t.Errorf("Expected '%s', got '%s'", apperror.CodeValidationFailed, problem.Code)
+		}
+		if problem.Errors["URL.LongURL"] != "LongURL is a required field" {
+			t.Errorf("Expected a message for 'URL.LongURL', got '%v'", problem.Errors)
+		}
+	})
+}
@@ -12,11 +12,13 @@ import (
 type Response struct {
 	Status  string      `json:"status,omitempty"`
 	Code    int         `json:"code,omitempty"`
-	Name    string      `json:"name,omitempty"` //name of the error
+	Name    string      `json:"name,omitempty" enums:"invalid_body,validation_failed,invalid_url,invalid_rule,invalid_utm,invalid_role,invalid_option,domain_verification_failed,unauthorized,invalid_credentials,sign_in_required,forbidden,account_locked,url_not_found,user_not_found,campaign_not_found,domain_not_found,workspace_not_found,member_not_found,invitation_not_found,webhook_not_found,delivery_not_found,not_found,hash_taken,email_taken,domain_taken,domain_not_verified,restore_expired,invitation_accepted,invitation_expired,workspace_owner,internal_error"` //stable code of the error
 	Message string      `json:"message,omitempty"`
 	Error   interface{} `json:"error,omitempty"` //for errors that occur even if request is successful
 	Data    interface{} `json:"data,omitempty"`
 	Extra   interface{} `json:"extra,omitempty"`
+
+	RequestID string `json:"request_id,omitempty"` // correlation id of the failed request
 }
 
 // BuildResponse method is to inject data value to dynamic success response
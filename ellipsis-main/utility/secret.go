@@ -0,0 +1,32 @@
+package utility
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// GenerateSecret returns a random url safe secret made of 'size' bytes
+func GenerateSecret(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// SetEnv sets 'key' to 'value' in the content of an env file, the line is appended when
+// the key is not set yet
+func SetEnv(content, key, value string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), key+"=") {
+			lines[i] = key + "=" + value
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	return strings.Join(append(lines, key+"="+value), "\n") + "\n"
+}
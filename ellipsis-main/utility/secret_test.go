@@ -0,0 +1,43 @@
+// build+ unit
+package utility_test
+
+import (
+	"brief/utility"
+	"testing"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := utility.GenerateSecret(32)
+	if err != nil {
+		t.Fatalf("Expected 'error' to be nil, got '%v'", err)
+	}
+	second, _ := utility.GenerateSecret(32)
+
+	if len(first) != 43 {
+		t.Errorf("Expected '43' characters, got '%d'", len(first))
+	}
+	if first == second {
+		t.Errorf("Expected secrets to differ, got '%s' twice", first)
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Content  string
+		Expected string
+	}{
+		{"Replaces_Value", "SERVER_PORT=8080\nSECRET_KEY=old\nPG_HOST=db\n", "SERVER_PORT=8080\nSECRET_KEY=new\nPG_HOST=db\n"},
+		{"Appends_Missing_Key", "SERVER_PORT=8080\n", "SERVER_PORT=8080\nSECRET_KEY=new\n"},
+		{"Empty_File", "", "SECRET_KEY=new\n"},
+		{"Ignores_Similar_Key", "SECRET_KEY_OLD=old", "SECRET_KEY_OLD=old\nSECRET_KEY=new\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if got := utility.SetEnv(test.Content, "SECRET_KEY", "new"); got != test.Expected {
+				t.Errorf("Expected '%q', got '%q'", test.Expected, got)
+			}
+		})
+	}
+}
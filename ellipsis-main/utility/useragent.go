@@ -0,0 +1,102 @@
+package utility
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type UserAgent struct {
+	OS      string
+	Device  string
+	Browser string
+}
+
+// ParseUserAgent extracts the operating system, device type and browser from a User-Agent header.
+// Values that cannot be recognized are left empty
+func ParseUserAgent(header string) UserAgent {
+	ua := strings.ToLower(header)
+	var parsed UserAgent
+
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		parsed.OS = "ios"
+	case strings.Contains(ua, "android"):
+		parsed.OS = "android"
+	case strings.Contains(ua, "windows"):
+		parsed.OS = "windows"
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		parsed.OS = "macos"
+	case strings.Contains(ua, "linux"), strings.Contains(ua, "x11"):
+		parsed.OS = "linux"
+	}
+
+	switch {
+	case ua == "", strings.Contains(ua, "bot"), strings.Contains(ua, "crawler"), strings.Contains(ua, "spider"):
+		parsed.Device = "bot"
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		parsed.OS == "android" && !strings.Contains(ua, "mobile"):
+		parsed.Device = "tablet"
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "ipod"):
+		parsed.Device = "mobile"
+	default:
+		parsed.Device = "desktop"
+	}
+
+	// Order matters, most browsers include the tokens of the ones they are based on
+	switch {
+	case strings.Contains(ua, "edg/"), strings.Contains(ua, "edge/"), strings.Contains(ua, "edga/"), strings.Contains(ua, "edgios/"):
+		parsed.Browser = "edge"
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		parsed.Browser = "opera"
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios/"):
+		parsed.Browser = "firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"):
+		parsed.Browser = "chrome"
+	case strings.Contains(ua, "safari/"):
+		parsed.Browser = "safari"
+	}
+
+	return parsed
+}
+
+// ParseAcceptLanguage returns the lower-cased language tags of an Accept-Language header,
+// ordered by their quality value
+func ParseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag, quality})
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
@@ -0,0 +1,55 @@
+// build+ unit
+package utility_test
+
+import (
+	"brief/utility"
+	"reflect"
+	"testing"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Header   string
+		Expected utility.UserAgent
+	}{
+		{"iPhone_Safari", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1", utility.UserAgent{"ios", "mobile", "safari"}},
+		{"iPad_Chrome", "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/118.0 Mobile/15E148 Safari/604.1", utility.UserAgent{"ios", "tablet", "chrome"}},
+		{"Android_Phone", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 Mobile Safari/537.36", utility.UserAgent{"android", "mobile", "chrome"}},
+		{"Android_Tablet", "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 Safari/537.36", utility.UserAgent{"android", "tablet", "chrome"}},
+		{"Windows_Edge", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 Safari/537.36 Edg/118.0", utility.UserAgent{"windows", "desktop", "edge"}},
+		{"Mac_Firefox", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14.0; rv:118.0) Gecko/20100101 Firefox/118.0", utility.UserAgent{"macos", "desktop", "firefox"}},
+		{"Linux_Opera", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 Safari/537.36 OPR/104.0", utility.UserAgent{"linux", "desktop", "opera"}},
+		{"Crawler", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", utility.UserAgent{"", "bot", ""}},
+		{"Empty", "", utility.UserAgent{"", "bot", ""}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if ua := utility.ParseUserAgent(test.Header); ua != test.Expected {
+				t.Errorf("Expected '%+v', got '%+v'", test.Expected, ua)
+			}
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Header   string
+		Expected []string
+	}{
+		{"Single", "fr-CA", []string{"fr-ca"}},
+		{"Quality_Order", "en;q=0.5, fr-CA, de;q=0.8", []string{"fr-ca", "de", "en"}},
+		{"Ignores_Wildcard_And_Zero", "*, es;q=0, it", []string{"it"}},
+		{"Empty", "", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if langs := utility.ParseAcceptLanguage(test.Header); !reflect.DeepEqual(langs, test.Expected) {
+				t.Errorf("Expected '%v', got '%v'", test.Expected, langs)
+			}
+		})
+	}
+}